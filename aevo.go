@@ -0,0 +1,164 @@
+package exchanges
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type Aevo struct{}
+
+func NewAevo() *Aevo {
+	log.Println("Инициализация Aevo")
+	return &Aevo{}
+}
+
+func (a *Aevo) GetName() string {
+	return "Aevo"
+}
+
+// Структура статистики по активу Aevo
+type aevoStatisticsResponse struct {
+	OpenInterest struct {
+		Total string `json:"total"`
+	} `json:"open_interest"`
+	DailyVolume          string `json:"daily_volume"`           // в USD
+	DailyVolumeContracts string `json:"daily_volume_contracts"` // в базовой валюте
+}
+
+func (a *Aevo) GetFundingRates() ([]FundingRate, error) {
+	log.Println("Запрос ставок фандинга с Aevo")
+
+	resp, err := httpGet("https://api.aevo.xyz/markets?instrument_type=PERPETUAL")
+	if err != nil {
+		log.Printf("Ошибка запроса рынков к Aevo: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Aevo API вернул код ошибки %d", resp.StatusCode)
+	}
+
+	var markets []struct {
+		InstrumentName  string `json:"instrument_name"`
+		UnderlyingAsset string `json:"underlying_asset"`
+		QuoteAsset      string `json:"quote_asset"`
+		MarkPrice       string `json:"mark_price"`
+		IndexPrice      string `json:"index_price"`
+		IsActive        bool   `json:"is_active"`
+	}
+
+	if err := decodeResponse(a.GetName(), resp, &markets); err != nil {
+		log.Printf("Ошибка декодирования рынков от Aevo: %v", err)
+		return nil, err
+	}
+
+	location := GetLocationFromEnv()
+	result := make([]FundingRate, 0)
+
+	for _, market := range markets {
+		if !market.IsActive {
+			continue
+		}
+
+		fundingRate, nextFunding, err := a.getFunding(market.InstrumentName)
+		if err != nil {
+			log.Printf("Ошибка получения фандинг ставки для %s с Aevo: %v", market.InstrumentName, err)
+			continue
+		}
+
+		stats, err := a.getStatistics(market.UnderlyingAsset)
+		if err != nil {
+			log.Printf("Ошибка получения статистики для %s с Aevo: %v", market.UnderlyingAsset, err)
+			// Продолжаем работу без объемов
+			stats = &aevoStatisticsResponse{}
+		}
+
+		// Расчеты по перпетуалам Aevo ведутся в USDC
+		settleAsset := market.QuoteAsset
+		if settleAsset == "" || settleAsset == "USD" {
+			settleAsset = "USDC"
+		}
+
+		volume := parseDecimal(stats.DailyVolumeContracts)
+		volumeUSDT := parseDecimal(stats.DailyVolume)
+
+		result = append(result, FundingRate{
+			Symbol:          market.InstrumentName,
+			Rate:            fundingRate.Float64(),
+			NextFunding:     nextFunding.In(location).Format(time.RFC3339),
+			Volume24h:       volume.Float64(),
+			VolumeUSDT24h:   volumeUSDT.Float64(),
+			MarkPrice:       parseFloatFromString(market.MarkPrice),
+			IndexPrice:      parseFloatFromString(market.IndexPrice),
+			FundingInterval: time.Hour,
+			OpenInterest:    parseFloatFromString(stats.OpenInterest.Total),
+			SettleAsset:     settleAsset,
+			Precise:         newPrecise(fundingRate, Decimal{}, volume, volumeUSDT),
+		})
+
+		// Добавляем задержку между запросами
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	log.Printf("Получено %d ставок фандинга с Aevo", len(result))
+	return result, nil
+}
+
+// getFunding получает текущую часовую ставку и время следующего фандинга инструмента
+func (a *Aevo) getFunding(instrument string) (Decimal, time.Time, error) {
+	resp, err := httpGet("https://api.aevo.xyz/funding?instrument_name=" + url.QueryEscape(instrument))
+	if err != nil {
+		return Decimal{}, time.Time{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Decimal{}, time.Time{}, fmt.Errorf("Aevo API вернул код ошибки %d", resp.StatusCode)
+	}
+
+	var response struct {
+		FundingRate string `json:"funding_rate"`
+		NextEpoch   string `json:"next_epoch"` // в наносекундах
+	}
+
+	if err := decodeResponse(a.GetName(), resp, &response); err != nil {
+		return Decimal{}, time.Time{}, err
+	}
+
+	fundingRate, err := ParseDecimal(response.FundingRate)
+	if err != nil {
+		return Decimal{}, time.Time{}, fmt.Errorf("ошибка парсинга ставки фандинга %s: %v", response.FundingRate, err)
+	}
+
+	nextFunding := time.Now().Truncate(time.Hour).Add(time.Hour)
+	if nextEpoch, err := strconv.ParseInt(response.NextEpoch, 10, 64); err == nil && nextEpoch > 0 {
+		nextFunding = time.Unix(0, nextEpoch)
+	}
+
+	return fundingRate, nextFunding, nil
+}
+
+// getStatistics получает объемы и открытый интерес по базовому активу
+func (a *Aevo) getStatistics(asset string) (*aevoStatisticsResponse, error) {
+	resp, err := httpGet("https://api.aevo.xyz/statistics?instrument_type=PERPETUAL&asset=" + url.QueryEscape(asset))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Aevo API вернул код ошибки %d", resp.StatusCode)
+	}
+
+	var stats aevoStatisticsResponse
+	if err := decodeResponse(a.GetName(), resp, &stats); err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
@@ -0,0 +1,442 @@
+package exchanges
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Alert сработавшее правило оповещения
+type Alert struct {
+	Rule     string    `json:"rule"`
+	Key      string    `json:"key"` // ключ дедупликации: правило, биржа и символ
+	Exchange string    `json:"exchange,omitempty"`
+	Symbol   string    `json:"symbol,omitempty"`
+	Value    float64   `json:"value"`
+	Message  string    `json:"message"`
+	Time     time.Time `json:"time"`
+}
+
+// AlertRule правило, проверяемое при каждом обновлении кэша.
+// Lookback сообщает, за какой период правилу нужна история ставок в AlertContext.RateAt
+type AlertRule interface {
+	Name() string
+	Lookback() time.Duration
+	Evaluate(ctx *AlertContext) []Alert
+}
+
+// ratePoint наблюдение ставки в момент времени
+type ratePoint struct {
+	at   time.Time
+	rate float64
+}
+
+// AlertContext данные, доступные правилам при проверке
+type AlertContext struct {
+	Rates map[string][]FundingRate // ключ - имя биржи
+	Now   time.Time
+
+	history map[string][]ratePoint // ключ - биржа и символ
+}
+
+// RateAt возвращает последнюю наблюдавшуюся ставку, приведенную к 8 часам, не позже момента t
+func (c *AlertContext) RateAt(exchange, symbol string, t time.Time) (float64, bool) {
+	points := c.history[exchange+"|"+symbol]
+	for i := len(points) - 1; i >= 0; i-- {
+		if !points[i].at.After(t) {
+			return points[i].rate, true
+		}
+	}
+	return 0, false
+}
+
+// matchRate проверяет фильтры правила по бирже и каноническому символу
+func matchRate(exchangeFilter, symbolFilter, exchange string, rate FundingRate) bool {
+	if exchangeFilter != "" && !strings.EqualFold(exchangeFilter, exchange) {
+		return false
+	}
+	if symbolFilter != "" && !strings.EqualFold(symbolFilter, CanonicalSymbol(rate.Symbol)) {
+		return false
+	}
+	return true
+}
+
+// ThresholdDirection направление сравнения ставки с порогом
+type ThresholdDirection int
+
+const (
+	ThresholdAbove    ThresholdDirection = iota // ставка выше порога
+	ThresholdBelow                              // ставка ниже порога
+	ThresholdAbsAbove                           // модуль ставки выше порога
+)
+
+// ThresholdRule срабатывает, когда ставка, приведенная к 8 часам, пересекает порог.
+// Пустые Exchange и Symbol означают любую биржу и любой актив
+type ThresholdRule struct {
+	RuleName  string
+	Exchange  string
+	Symbol    string // канонический символ, например BTC
+	Threshold float64
+	Direction ThresholdDirection
+}
+
+func (r ThresholdRule) Name() string {
+	return r.RuleName
+}
+
+func (r ThresholdRule) Lookback() time.Duration {
+	return 0
+}
+
+func (r ThresholdRule) Evaluate(ctx *AlertContext) []Alert {
+	var alerts []Alert
+	for exchange, rates := range ctx.Rates {
+		for _, rate := range rates {
+			if !matchRate(r.Exchange, r.Symbol, exchange, rate) {
+				continue
+			}
+
+			value := rate.Rate8h()
+			var triggered bool
+			var verb string
+			switch r.Direction {
+			case ThresholdAbove:
+				triggered, verb = value > r.Threshold, "выше"
+			case ThresholdBelow:
+				triggered, verb = value < r.Threshold, "ниже"
+			case ThresholdAbsAbove:
+				triggered, verb = math.Abs(value) > r.Threshold, "по модулю выше"
+			}
+			if !triggered {
+				continue
+			}
+
+			alerts = append(alerts, Alert{
+				Rule:     r.RuleName,
+				Key:      r.RuleName + "|" + exchange + "|" + rate.Symbol,
+				Exchange: exchange,
+				Symbol:   rate.Symbol,
+				Value:    value,
+				Message:  fmt.Sprintf("%s %s: ставка за 8ч %.4f%% %s порога %.4f%%", exchange, rate.Symbol, value*100, verb, r.Threshold*100),
+				Time:     ctx.Now,
+			})
+		}
+	}
+	return alerts
+}
+
+// SpreadRule срабатывает, когда межбиржевой спред ставок актива, приведенных к 8 часам,
+// превышает MinSpread
+type SpreadRule struct {
+	RuleName      string
+	Symbol        string // канонический символ, пусто — любой актив
+	MinSpread     float64
+	MinVolumeUSDT float64 // ставки с меньшим объемом не учитываются
+}
+
+func (r SpreadRule) Name() string {
+	return r.RuleName
+}
+
+func (r SpreadRule) Lookback() time.Duration {
+	return 0
+}
+
+func (r SpreadRule) Evaluate(ctx *AlertContext) []Alert {
+	var alerts []Alert
+	for _, spread := range CrossExchangeSpreads(ctx.Rates, r.MinVolumeUSDT) {
+		if r.Symbol != "" && !strings.EqualFold(r.Symbol, spread.Symbol) {
+			continue
+		}
+		if spread.Spread <= r.MinSpread {
+			continue
+		}
+
+		alerts = append(alerts, Alert{
+			Rule:   r.RuleName,
+			Key:    r.RuleName + "|" + spread.Symbol + "|" + spread.Low.Exchange + "|" + spread.High.Exchange,
+			Symbol: spread.Symbol,
+			Value:  spread.Spread,
+			Message: fmt.Sprintf("%s: спред %.4f%% между %s (%.4f%%) и %s (%.4f%%)", spread.Symbol, spread.Spread*100,
+				spread.Low.Exchange, spread.Low.Rate8h()*100, spread.High.Exchange, spread.High.Rate8h()*100),
+			Time: ctx.Now,
+		})
+	}
+	return alerts
+}
+
+// RateOfChangeRule срабатывает, когда ставка, приведенная к 8 часам, изменилась больше чем
+// на MinChange за Window
+type RateOfChangeRule struct {
+	RuleName  string
+	Exchange  string
+	Symbol    string // канонический символ
+	MinChange float64
+	Window    time.Duration
+}
+
+func (r RateOfChangeRule) Name() string {
+	return r.RuleName
+}
+
+func (r RateOfChangeRule) Lookback() time.Duration {
+	return r.Window
+}
+
+func (r RateOfChangeRule) Evaluate(ctx *AlertContext) []Alert {
+	var alerts []Alert
+	for exchange, rates := range ctx.Rates {
+		for _, rate := range rates {
+			if !matchRate(r.Exchange, r.Symbol, exchange, rate) {
+				continue
+			}
+
+			past, ok := ctx.RateAt(exchange, rate.Symbol, ctx.Now.Add(-r.Window))
+			if !ok {
+				continue
+			}
+
+			current := rate.Rate8h()
+			change := current - past
+			if math.Abs(change) <= r.MinChange {
+				continue
+			}
+
+			alerts = append(alerts, Alert{
+				Rule:     r.RuleName,
+				Key:      r.RuleName + "|" + exchange + "|" + rate.Symbol,
+				Exchange: exchange,
+				Symbol:   rate.Symbol,
+				Value:    change,
+				Message: fmt.Sprintf("%s %s: ставка изменилась на %+.4f%% за %s (%.4f%% -> %.4f%%)", exchange, rate.Symbol,
+					change*100, r.Window, past*100, current*100),
+				Time: ctx.Now,
+			})
+		}
+	}
+	return alerts
+}
+
+// FundingWindowRule срабатывает, когда до выплаты фандинга осталось меньше Within,
+// а модуль ставки, приведенной к 8 часам, не меньше MinAbsRate
+type FundingWindowRule struct {
+	RuleName   string
+	Exchange   string
+	Symbol     string // канонический символ
+	Within     time.Duration
+	MinAbsRate float64
+}
+
+func (r FundingWindowRule) Name() string {
+	return r.RuleName
+}
+
+func (r FundingWindowRule) Lookback() time.Duration {
+	return 0
+}
+
+func (r FundingWindowRule) Evaluate(ctx *AlertContext) []Alert {
+	var alerts []Alert
+	for exchange, rates := range ctx.Rates {
+		for _, rate := range rates {
+			value := rate.Rate8h()
+			if !matchRate(r.Exchange, r.Symbol, exchange, rate) || math.Abs(value) < r.MinAbsRate {
+				continue
+			}
+
+			nextFunding, err := time.Parse(time.RFC3339, rate.NextFunding)
+			if err != nil {
+				continue
+			}
+
+			left := nextFunding.Sub(ctx.Now)
+			if left < 0 || left > r.Within {
+				continue
+			}
+
+			alerts = append(alerts, Alert{
+				Rule: r.RuleName,
+				// Время выплаты в ключе: следующее окно оповещает заново
+				Key:      r.RuleName + "|" + exchange + "|" + rate.Symbol + "|" + rate.NextFunding,
+				Exchange: exchange,
+				Symbol:   rate.Symbol,
+				Value:    value,
+				Message: fmt.Sprintf("%s %s: до выплаты фандинга %s, ставка %.4f%% (за 8ч %.4f%%)", exchange, rate.Symbol,
+					left.Truncate(time.Second), rate.Rate*100, value*100),
+				Time: ctx.Now,
+			})
+		}
+	}
+	return alerts
+}
+
+// alertQueueSize размер очереди оповещений, ожидающих доставки
+const alertQueueSize = 256
+
+// AlertEngine проверяет правила на каждом обновлении ставок и рассылает оповещения.
+// Оповещение с одним ключом отправляется не чаще одного раза за cooldown.
+// Доставка идет в отдельной горутине, поэтому медленный канал не задерживает проверку правил
+type AlertEngine struct {
+	mu        sync.Mutex
+	rules     []AlertRule
+	notifiers []Notifier
+	cooldown  time.Duration
+	sent      map[string]time.Time   // ключ оповещения -> время последней отправки
+	history   map[string][]ratePoint // наблюдения ставок для правил изменения
+	keep      time.Duration          // сколько хранить наблюдения
+
+	queue   chan Alert    // оповещения, ожидающие доставки
+	done    chan struct{} // закрывается после остановки доставки
+	closed  bool
+	dropped atomic.Uint64
+}
+
+// NewAlertEngine создает движок оповещений с указанным интервалом подавления повторов
+// и запускает доставку. Остановка — через Close
+func NewAlertEngine(cooldown time.Duration) *AlertEngine {
+	e := &AlertEngine{
+		cooldown: cooldown,
+		sent:     make(map[string]time.Time),
+		history:  make(map[string][]ratePoint),
+		queue:    make(chan Alert, alertQueueSize),
+		done:     make(chan struct{}),
+	}
+	go e.deliver()
+	return e
+}
+
+// AddRule добавляет правило
+func (e *AlertEngine) AddRule(rule AlertRule) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.rules = append(e.rules, rule)
+	if lookback := rule.Lookback(); lookback > e.keep {
+		e.keep = lookback
+	}
+}
+
+// AddNotifier добавляет канал доставки оповещений
+func (e *AlertEngine) AddNotifier(notifier Notifier) {
+	e.mu.Lock()
+	e.notifiers = append(e.notifiers, notifier)
+	e.mu.Unlock()
+}
+
+// Evaluate проверяет все правила на переданном снимке ставок, ставит новые оповещения
+// в очередь доставки и возвращает их
+func (e *AlertEngine) Evaluate(rates map[string][]FundingRate, now time.Time) []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ctx := &AlertContext{Rates: rates, Now: now, history: e.history}
+
+	var fresh []Alert
+	seen := make(map[string]bool)
+	for _, rule := range e.rules {
+		for _, alert := range rule.Evaluate(ctx) {
+			if seen[alert.Key] {
+				continue
+			}
+			seen[alert.Key] = true
+
+			if last, ok := e.sent[alert.Key]; ok && now.Sub(last) < e.cooldown {
+				continue
+			}
+			e.sent[alert.Key] = now
+			fresh = append(fresh, alert)
+		}
+	}
+
+	e.record(rates, now)
+
+	for _, alert := range fresh {
+		if e.closed {
+			break
+		}
+		select {
+		case e.queue <- alert:
+		default:
+			e.dropped.Add(1)
+			log.Printf("Очередь оповещений переполнена, оповещение %s отброшено", alert.Key)
+		}
+	}
+
+	return fresh
+}
+
+// Dropped возвращает число оповещений, отброшенных из-за переполнения очереди доставки
+func (e *AlertEngine) Dropped() uint64 {
+	return e.dropped.Load()
+}
+
+// Close останавливает доставку, дождавшись отправки оповещений из очереди
+func (e *AlertEngine) Close() {
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.queue)
+	}
+	e.mu.Unlock()
+
+	<-e.done
+}
+
+// deliver отправляет оповещения из очереди во все каналы доставки
+func (e *AlertEngine) deliver() {
+	defer close(e.done)
+
+	for alert := range e.queue {
+		e.mu.Lock()
+		notifiers := e.notifiers
+		e.mu.Unlock()
+
+		for _, notifier := range notifiers {
+			if err := notifier.Notify(alert); err != nil {
+				log.Printf("Ошибка отправки оповещения %s: %v", alert.Key, err)
+			}
+		}
+	}
+}
+
+// record запоминает наблюдения ставок и удаляет устаревшие данные
+func (e *AlertEngine) record(rates map[string][]FundingRate, now time.Time) {
+	for exchange, list := range rates {
+		for _, rate := range list {
+			key := exchange + "|" + rate.Symbol
+			points := append(e.history[key], ratePoint{at: now, rate: rate.Rate8h()})
+
+			// Оставляем одну точку старше окна, чтобы было с чем сравнивать
+			cutoff := now.Add(-e.keep)
+			drop := 0
+			for drop+1 < len(points) && !points[drop+1].at.After(cutoff) {
+				drop++
+			}
+			e.history[key] = points[drop:]
+		}
+	}
+
+	for key, last := range e.sent {
+		if now.Sub(last) >= e.cooldown {
+			delete(e.sent, key)
+		}
+	}
+}
+
+// Attach запускает проверку правил после каждого обновления кэша.
+// Возвращает функцию остановки
+func (e *AlertEngine) Attach(cache *RatesCache) func() {
+	sub := cache.Subscribe(64)
+	go func() {
+		for event := range sub.C {
+			if event.Type == EventUpdated {
+				e.Evaluate(cache.GetAllRates(), event.Time)
+			}
+		}
+	}()
+	return sub.Close
+}
@@ -0,0 +1,168 @@
+package exchanges_test
+
+import (
+	"testing"
+	"time"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+)
+
+func TestFundingWindowRuleNormalizesRate(t *testing.T) {
+	now := time.Date(2026, 5, 1, 7, 50, 0, 0, time.UTC)
+	next := "2026-05-01T08:00:00Z"
+	ctx := &exchanges.AlertContext{
+		Now: now,
+		Rates: map[string][]exchanges.FundingRate{
+			// 0.01% за час — 0.08% за 8 часов, 0.01% за 8 часов ниже порога
+			"Hyperliquid": {{Symbol: "BTC", Rate: 0.0001, NextFunding: next, FundingInterval: time.Hour}},
+			"Binance":     {{Symbol: "BTCUSDT", Rate: 0.0001, NextFunding: next, FundingInterval: 8 * time.Hour}},
+		},
+	}
+
+	rule := exchanges.FundingWindowRule{RuleName: "window", Within: 15 * time.Minute, MinAbsRate: 0.0005}
+	alerts := rule.Evaluate(ctx)
+	if len(alerts) != 1 || alerts[0].Exchange != "Hyperliquid" || !approxEqual(alerts[0].Value, 0.0008) {
+		t.Errorf("оповещения %+v", alerts)
+	}
+}
+
+// recordNotifier запоминает доставленные оповещения
+type recordNotifier chan exchanges.Alert
+
+func (n recordNotifier) Notify(alert exchanges.Alert) error {
+	n <- alert
+	return nil
+}
+
+func TestAlertEngineCooldownAndDedup(t *testing.T) {
+	now := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	rates := map[string][]exchanges.FundingRate{
+		"Binance": {{Symbol: "BTCUSDT", Rate: 0.002, FundingInterval: 8 * time.Hour}},
+	}
+
+	engine := exchanges.NewAlertEngine(time.Hour)
+	defer engine.Close()
+	// Одинаковые правила дают один ключ, оповещение отправляется один раз
+	rule := exchanges.ThresholdRule{RuleName: "high", Symbol: "BTC", Threshold: 0.001}
+	engine.AddRule(rule)
+	engine.AddRule(rule)
+
+	tests := []struct {
+		name  string
+		at    time.Time
+		wantN int
+	}{
+		{name: "Первое срабатывание", at: now, wantN: 1},
+		{name: "Повтор внутри cooldown", at: now.Add(30 * time.Minute), wantN: 0},
+		{name: "Повтор после cooldown", at: now.Add(61 * time.Minute), wantN: 1},
+	}
+	for _, tt := range tests {
+		if got := engine.Evaluate(rates, tt.at); len(got) != tt.wantN {
+			t.Errorf("%s: оповещения %+v, ожидалось %d", tt.name, got, tt.wantN)
+		}
+	}
+}
+
+func TestRateOfChangeRule(t *testing.T) {
+	now := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	snapshot := func(rate float64) map[string][]exchanges.FundingRate {
+		return map[string][]exchanges.FundingRate{
+			"Bybit": {{Symbol: "ETHUSDT", Rate: rate, FundingInterval: 8 * time.Hour}},
+		}
+	}
+
+	// Правило по указателю тоже продлевает хранение истории
+	engine := exchanges.NewAlertEngine(time.Hour)
+	defer engine.Close()
+	engine.AddRule(&exchanges.RateOfChangeRule{RuleName: "jump", Symbol: "ETH", MinChange: 0.0005, Window: 30 * time.Minute})
+
+	if got := engine.Evaluate(snapshot(0.0001), now); len(got) != 0 {
+		t.Fatalf("без истории: %+v", got)
+	}
+	for _, step := range []struct {
+		after time.Duration
+		rate  float64
+	}{{10 * time.Minute, 0.0001}, {20 * time.Minute, 0.0003}} {
+		if got := engine.Evaluate(snapshot(step.rate), now.Add(step.after)); len(got) != 0 {
+			t.Fatalf("%s: нет наблюдения старше окна: %+v", step.after, got)
+		}
+	}
+	// Сравнение с наблюдением 10 минут, а не с последним
+	got := engine.Evaluate(snapshot(0.0009), now.Add(40*time.Minute))
+	if len(got) != 1 || got[0].Exchange != "Bybit" || !approxEqual(got[0].Value, 0.0008) {
+		t.Fatalf("оповещения %+v", got)
+	}
+}
+
+func TestSpreadRule(t *testing.T) {
+	ctx := &exchanges.AlertContext{
+		Now: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC),
+		Rates: map[string][]exchanges.FundingRate{
+			"Binance":     {{Symbol: "BTCUSDT", Rate: 0.0001, FundingInterval: 8 * time.Hour, VolumeUSDT24h: 5e6}},
+			"OKX":         {{Symbol: "BTC-USDT-SWAP", Rate: -0.0002, FundingInterval: 8 * time.Hour, VolumeUSDT24h: 5e6}},
+			"Hyperliquid": {{Symbol: "BTC", Rate: 0.0001, FundingInterval: time.Hour, VolumeUSDT24h: 1000}},
+		},
+	}
+
+	tests := []struct {
+		name string
+		rule exchanges.SpreadRule
+		want int
+	}{
+		// Hyperliquid отсекается по объему, спред Binance и OKX 0.03%
+		{name: "Спред выше порога", rule: exchanges.SpreadRule{RuleName: "spread", MinSpread: 0.0002, MinVolumeUSDT: 1e6}, want: 1},
+		{name: "Спред ниже порога", rule: exchanges.SpreadRule{RuleName: "spread", MinSpread: 0.0005, MinVolumeUSDT: 1e6}, want: 0},
+		{name: "Другой актив", rule: exchanges.SpreadRule{RuleName: "spread", Symbol: "ETH", MinVolumeUSDT: 1e6}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.rule.Evaluate(ctx)
+			if len(got) != tt.want {
+				t.Fatalf("оповещения %+v, ожидалось %d", got, tt.want)
+			}
+			if tt.want == 1 && (got[0].Symbol != "BTC" || !approxEqual(got[0].Value, 0.0003)) {
+				t.Errorf("оповещение %+v", got[0])
+			}
+		})
+	}
+}
+
+func TestAlertEngineAsyncDelivery(t *testing.T) {
+	release := make(chan struct{})
+	delivered := make(recordNotifier, 1)
+
+	engine := exchanges.NewAlertEngine(time.Hour)
+	engine.AddRule(exchanges.ThresholdRule{RuleName: "high", Threshold: 0.001})
+	engine.AddNotifier(notifierFunc(func(alert exchanges.Alert) error {
+		<-release
+		return nil
+	}))
+	engine.AddNotifier(delivered)
+
+	rates := map[string][]exchanges.FundingRate{
+		"Binance": {{Symbol: "BTCUSDT", Rate: 0.002, FundingInterval: 8 * time.Hour}},
+	}
+
+	// Медленный канал доставки не задерживает проверку правил
+	done := make(chan struct{})
+	go func() {
+		engine.Evaluate(rates, time.Now())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("проверка правил ждет доставки")
+	}
+
+	close(release)
+	engine.Close()
+	select {
+	case alert := <-delivered:
+		if alert.Symbol != "BTCUSDT" {
+			t.Errorf("оповещение %+v", alert)
+		}
+	default:
+		t.Fatal("Close вернулся до доставки оповещения")
+	}
+}
@@ -0,0 +1,429 @@
+package exchanges
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APIOptions настройки HTTP API
+type APIOptions struct {
+	DefaultLimit int           // размер страницы по умолчанию, 0 — без ограничения
+	MaxLimit     int           // максимальный размер страницы, 0 — без ограничения
+	StaleAfter   time.Duration // /health сообщает stale, если биржа не обновлялась дольше, 0 — не проверять
+}
+
+// apiRate представление ставки в ответах API
+type apiRate struct {
+	Exchange        string  `json:"exchange"`
+	Symbol          string  `json:"symbol"`
+	Canonical       string  `json:"canonical_symbol"`
+	Rate            float64 `json:"rate"`
+	Rate8h          float64 `json:"rate_8h"` // ставка, приведенная к 8 часам
+	APR             float64 `json:"apr"`
+	APY             float64 `json:"apy"`
+	PredictedRate   float64 `json:"predicted_rate,omitempty"`
+	NextFunding     string  `json:"next_funding"`
+	FundingInterval int64   `json:"funding_interval_seconds,omitempty"`
+	Volume24h       float64 `json:"volume_24h"`
+	VolumeUSDT24h   float64 `json:"volume_usdt_24h"`
+	MarkPrice       float64 `json:"mark_price,omitempty"`
+	IndexPrice      float64 `json:"index_price,omitempty"`
+	OpenInterest    float64 `json:"open_interest,omitempty"`
+	SettleAsset     string  `json:"settle_asset,omitempty"`
+	MarginType      string  `json:"margin_type,omitempty"`
+
+	Issues []ValidationCheck `json:"issues,omitempty"` // проблемы, отмеченные проверкой качества
+}
+
+func newAPIRate(exchange string, rate FundingRate) apiRate {
+	return apiRate{
+		Exchange:        exchange,
+		Symbol:          rate.Symbol,
+		Canonical:       CanonicalSymbol(rate.Symbol),
+		Rate:            rate.Rate,
+		Rate8h:          rate.Rate8h(),
+		APR:             rate.APR(),
+		APY:             rate.APY(),
+		PredictedRate:   rate.PredictedRate,
+		NextFunding:     rate.NextFunding,
+		FundingInterval: int64(rate.FundingInterval / time.Second),
+		Volume24h:       rate.Volume24h,
+		VolumeUSDT24h:   rate.VolumeUSDT24h,
+		MarkPrice:       rate.MarkPrice,
+		IndexPrice:      rate.IndexPrice,
+		OpenInterest:    rate.OpenInterest,
+		SettleAsset:     rate.SettleAsset,
+		MarginType:      string(rate.MarginType),
+		Issues:          rate.Issues,
+	}
+}
+
+// apiPage страница списка ставок
+type apiPage struct {
+	Version uint64    `json:"version"`
+	Total   int       `json:"total"`
+	Offset  int       `json:"offset"`
+	Limit   int       `json:"limit,omitempty"`
+	Data    []apiRate `json:"data"`
+}
+
+// apiExchange состояние биржи в ответах API
+type apiExchange struct {
+	Name            string     `json:"name"`
+	Rows            int        `json:"rows"`
+	LastUpdate      *time.Time `json:"last_update,omitempty"`
+	AgeSeconds      float64    `json:"age_seconds,omitempty"`
+	DurationSeconds float64    `json:"fetch_duration_seconds"`
+	Updates         uint64     `json:"updates"`
+	Errors          uint64     `json:"errors"`
+	LastError       string     `json:"last_error,omitempty"`
+	Stale           bool       `json:"stale"`
+}
+
+type apiHandler struct {
+	cache   *RatesCache
+	options APIOptions
+	mux     *http.ServeMux
+}
+
+// NewAPIHandler создает http.Handler с JSON API поверх кэша ставок:
+//
+//	GET /rates               все ставки
+//	GET /rates/{exchange}    ставки одной биржи
+//	GET /symbols/{symbol}    ставки актива на всех биржах (BTC, BTCUSDT, BTC-USDT-SWAP, ...)
+//	GET /exchanges           состояние бирж
+//	GET /health              общее состояние кэша
+//
+// Списки ставок поддерживают параметры min_volume, sort (rate, -rate, abs_rate, volume, symbol),
+// limit и offset
+func NewAPIHandler(cache *RatesCache, options APIOptions) http.Handler {
+	h := &apiHandler{cache: cache, options: options, mux: http.NewServeMux()}
+	h.mux.HandleFunc("GET /rates", h.handleRates)
+	h.mux.HandleFunc("GET /rates/{exchange}", h.handleExchangeRates)
+	h.mux.HandleFunc("GET /symbols/{symbol}", h.handleSymbol)
+	h.mux.HandleFunc("GET /exchanges", h.handleExchanges)
+	h.mux.HandleFunc("GET /health", h.handleHealth)
+	return h
+}
+
+func (h *apiHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Vary", "Accept-Encoding")
+
+	// Ответы /health и /exchanges содержат возраст данных и меняются без обновления кэша
+	if r.URL.Path != "/health" && r.URL.Path != "/exchanges" {
+		// Ставки и версия берутся одним снимком, иначе обновление между ними отдало бы
+		// новые данные со старым ETag
+		snapshot := &apiSnapshot{}
+		snapshot.rates, snapshot.version = h.cache.snapshot()
+		r = r.WithContext(context.WithValue(r.Context(), apiSnapshotKey{}, snapshot))
+
+		// ETag зависит от версии кэша и запроса, так как ответы различаются фильтрами
+		hash := fnv.New64a()
+		hash.Write([]byte(r.URL.Path + "?" + r.URL.RawQuery))
+		etag := fmt.Sprintf(`W/"%d-%x"`, snapshot.version, hash.Sum64())
+
+		w.Header().Set("ETag", etag)
+		for _, candidate := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+			if candidate = strings.TrimSpace(candidate); candidate == etag || candidate == "*" {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+	}
+
+	if acceptsGzip(r.Header.Get("Accept-Encoding")) {
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		defer gz.Close()
+		w = &gzipResponseWriter{ResponseWriter: w, writer: gz}
+	}
+
+	h.mux.ServeHTTP(w, r)
+}
+
+// apiSnapshot ставки кэша и их версия на момент запроса
+type apiSnapshot struct {
+	rates   map[string][]FundingRate
+	version uint64
+}
+
+type apiSnapshotKey struct{}
+
+// snapshot возвращает снимок ставок, сделанный для запроса в ServeHTTP
+func (h *apiHandler) snapshot(r *http.Request) *apiSnapshot {
+	if snapshot, ok := r.Context().Value(apiSnapshotKey{}).(*apiSnapshot); ok {
+		return snapshot
+	}
+	snapshot := &apiSnapshot{}
+	snapshot.rates, snapshot.version = h.cache.snapshot()
+	return snapshot
+}
+
+// acceptsGzip проверяет, разрешает ли заголовок Accept-Encoding сжатие gzip с учетом q-значений
+func acceptsGzip(header string) bool {
+	gzipQ, anyQ := -1.0, -1.0
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		coding := strings.ToLower(strings.TrimSpace(params[0]))
+		q := 1.0
+		for _, param := range params[1:] {
+			name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || !strings.EqualFold(strings.TrimSpace(name), "q") {
+				continue
+			}
+			v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				v = 0
+			}
+			q = v
+		}
+		switch coding {
+		case "gzip", "x-gzip":
+			gzipQ = q
+		case "*":
+			anyQ = q
+		}
+	}
+	if gzipQ >= 0 {
+		return gzipQ > 0
+	}
+	return anyQ > 0
+}
+
+// gzipResponseWriter сжимает тело ответа
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	writer *gzip.Writer
+}
+
+func (g *gzipResponseWriter) Write(b []byte) (int, error) {
+	return g.writer.Write(b)
+}
+
+func (g *gzipResponseWriter) WriteHeader(code int) {
+	g.ResponseWriter.Header().Del("Content-Length")
+	g.ResponseWriter.WriteHeader(code)
+}
+
+func (h *apiHandler) handleRates(w http.ResponseWriter, r *http.Request) {
+	var rows []apiRate
+	for exchange, rates := range h.snapshot(r).rates {
+		for _, rate := range rates {
+			rows = append(rows, newAPIRate(exchange, rate))
+		}
+	}
+	h.writePage(w, r, rows)
+}
+
+func (h *apiHandler) handleExchangeRates(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("exchange")
+	for exchange, rates := range h.snapshot(r).rates {
+		if !strings.EqualFold(exchange, name) {
+			continue
+		}
+		rows := make([]apiRate, 0, len(rates))
+		for _, rate := range rates {
+			rows = append(rows, newAPIRate(exchange, rate))
+		}
+		h.writePage(w, r, rows)
+		return
+	}
+	writeAPIError(w, http.StatusNotFound, fmt.Sprintf("биржа %s не найдена", name))
+}
+
+func (h *apiHandler) handleSymbol(w http.ResponseWriter, r *http.Request) {
+	symbol := CanonicalSymbol(r.PathValue("symbol"))
+	rows := make([]apiRate, 0)
+	for exchange, rates := range h.snapshot(r).rates {
+		for _, rate := range rates {
+			if CanonicalSymbol(rate.Symbol) == symbol {
+				rows = append(rows, newAPIRate(exchange, rate))
+			}
+		}
+	}
+	h.writePage(w, r, rows)
+}
+
+func (h *apiHandler) handleExchanges(w http.ResponseWriter, r *http.Request) {
+	writeAPIJSON(w, http.StatusOK, h.exchanges(time.Now()))
+}
+
+func (h *apiHandler) handleHealth(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	exchanges := h.exchanges(now)
+
+	status := "ok"
+	code := http.StatusOK
+	if len(exchanges) == 0 {
+		status = "empty"
+		code = http.StatusServiceUnavailable
+	}
+	for _, exchange := range exchanges {
+		if exchange.Stale {
+			status = "stale"
+		}
+	}
+
+	response := struct {
+		Status     string        `json:"status"`
+		Version    uint64        `json:"version"`
+		LastUpdate *time.Time    `json:"last_update,omitempty"`
+		Exchanges  []apiExchange `json:"exchanges"`
+	}{Status: status, Version: h.cache.Version(), Exchanges: exchanges}
+
+	if last := h.cache.GetLastUpdate(); !last.IsZero() {
+		response.LastUpdate = &last
+	}
+
+	writeAPIJSON(w, code, response)
+}
+
+// exchanges собирает состояние бирж, отсортированное по имени
+func (h *apiHandler) exchanges(now time.Time) []apiExchange {
+	statuses := h.cache.GetStatus()
+	result := make([]apiExchange, 0, len(statuses))
+	for _, status := range statuses {
+		exchange := apiExchange{
+			Name:            status.Name,
+			Rows:            status.Rows,
+			DurationSeconds: status.LastDuration.Seconds(),
+			Updates:         status.Updates,
+			Errors:          status.Errors,
+			LastError:       status.LastError,
+		}
+		if !status.LastUpdate.IsZero() {
+			lastUpdate := status.LastUpdate
+			exchange.LastUpdate = &lastUpdate
+			exchange.AgeSeconds = now.Sub(lastUpdate).Seconds()
+		}
+		if h.options.StaleAfter > 0 {
+			exchange.Stale = status.LastUpdate.IsZero() || now.Sub(status.LastUpdate) > h.options.StaleAfter
+		}
+		result = append(result, exchange)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// writePage применяет фильтры, сортировку и пагинацию из параметров запроса
+func (h *apiHandler) writePage(w http.ResponseWriter, r *http.Request, rows []apiRate) {
+	query := r.URL.Query()
+
+	if v := query.Get("min_volume"); v != "" {
+		minVolume, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, "некорректный min_volume")
+			return
+		}
+		filtered := rows[:0]
+		for _, row := range rows {
+			if row.VolumeUSDT24h >= minVolume {
+				filtered = append(filtered, row)
+			}
+		}
+		rows = filtered
+	}
+
+	if err := sortAPIRates(rows, query.Get("sort")); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limit := h.options.DefaultLimit
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeAPIError(w, http.StatusBadRequest, "некорректный limit")
+			return
+		}
+		limit = n
+	}
+	if h.options.MaxLimit > 0 && (limit == 0 || limit > h.options.MaxLimit) {
+		limit = h.options.MaxLimit
+	}
+
+	offset := 0
+	if v := query.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeAPIError(w, http.StatusBadRequest, "некорректный offset")
+			return
+		}
+		offset = n
+	}
+
+	page := apiPage{Version: h.snapshot(r).version, Total: len(rows), Offset: offset, Limit: limit}
+	if offset > len(rows) {
+		offset = len(rows)
+	}
+	end := len(rows)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+	page.Data = rows[offset:end]
+	if page.Data == nil {
+		page.Data = []apiRate{}
+	}
+
+	writeAPIJSON(w, http.StatusOK, page)
+}
+
+// sortAPIRates сортирует ставки по бирже и символу, затем по ключу, если он задан.
+// Ставки сравниваются приведенными к 8 часам, т.к. интервалы фандинга бирж различаются
+func sortAPIRates(rows []apiRate, key string) error {
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Exchange != rows[j].Exchange {
+			return rows[i].Exchange < rows[j].Exchange
+		}
+		return rows[i].Symbol < rows[j].Symbol
+	})
+
+	desc := strings.HasPrefix(key, "-")
+	key = strings.TrimPrefix(key, "-")
+
+	var less func(a, b apiRate) bool
+	switch key {
+	case "":
+		return nil
+	case "symbol":
+		less = func(a, b apiRate) bool { return a.Symbol < b.Symbol }
+	case "rate":
+		less = func(a, b apiRate) bool { return a.Rate8h < b.Rate8h }
+	case "abs_rate":
+		less = func(a, b apiRate) bool { return math.Abs(a.Rate8h) < math.Abs(b.Rate8h) }
+	case "volume":
+		less = func(a, b apiRate) bool { return a.VolumeUSDT24h < b.VolumeUSDT24h }
+	default:
+		return fmt.Errorf("неизвестный ключ сортировки %s", key)
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if desc {
+			return less(rows[j], rows[i])
+		}
+		return less(rows[i], rows[j])
+	})
+	return nil
+}
+
+func writeAPIJSON(w http.ResponseWriter, code int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(payload); err != nil {
+		log.Printf("Ошибка записи ответа API: %v", err)
+	}
+}
+
+func writeAPIError(w http.ResponseWriter, code int, message string) {
+	writeAPIJSON(w, code, map[string]string{"error": message})
+}
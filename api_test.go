@@ -0,0 +1,174 @@
+package exchanges_test
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+)
+
+// apiPage ответ API со списком ставок
+type apiPage struct {
+	Version uint64 `json:"version"`
+	Total   int    `json:"total"`
+	Data    []struct {
+		Exchange      string  `json:"exchange"`
+		Symbol        string  `json:"symbol"`
+		Rate8h        float64 `json:"rate_8h"`
+		VolumeUSDT24h float64 `json:"volume_usdt_24h"`
+	} `json:"data"`
+}
+
+func newTestAPI(t *testing.T) (*exchanges.RatesCache, *staticExchange, http.Handler) {
+	t.Helper()
+
+	next := time.Now().Add(time.Hour).Format(time.RFC3339)
+	cache := newTestCache()
+	exchange := &staticExchange{name: "Binance", rates: []exchanges.FundingRate{
+		{Symbol: "BTCUSDT", Rate: 0.0001, NextFunding: next, Volume24h: 10, VolumeUSDT24h: 5000000},
+		{Symbol: "ETHUSDT", Rate: -0.0003, NextFunding: next, Volume24h: 10, VolumeUSDT24h: 20000},
+		{Symbol: "SOLUSDT", Rate: 0.0002, NextFunding: next, Volume24h: 10, VolumeUSDT24h: 100000},
+	}}
+	if err := cache.UpdateRates(exchange); err != nil {
+		t.Fatal(err)
+	}
+	return cache, exchange, exchanges.NewAPIHandler(cache, exchanges.APIOptions{})
+}
+
+func serveAPI(handler http.Handler, target string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAPIETag(t *testing.T) {
+	cache, exchange, handler := newTestAPI(t)
+
+	rec := serveAPI(handler, "/rates", nil)
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag == "" {
+		t.Fatalf("код %d, ETag %q", rec.Code, etag)
+	}
+	var page apiPage
+	if err := json.NewDecoder(rec.Body).Decode(&page); err != nil {
+		t.Fatal(err)
+	}
+	if page.Version != cache.Version() {
+		t.Errorf("версия страницы %d, версия кэша %d", page.Version, cache.Version())
+	}
+
+	if rec := serveAPI(handler, "/rates", map[string]string{"If-None-Match": etag}); rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("повторный запрос: код %d, тело %q", rec.Code, rec.Body.String())
+	}
+	// ETag учитывает параметры запроса
+	if rec := serveAPI(handler, "/rates?sort=rate", map[string]string{"If-None-Match": etag}); rec.Code != http.StatusOK {
+		t.Errorf("запрос с другими параметрами: код %d", rec.Code)
+	}
+
+	if err := cache.UpdateRates(exchange); err != nil {
+		t.Fatal(err)
+	}
+	rec = serveAPI(handler, "/rates", map[string]string{"If-None-Match": etag})
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") == etag {
+		t.Errorf("после обновления кэша: код %d, ETag %q", rec.Code, rec.Header().Get("ETag"))
+	}
+}
+
+func TestAPIGzip(t *testing.T) {
+	_, _, handler := newTestAPI(t)
+
+	tests := []struct {
+		acceptEncoding string
+		wantGzip       bool
+	}{
+		{acceptEncoding: "", wantGzip: false},
+		{acceptEncoding: "gzip", wantGzip: true},
+		{acceptEncoding: "deflate, gzip;q=0.5", wantGzip: true},
+		{acceptEncoding: "gzip;q=0", wantGzip: false},
+		{acceptEncoding: "gzip; q=0.0, deflate", wantGzip: false},
+		{acceptEncoding: "*", wantGzip: true},
+		{acceptEncoding: "*;q=0", wantGzip: false},
+		{acceptEncoding: "br, *;q=0.1", wantGzip: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.acceptEncoding, func(t *testing.T) {
+			rec := serveAPI(handler, "/rates", map[string]string{"Accept-Encoding": tt.acceptEncoding})
+			if got := rec.Header().Get("Content-Encoding") == "gzip"; got != tt.wantGzip {
+				t.Fatalf("сжатие %v, ожидалось %v", got, tt.wantGzip)
+			}
+
+			var body io.Reader = rec.Body
+			if tt.wantGzip {
+				gz, err := gzip.NewReader(rec.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				body = gz
+			}
+			var page apiPage
+			if err := json.NewDecoder(body).Decode(&page); err != nil {
+				t.Fatal(err)
+			}
+			if page.Total != 3 {
+				t.Errorf("ставок %d, ожидалось 3", page.Total)
+			}
+		})
+	}
+}
+
+func TestAPIFilters(t *testing.T) {
+	_, _, handler := newTestAPI(t)
+
+	tests := []struct {
+		target    string
+		wantCode  int
+		wantTotal int
+		want      []string
+	}{
+		{target: "/rates", wantCode: http.StatusOK, wantTotal: 3, want: []string{"BTCUSDT", "ETHUSDT", "SOLUSDT"}},
+		{target: "/rates?min_volume=50000", wantCode: http.StatusOK, wantTotal: 2, want: []string{"BTCUSDT", "SOLUSDT"}},
+		{target: "/rates?sort=-rate", wantCode: http.StatusOK, wantTotal: 3, want: []string{"SOLUSDT", "BTCUSDT", "ETHUSDT"}},
+		{target: "/rates?sort=abs_rate&limit=1&offset=1", wantCode: http.StatusOK, wantTotal: 3, want: []string{"SOLUSDT"}},
+		{target: "/rates/binance?sort=volume", wantCode: http.StatusOK, wantTotal: 3, want: []string{"ETHUSDT", "SOLUSDT", "BTCUSDT"}},
+		{target: "/symbols/ETH-USDT-SWAP", wantCode: http.StatusOK, wantTotal: 1, want: []string{"ETHUSDT"}},
+		{target: "/rates/bybit", wantCode: http.StatusNotFound},
+		{target: "/rates?sort=apr", wantCode: http.StatusBadRequest},
+		{target: "/rates?min_volume=abc", wantCode: http.StatusBadRequest},
+		{target: "/rates?limit=-1", wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			rec := serveAPI(handler, tt.target, nil)
+			if rec.Code != tt.wantCode {
+				t.Fatalf("код %d, ожидалось %d: %s", rec.Code, tt.wantCode, rec.Body.String())
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			var page apiPage
+			if err := json.NewDecoder(rec.Body).Decode(&page); err != nil {
+				t.Fatal(err)
+			}
+			if page.Total != tt.wantTotal || len(page.Data) != len(tt.want) {
+				t.Fatalf("всего %d, страница %+v", page.Total, page.Data)
+			}
+			for i, symbol := range tt.want {
+				if page.Data[i].Symbol != symbol {
+					t.Errorf("позиция %d: %s, ожидалось %s", i, page.Data[i].Symbol, symbol)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package exchanges
+
+import "sort"
+
+// SpreadLeg ставка одной биржи в межбиржевом спреде
+type SpreadLeg struct {
+	Exchange string
+	FundingRate
+}
+
+// Spread разница ставок фандинга одного актива на разных биржах
+type Spread struct {
+	Symbol string    // канонический символ (см. CanonicalSymbol)
+	Low    SpreadLeg // биржа с минимальной ставкой
+	High   SpreadLeg // биржа с максимальной ставкой
+	Spread float64   // High.Rate8h() - Low.Rate8h(): ставки приводятся к 8 часам, т.к. интервалы бирж различаются
+}
+
+// APR спред в годовом выражении без реинвестирования
+func (s Spread) APR() float64 {
+	return s.High.APR() - s.Low.APR()
+}
+
+// CrossExchangeSpreads находит для каждого актива максимальный спред ставок между биржами.
+// Ставки с объемом меньше minVolumeUSDT не учитываются. Результат отсортирован по убыванию спреда
+func CrossExchangeSpreads(rates map[string][]FundingRate, minVolumeUSDT float64) []Spread {
+	legs := make(map[string][]SpreadLeg)
+	for exchange, list := range rates {
+		for _, rate := range list {
+			if rate.VolumeUSDT24h < minVolumeUSDT {
+				continue
+			}
+			symbol := CanonicalSymbol(rate.Symbol)
+			legs[symbol] = append(legs[symbol], SpreadLeg{Exchange: exchange, FundingRate: rate})
+		}
+	}
+
+	var result []Spread
+	for symbol, list := range legs {
+		// Одна биржа может листить актив в нескольких контрактах, поэтому сравниваем только пары разных бирж
+		var best *Spread
+		for i := range list {
+			for j := range list {
+				if list[i].Exchange == list[j].Exchange {
+					continue
+				}
+				spread := list[j].Rate8h() - list[i].Rate8h()
+				if best == nil || spread > best.Spread {
+					best = &Spread{Symbol: symbol, Low: list[i], High: list[j], Spread: spread}
+				}
+			}
+		}
+		if best != nil {
+			result = append(result, *best)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Spread != result[j].Spread {
+			return result[i].Spread > result[j].Spread
+		}
+		return result[i].Symbol < result[j].Symbol
+	})
+	return result
+}
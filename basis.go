@@ -0,0 +1,90 @@
+package exchanges
+
+import (
+	"sort"
+	"time"
+)
+
+// DefaultBasisHorizon срок схождения базиса по умолчанию при пересчете в годовой
+const DefaultBasisHorizon = 30 * 24 * time.Hour
+
+// SpotPriceProvider биржа, отдающая цены спотовых пар.
+// Цены возвращаются по символам бессрочных контрактов той же биржи, чтобы их можно было сопоставить с FundingRate
+type SpotPriceProvider interface {
+	GetName() string
+	GetSpotPrices() (map[string]float64, error)
+}
+
+// Basis базис между спотом и бессрочным контрактом одной биржи.
+// Доходность рассчитана для связки лонг спот / шорт контракт: шорт получает положительный фандинг,
+// а положительный базис возвращается при схождении цены контракта со спотом
+type Basis struct {
+	Exchange        string
+	Symbol          string // символ контракта на бирже
+	SpotPrice       float64
+	MarkPrice       float64
+	Basis           float64 // (MarkPrice - SpotPrice) / SpotPrice
+	AnnualizedBasis float64 // Basis в годовом выражении при схождении за BasisOptions.Horizon
+	FundingAPR      float64 // ставка фандинга в годовом выражении без реинвестирования
+	Carry           float64 // FundingAPR + AnnualizedBasis
+}
+
+// BasisOptions параметры расчета базиса
+type BasisOptions struct {
+	Horizon       time.Duration // срок схождения базиса, 0 — DefaultBasisHorizon
+	MinVolumeUSDT float64       // контракты с меньшим объемом за 24 часа не учитываются
+}
+
+// CalculateBasis сопоставляет ставки биржи со спотовыми ценами (см. SpotPriceProvider).
+// Контракты без маркировочной цены или без спотовой пары пропускаются.
+// Результат отсортирован по убыванию Carry
+func CalculateBasis(exchange string, rates []FundingRate, spot map[string]float64, options BasisOptions) []Basis {
+	horizon := options.Horizon
+	if horizon <= 0 {
+		horizon = DefaultBasisHorizon
+	}
+	year := DaysPerYear * 24 * time.Hour
+
+	var result []Basis
+	for _, rate := range rates {
+		if rate.VolumeUSDT24h < options.MinVolumeUSDT || rate.MarkPrice <= 0 {
+			continue
+		}
+		spotPrice, ok := spot[rate.Symbol]
+		if !ok || spotPrice <= 0 {
+			continue
+		}
+
+		basis := (rate.MarkPrice - spotPrice) / spotPrice
+		annualized := basis * float64(year) / float64(horizon)
+		fundingAPR := rate.APR()
+		result = append(result, Basis{
+			Exchange:        exchange,
+			Symbol:          rate.Symbol,
+			SpotPrice:       spotPrice,
+			MarkPrice:       rate.MarkPrice,
+			Basis:           basis,
+			AnnualizedBasis: annualized,
+			FundingAPR:      fundingAPR,
+			Carry:           fundingAPR + annualized,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Carry != result[j].Carry {
+			return result[i].Carry > result[j].Carry
+		}
+		return result[i].Symbol < result[j].Symbol
+	})
+	return result
+}
+
+// FetchBasis запрашивает спотовые цены биржи и рассчитывает базис по ее ставкам.
+// rates — ставки той же биржи, полученные через GetFundingRates (например, из кэша)
+func FetchBasis(provider SpotPriceProvider, rates []FundingRate, options BasisOptions) ([]Basis, error) {
+	spot, err := provider.GetSpotPrices()
+	if err != nil {
+		return nil, err
+	}
+	return CalculateBasis(provider.GetName(), rates, spot, options), nil
+}
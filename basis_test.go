@@ -0,0 +1,75 @@
+package exchanges_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+	"github.com/petrixs/cr-exchanges/exchangetest"
+)
+
+func TestCalculateBasis(t *testing.T) {
+	rates := []exchanges.FundingRate{
+		{Symbol: "BTCUSDT", Rate: 0.0001, FundingInterval: 8 * time.Hour, MarkPrice: 50500, VolumeUSDT24h: 1e9},
+		{Symbol: "ETHUSDT", Rate: -0.0002, FundingInterval: 8 * time.Hour, MarkPrice: 2490, VolumeUSDT24h: 1e8},
+		{Symbol: "NOSPOTUSDT", Rate: 0.001, MarkPrice: 1, VolumeUSDT24h: 1e8},
+		{Symbol: "NOMARKUSDT", Rate: 0.001, VolumeUSDT24h: 1e8},
+		{Symbol: "THINUSDT", Rate: 0.001, MarkPrice: 1, VolumeUSDT24h: 10},
+		// Без объема в USDT контракт не проходит порог, объем в контрактах не учитывается
+		{Symbol: "NOUSDTUSDT", Rate: 0.001, MarkPrice: 100, Volume24h: 1e6},
+	}
+	spot := map[string]float64{"BTCUSDT": 50000, "ETHUSDT": 2500, "NOMARKUSDT": 1, "THINUSDT": 1, "NOUSDTUSDT": 100}
+
+	got := exchanges.CalculateBasis("Test", rates, spot, exchanges.BasisOptions{Horizon: 73 * 24 * time.Hour, MinVolumeUSDT: 1000})
+	if len(got) != 2 || got[0].Symbol != "BTCUSDT" || got[1].Symbol != "ETHUSDT" {
+		t.Fatalf("базис %+v", got)
+	}
+
+	// 1% за 73 дня — 5% годовых, фандинг 0.01% три раза в сутки — 10.95% годовых
+	btc := got[0]
+	if !approxEqual(btc.Basis, 0.01) || !approxEqual(btc.AnnualizedBasis, 0.05) ||
+		!approxEqual(btc.FundingAPR, 0.1095) || !approxEqual(btc.Carry, 0.1595) {
+		t.Errorf("BTC: %+v", btc)
+	}
+	eth := got[1]
+	if !approxEqual(eth.Basis, -0.004) || !approxEqual(eth.Carry, -0.219-0.02) {
+		t.Errorf("ETH: %+v", eth)
+	}
+}
+
+func TestFetchBasis(t *testing.T) {
+	server := newMockServer(t)
+	venues := []exchangetest.Venue{exchangetest.VenueBinance, exchangetest.VenueBybit, exchangetest.VenueOKX, exchangetest.VenueGate}
+	for _, venue := range venues {
+		server.SetRate(venue, "BTC", exchangetest.MockRate{Rate: 0.0001, Volume: 1000, Price: 50500, SpotPrice: 50000})
+	}
+
+	for _, venue := range venues {
+		exchange := mockExchanges[venue]()
+		provider, ok := exchange.(exchanges.SpotPriceProvider)
+		if !ok {
+			t.Fatalf("%s: нет спотовых цен", venue)
+		}
+		rates, err := exchange.GetFundingRates()
+		if err != nil {
+			t.Fatalf("%s: %v", venue, err)
+		}
+
+		got, err := exchanges.FetchBasis(provider, rates, exchanges.BasisOptions{})
+		if err != nil {
+			t.Fatalf("%s: %v", venue, err)
+		}
+		if len(got) != 1 {
+			t.Fatalf("%s: базис %+v", venue, got)
+		}
+		basis := got[0]
+		if basis.Symbol != exchangetest.Symbol(venue, "BTC") || basis.SpotPrice != 50000 || basis.MarkPrice != 50500 || !approxEqual(basis.Basis, 0.01) {
+			t.Errorf("%s: базис %+v", venue, basis)
+		}
+	}
+}
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
@@ -2,17 +2,27 @@ package exchanges
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
 )
 
-type Binance struct{}
+type Binance struct {
+	Credentials CredentialProvider // Ключи для приватных запросов, по умолчанию из переменных окружения BINANCE_*
+
+	IncludeInverse bool // Включать бессрочные контракты с маржой в монете (dapi)
+
+	// Символы USDT-M контрактов, для которых PredictedRate рассчитывается по индексу премии
+	// (см. EstimatePredictedRates); по одному запросу на символ
+	PredictSymbols []string
+}
 
 func NewBinance() *Binance {
 	log.Println("Инициализация Binance")
-	return &Binance{}
+	return &Binance{Credentials: EnvCredentials{Prefix: "BINANCE"}}
 }
 
 func (b *Binance) GetName() string {
@@ -22,8 +32,28 @@ func (b *Binance) GetName() string {
 func (b *Binance) GetFundingRates() ([]FundingRate, error) {
 	log.Println("Запрос ставок фандинга с Binance")
 
+	result, err := b.getLinearRates()
+	if err != nil {
+		return nil, err
+	}
+
+	if b.IncludeInverse {
+		inverse, err := b.getInverseRates()
+		if err != nil {
+			log.Printf("Ошибка получения ставок инверсных контрактов Binance: %v", err)
+		} else {
+			result = append(result, inverse...)
+		}
+	}
+
+	log.Printf("Получено %d ставок фандинга с объемами с Binance", len(result))
+	return result, nil
+}
+
+// getLinearRates получает ставки USDT-M контрактов (fapi)
+func (b *Binance) getLinearRates() ([]FundingRate, error) {
 	// Получаем фандинг ставки
-	fundingResp, err := http.Get("https://fapi.binance.com/fapi/v1/premiumIndex")
+	fundingResp, err := httpGet("https://fapi.binance.com/fapi/v1/premiumIndex")
 	if err != nil {
 		log.Printf("Ошибка запроса фандинга к Binance: %v", err)
 		return nil, err
@@ -32,17 +62,19 @@ func (b *Binance) GetFundingRates() ([]FundingRate, error) {
 
 	var fundingRates []struct {
 		Symbol          string  `json:"symbol"`
-		LastFundingRate float64 `json:"lastFundingRate,string"`
+		MarkPrice       Decimal `json:"markPrice"`
+		IndexPrice      Decimal `json:"indexPrice"`
+		LastFundingRate Decimal `json:"lastFundingRate"`
 		NextFundingTime int64   `json:"nextFundingTime"`
 	}
 
-	if err := json.NewDecoder(fundingResp.Body).Decode(&fundingRates); err != nil {
+	if err := decodeResponse(b.GetName(), fundingResp, &fundingRates); err != nil {
 		log.Printf("Ошибка декодирования фандинга от Binance: %v", err)
 		return nil, err
 	}
 
 	// Получаем объемы торгов
-	volumeResp, err := http.Get("https://fapi.binance.com/fapi/v1/ticker/24hr")
+	volumeResp, err := httpGet("https://fapi.binance.com/fapi/v1/ticker/24hr")
 	if err != nil {
 		log.Printf("Ошибка запроса объемов к Binance: %v", err)
 		return nil, err
@@ -55,24 +87,28 @@ func (b *Binance) GetFundingRates() ([]FundingRate, error) {
 		QuoteVolume string `json:"quoteVolume"`
 	}
 
-	if err := json.NewDecoder(volumeResp.Body).Decode(&volumeData); err != nil {
+	if err := decodeResponse(b.GetName(), volumeResp, &volumeData); err != nil {
 		log.Printf("Ошибка декодирования объемов от Binance: %v", err)
 		return nil, err
 	}
 
 	// Создаем карту объемов для быстрого поиска
 	volumeMap := make(map[string]struct {
-		Volume      float64
-		QuoteVolume float64
+		Volume      Decimal
+		QuoteVolume Decimal
 	})
 
 	for _, vol := range volumeData {
-		volume, _ := strconv.ParseFloat(vol.Volume, 64)
-		quoteVolume, _ := strconv.ParseFloat(vol.QuoteVolume, 64)
 		volumeMap[vol.Symbol] = struct {
-			Volume      float64
-			QuoteVolume float64
-		}{volume, quoteVolume}
+			Volume      Decimal
+			QuoteVolume Decimal
+		}{parseDecimal(vol.Volume), parseDecimal(vol.QuoteVolume)}
+	}
+
+	intervals, err := b.getFundingIntervals()
+	if err != nil {
+		log.Printf("Ошибка получения интервалов фандинга Binance: %v", err)
+		intervals = make(map[string]time.Duration)
 	}
 
 	// Объединяем данные
@@ -80,21 +116,402 @@ func (b *Binance) GetFundingRates() ([]FundingRate, error) {
 	location := GetLocationFromEnv()
 
 	for i, rate := range fundingRates {
-		var volume24h, volumeUSDT24h float64
+		var volume24h, volumeUSDT24h Decimal
 		if vol, exists := volumeMap[rate.Symbol]; exists {
 			volume24h = vol.Volume
 			volumeUSDT24h = vol.QuoteVolume
 		}
 
+		interval, ok := intervals[rate.Symbol]
+		if !ok {
+			interval = 8 * time.Hour
+		}
+
 		result[i] = FundingRate{
-			Symbol:        rate.Symbol,
-			Rate:          rate.LastFundingRate,
-			NextFunding:   time.Unix(rate.NextFundingTime/1000, 0).In(location).Format(time.RFC3339),
-			Volume24h:     volume24h,
-			VolumeUSDT24h: volumeUSDT24h,
+			Symbol:          rate.Symbol,
+			Rate:            rate.LastFundingRate.Float64(),
+			NextFunding:     time.Unix(rate.NextFundingTime/1000, 0).In(location).Format(time.RFC3339),
+			Volume24h:       volume24h.Float64(),
+			VolumeUSDT24h:   volumeUSDT24h.Float64(),
+			MarkPrice:       rate.MarkPrice.Float64(),
+			IndexPrice:      rate.IndexPrice.Float64(),
+			FundingInterval: interval,
+			MarginType:      MarginLinear,
+			Precise:         newPrecise(rate.LastFundingRate, Decimal{}, volume24h, volumeUSDT24h),
 		}
 	}
 
-	log.Printf("Получено %d ставок фандинга с объемами с Binance", len(result))
+	if len(b.PredictSymbols) > 0 {
+		b.predictRates(result)
+	}
+
+	return result, nil
+}
+
+// predictRates заполняет PredictedRate для символов из PredictSymbols
+func (b *Binance) predictRates(rates []FundingRate) {
+	indexes := make(map[string]int, len(rates))
+	for i, rate := range rates {
+		indexes[rate.Symbol] = i
+	}
+
+	var selected []FundingRate
+	for _, symbol := range b.PredictSymbols {
+		if i, ok := indexes[symbol]; ok {
+			selected = append(selected, rates[i])
+		}
+	}
+	if err := EstimatePredictedRates(b, DefaultFundingFormulas[b.GetName()], selected, time.Now()); err != nil {
+		log.Printf("Ошибка прогноза ставок Binance: %v", err)
+	}
+	for _, rate := range selected {
+		rates[indexes[rate.Symbol]].PredictedRate = rate.PredictedRate
+		rates[indexes[rate.Symbol]].Precise = rate.Precise
+	}
+}
+
+// getFundingIntervals получает интервалы фандинга USDT-M контрактов.
+// Binance перечисляет только контракты с нестандартными параметрами, остальные — 8 часов
+func (b *Binance) getFundingIntervals() (map[string]time.Duration, error) {
+	resp, err := httpGet("https://fapi.binance.com/fapi/v1/fundingInfo")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var fundingInfo []struct {
+		Symbol               string `json:"symbol"`
+		FundingIntervalHours int64  `json:"fundingIntervalHours"`
+	}
+
+	if err := decodeResponse(b.GetName(), resp, &fundingInfo); err != nil {
+		return nil, err
+	}
+
+	intervals := make(map[string]time.Duration)
+	for _, info := range fundingInfo {
+		if info.FundingIntervalHours > 0 {
+			intervals[info.Symbol] = time.Duration(info.FundingIntervalHours) * time.Hour
+		}
+	}
+	return intervals, nil
+}
+
+// GetPremiumIndex возвращает минутные значения индекса премии USDT-M контракта за период (до 1500 минут)
+func (b *Binance) GetPremiumIndex(symbol string, from, to time.Time) ([]PremiumIndexPoint, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("interval", "1m")
+	params.Set("startTime", strconv.FormatInt(from.UnixMilli(), 10))
+	params.Set("endTime", strconv.FormatInt(to.UnixMilli(), 10))
+	params.Set("limit", "1500")
+
+	resp, err := httpGet("https://fapi.binance.com/fapi/v1/premiumIndexKlines?" + params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Binance API ошибка: статус %d", resp.StatusCode)
+	}
+
+	// Свеча: [время открытия, open, high, low, close, ...]
+	var klines [][]interface{}
+	if err := decodeResponse(b.GetName(), resp, &klines); err != nil {
+		return nil, err
+	}
+
+	points := make([]PremiumIndexPoint, 0, len(klines))
+	for _, kline := range klines {
+		if len(kline) < 5 {
+			continue
+		}
+		openTime, _ := kline[0].(float64)
+		closePrice, _ := kline[4].(string)
+		points = append(points, PremiumIndexPoint{
+			Time:    time.UnixMilli(int64(openTime)).UTC(),
+			Premium: parseFloatFromString(closePrice),
+		})
+	}
+	return points, nil
+}
+
+// GetSpotPrices возвращает последние цены спотовых пар Binance по символам USDT-контрактов
+func (b *Binance) GetSpotPrices() (map[string]float64, error) {
+	resp, err := httpGet("https://api.binance.com/api/v3/ticker/price")
+	if err != nil {
+		log.Printf("Ошибка запроса спотовых цен Binance: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var tickers []struct {
+		Symbol string `json:"symbol"`
+		Price  string `json:"price"`
+	}
+	if err := decodeResponse(b.GetName(), resp, &tickers); err != nil {
+		log.Printf("Ошибка декодирования спотовых цен Binance: %v", err)
+		return nil, err
+	}
+
+	// Спотовая пара и USDT-контракт Binance называются одинаково, например BTCUSDT
+	prices := make(map[string]float64, len(tickers))
+	for _, ticker := range tickers {
+		if price := parseFloatFromString(ticker.Price); price > 0 {
+			prices[ticker.Symbol] = price
+		}
+	}
+	return prices, nil
+}
+
+// getInverseRates получает ставки бессрочных COIN-M контрактов (dapi)
+func (b *Binance) getInverseRates() ([]FundingRate, error) {
+	// Номинал контракта в USD нужен для пересчета объема из контрактов
+	infoResp, err := httpGet("https://dapi.binance.com/dapi/v1/exchangeInfo")
+	if err != nil {
+		return nil, err
+	}
+	defer infoResp.Body.Close()
+
+	var exchangeInfo struct {
+		Symbols []struct {
+			Symbol       string  `json:"symbol"`
+			ContractType string  `json:"contractType"`
+			ContractSize Decimal `json:"contractSize"`
+			MarginAsset  string  `json:"marginAsset"`
+		} `json:"symbols"`
+	}
+
+	if err := decodeResponse(b.GetName(), infoResp, &exchangeInfo); err != nil {
+		return nil, err
+	}
+
+	type contractInfo struct {
+		size        Decimal
+		marginAsset string
+	}
+
+	contracts := make(map[string]contractInfo)
+	for _, symbol := range exchangeInfo.Symbols {
+		if symbol.ContractType == "PERPETUAL" {
+			contracts[symbol.Symbol] = contractInfo{symbol.ContractSize, symbol.MarginAsset}
+		}
+	}
+
+	fundingResp, err := httpGet("https://dapi.binance.com/dapi/v1/premiumIndex")
+	if err != nil {
+		return nil, err
+	}
+	defer fundingResp.Body.Close()
+
+	var fundingRates []struct {
+		Symbol          string `json:"symbol"`
+		LastFundingRate string `json:"lastFundingRate"`
+		NextFundingTime int64  `json:"nextFundingTime"`
+	}
+
+	if err := decodeResponse(b.GetName(), fundingResp, &fundingRates); err != nil {
+		return nil, err
+	}
+
+	volumeResp, err := httpGet("https://dapi.binance.com/dapi/v1/ticker/24hr")
+	if err != nil {
+		return nil, err
+	}
+	defer volumeResp.Body.Close()
+
+	var volumeData []struct {
+		Symbol     string `json:"symbol"`
+		Volume     string `json:"volume"`     // в контрактах
+		BaseVolume string `json:"baseVolume"` // в монете
+	}
+
+	if err := decodeResponse(b.GetName(), volumeResp, &volumeData); err != nil {
+		return nil, err
+	}
+
+	volumeMap := make(map[string]struct {
+		Contracts Decimal
+		Base      Decimal
+	})
+
+	for _, vol := range volumeData {
+		volumeMap[vol.Symbol] = struct {
+			Contracts Decimal
+			Base      Decimal
+		}{parseDecimal(vol.Volume), parseDecimal(vol.BaseVolume)}
+	}
+
+	var result []FundingRate
+	location := GetLocationFromEnv()
+
+	for _, rate := range fundingRates {
+		contract, ok := contracts[rate.Symbol]
+		// Квартальные контракты не имеют фандинга
+		if !ok || rate.LastFundingRate == "" {
+			continue
+		}
+
+		vol := volumeMap[rate.Symbol]
+		fundingRate := parseDecimal(rate.LastFundingRate)
+		volumeUSDT := derived(vol.Contracts.Value.Mul(contract.size.Value))
+
+		result = append(result, FundingRate{
+			Symbol:          rate.Symbol,
+			Rate:            fundingRate.Float64(),
+			NextFunding:     time.Unix(rate.NextFundingTime/1000, 0).In(location).Format(time.RFC3339),
+			Volume24h:       vol.Base.Float64(),
+			VolumeUSDT24h:   volumeUSDT.Float64(),
+			FundingInterval: 8 * time.Hour,
+			SettleAsset:     contract.marginAsset,
+			MarginType:      MarginInverse,
+			Precise:         newPrecise(fundingRate, Decimal{}, vol.Base, volumeUSDT),
+		})
+	}
+
+	return result, nil
+}
+
+// binanceIncome запись истории доходов счета USDT-M
+type binanceIncome struct {
+	Symbol string `json:"symbol"`
+	Income string `json:"income"`
+	Asset  string `json:"asset"`
+	Time   int64  `json:"time"`
+	TranID int64  `json:"tranId"`
+}
+
+// GetFundingPayments возвращает начисления фандинга по USDT-M счету (доходы типа FUNDING_FEE)
+func (b *Binance) GetFundingPayments(query FundingPaymentsQuery) ([]FundingPayment, error) {
+	credentials, err := resolveCredentials(b.GetName(), b.Credentials, false)
+	if err != nil {
+		return nil, err
+	}
+
+	const pageSize = 1000
+	var result []FundingPayment
+	seen := make(map[int64]bool)
+	startTime := query.From
+
+	for {
+		params := url.Values{}
+		params.Set("incomeType", "FUNDING_FEE")
+		params.Set("limit", strconv.Itoa(pageSize))
+		if query.Symbol != "" {
+			params.Set("symbol", query.Symbol)
+		}
+		if !startTime.IsZero() {
+			params.Set("startTime", strconv.FormatInt(startTime.UnixMilli(), 10))
+		}
+		if !query.To.IsZero() {
+			params.Set("endTime", strconv.FormatInt(query.To.UnixMilli(), 10))
+		}
+
+		var page []binanceIncome
+		if err := b.signedGet(credentials, "https://fapi.binance.com/fapi/v1/income", params, &page); err != nil {
+			return nil, err
+		}
+
+		added := 0
+		for _, income := range page {
+			if seen[income.TranID] {
+				continue
+			}
+			seen[income.TranID] = true
+			added++
+
+			result = append(result, FundingPayment{
+				Exchange: b.GetName(),
+				Symbol:   income.Symbol,
+				Amount:   parseFloatFromString(income.Income),
+				Asset:    income.Asset,
+				Time:     time.UnixMilli(income.Time),
+				ID:       strconv.FormatInt(income.TranID, 10),
+			})
+		}
+
+		if len(page) < pageSize || query.reachedLimit(len(result)) {
+			break
+		}
+		// Фандинг всех символов начисляется в одну миллисекунду, поэтому следующая страница
+		// начинается со времени последней записи, а повторы отсекаются по tranId. Если вся
+		// страница уже получена, записи этой миллисекунды исчерпаны для API — переходим к следующей
+		startTime = time.UnixMilli(page[len(page)-1].Time)
+		if added == 0 {
+			startTime = startTime.Add(time.Millisecond)
+		}
+	}
+
+	log.Printf("Получено %d начислений фандинга с Binance", len(result))
+	return query.truncate(result), nil
+}
+
+// signedGet выполняет подписанный GET-запрос к fapi и декодирует ответ в out
+func (b *Binance) signedGet(credentials Credentials, endpoint string, params url.Values, out interface{}) error {
+	params.Set("timestamp", strconv.FormatInt(time.Now().UnixMilli(), 10))
+	query := params.Encode()
+	query += "&signature=" + signHMACSHA256Hex(credentials.SecretKey, query)
+
+	req, err := http.NewRequest("GET", endpoint+"?"+query, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-MBX-APIKEY", credentials.APIKey)
+
+	resp, err := httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Code int    `json:"code"`
+			Msg  string `json:"msg"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		return fmt.Errorf("Binance API ошибка: HTTP %d, %d - %s", resp.StatusCode, apiErr.Code, apiErr.Msg)
+	}
+
+	return decodeResponse(b.GetName(), resp, out)
+}
+
+// GetPositions возвращает открытые позиции USDT-M счета
+func (b *Binance) GetPositions() ([]Position, error) {
+	credentials, err := resolveCredentials(b.GetName(), b.Credentials, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var risks []struct {
+		Symbol           string `json:"symbol"`
+		PositionAmt      string `json:"positionAmt"` // со знаком: меньше нуля — шорт
+		EntryPrice       string `json:"entryPrice"`
+		MarkPrice        string `json:"markPrice"`
+		UnRealizedProfit string `json:"unRealizedProfit"`
+		Leverage         string `json:"leverage"`
+		Notional         string `json:"notional"`
+	}
+	if err := b.signedGet(credentials, "https://fapi.binance.com/fapi/v2/positionRisk", url.Values{}, &risks); err != nil {
+		return nil, err
+	}
+
+	var result []Position
+	for _, risk := range risks {
+		amount := parseFloatFromString(risk.PositionAmt)
+		if amount == 0 {
+			continue
+		}
+
+		position := newPosition(b.GetName(), risk.Symbol, amount, parseFloatFromString(risk.Notional))
+		position.EntryPrice = parseFloatFromString(risk.EntryPrice)
+		position.MarkPrice = parseFloatFromString(risk.MarkPrice)
+		position.Leverage = parseFloatFromString(risk.Leverage)
+		position.UnrealizedPnL = parseFloatFromString(risk.UnRealizedProfit)
+		result = append(result, position)
+	}
+
+	log.Printf("Получено %d позиций с Binance", len(result))
 	return result, nil
 }
@@ -5,14 +5,18 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 )
 
-type BingX struct{}
+type BingX struct {
+	Credentials CredentialProvider // Ключи для приватных запросов, по умолчанию из переменных окружения BINGX_*
+}
 
 func NewBingX() *BingX {
 	log.Println("Инициализация BingX")
-	return &BingX{}
+	return &BingX{Credentials: EnvCredentials{Prefix: "BINGX"}}
 }
 
 func (b *BingX) GetName() string {
@@ -24,21 +28,21 @@ type bingXTickerResponse struct {
 	Code int `json:"code"`
 	Data []struct {
 		Symbol      string  `json:"symbol"`
-		Volume      float64 `json:"volume"`
-		QuoteVolume float64 `json:"quoteVolume"`
+		Volume      Decimal `json:"volume"`
+		QuoteVolume Decimal `json:"quoteVolume"`
 	} `json:"data"`
 }
 
 // Получаем объемы для всех пар
-func (b *BingX) getVolumes() (map[string]float64, map[string]float64, error) {
-	resp, err := http.Get("https://open-api.bingx.com/openApi/swap/v2/ticker/24hr")
+func (b *BingX) getVolumes() (map[string]Decimal, map[string]Decimal, error) {
+	resp, err := httpGet("https://open-api.bingx.com/openApi/swap/v2/ticker/24hr")
 	if err != nil {
 		return nil, nil, err
 	}
 	defer resp.Body.Close()
 
 	var tickerData bingXTickerResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tickerData); err != nil {
+	if err := decodeResponse(b.GetName(), resp, &tickerData); err != nil {
 		return nil, nil, err
 	}
 
@@ -46,8 +50,8 @@ func (b *BingX) getVolumes() (map[string]float64, map[string]float64, error) {
 		return nil, nil, fmt.Errorf("BingX API error: %d", tickerData.Code)
 	}
 
-	volumes := make(map[string]float64)
-	volumesUSDT := make(map[string]float64)
+	volumes := make(map[string]Decimal)
+	volumesUSDT := make(map[string]Decimal)
 
 	for _, item := range tickerData.Data {
 		volumes[item.Symbol] = item.Volume
@@ -65,12 +69,12 @@ func (b *BingX) GetFundingRates() ([]FundingRate, error) {
 	if err != nil {
 		log.Printf("Ошибка получения объемов BingX: %v", err)
 		// Продолжаем работу без объемов
-		volumes = make(map[string]float64)
-		volumesUSDT = make(map[string]float64)
+		volumes = make(map[string]Decimal)
+		volumesUSDT = make(map[string]Decimal)
 	}
 
 	// Получаем фандинг ставки
-	resp, err := http.Get("https://open-api.bingx.com/openApi/swap/v2/quote/fundingRate")
+	resp, err := httpGet("https://open-api.bingx.com/openApi/swap/v2/quote/fundingRate")
 	if err != nil {
 		log.Printf("Ошибка запроса к BingX: %v", err)
 		return nil, err
@@ -81,13 +85,13 @@ func (b *BingX) GetFundingRates() ([]FundingRate, error) {
 		Code int `json:"code"`
 		Data []struct {
 			Symbol          string  `json:"symbol"`
-			FundingRate     float64 `json:"fundingRate"`
+			FundingRate     Decimal `json:"fundingRate"`
 			FundingTime     int64   `json:"fundingTime"`
 			NextFundingTime int64   `json:"nextFundingTime"`
 		} `json:"data"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+	if err := decodeResponse(b.GetName(), resp, &response); err != nil {
 		log.Printf("Ошибка декодирования ответа BingX: %v", err)
 		return nil, err
 	}
@@ -98,11 +102,16 @@ func (b *BingX) GetFundingRates() ([]FundingRate, error) {
 
 	var result []FundingRate
 	for _, rate := range response.Data {
+		// Без времени выплаты поле остается пустым, чтобы проверка и статистика не получали выдуманное время
 		var nextFunding string
 		if rate.NextFundingTime > 0 {
 			nextFunding = time.Unix(rate.NextFundingTime/1000, 0).Format(time.RFC3339)
-		} else {
-			nextFunding = time.Now().Add(time.Hour).Format(time.RFC3339)
+		}
+
+		// Интервал — разница между предыдущей и следующей выплатами; 0, если одна из них неизвестна
+		var interval time.Duration
+		if rate.FundingTime > 0 && rate.NextFundingTime > rate.FundingTime {
+			interval = time.Duration(rate.NextFundingTime-rate.FundingTime) * time.Millisecond
 		}
 
 		// Получаем объемы для данного символа
@@ -110,14 +119,120 @@ func (b *BingX) GetFundingRates() ([]FundingRate, error) {
 		volumeUSDT24h := volumesUSDT[rate.Symbol]
 
 		result = append(result, FundingRate{
-			Symbol:        rate.Symbol,
-			Rate:          rate.FundingRate,
-			NextFunding:   nextFunding,
-			Volume24h:     volume24h,
-			VolumeUSDT24h: volumeUSDT24h,
+			Symbol:          rate.Symbol,
+			Rate:            rate.FundingRate.Float64(),
+			NextFunding:     nextFunding,
+			Volume24h:       volume24h.Float64(),
+			VolumeUSDT24h:   volumeUSDT24h.Float64(),
+			FundingInterval: interval,
+			Precise:         newPrecise(rate.FundingRate, Decimal{}, volume24h, volumeUSDT24h),
 		})
 	}
 
 	log.Printf("Получено %d ставок фандинга с BingX", len(result))
 	return result, nil
 }
+
+// GetFundingPayments возвращает начисления фандинга по счету бессрочных контрактов (доходы FUNDING_FEE)
+func (b *BingX) GetFundingPayments(query FundingPaymentsQuery) ([]FundingPayment, error) {
+	credentials, err := resolveCredentials(b.GetName(), b.Credentials, false)
+	if err != nil {
+		return nil, err
+	}
+
+	const pageSize = 1000
+	var result []FundingPayment
+	seen := make(map[string]bool)
+	startTime := query.From
+
+	for {
+		params := url.Values{}
+		params.Set("incomeType", "FUNDING_FEE")
+		params.Set("limit", strconv.Itoa(pageSize))
+		if query.Symbol != "" {
+			params.Set("symbol", query.Symbol)
+		}
+		if !startTime.IsZero() {
+			params.Set("startTime", strconv.FormatInt(startTime.UnixMilli(), 10))
+		}
+		if !query.To.IsZero() {
+			params.Set("endTime", strconv.FormatInt(query.To.UnixMilli(), 10))
+		}
+
+		var page []struct {
+			Symbol string `json:"symbol"`
+			Income string `json:"income"`
+			Asset  string `json:"asset"`
+			Time   int64  `json:"time"`
+			TranID string `json:"tranId"`
+		}
+		if err := b.signedGet(credentials, "https://open-api.bingx.com/openApi/swap/v2/user/income", params, &page); err != nil {
+			return nil, err
+		}
+
+		added := 0
+		for _, income := range page {
+			if seen[income.TranID] {
+				continue
+			}
+			seen[income.TranID] = true
+			added++
+
+			result = append(result, FundingPayment{
+				Exchange: b.GetName(),
+				Symbol:   income.Symbol,
+				Amount:   parseFloatFromString(income.Income),
+				Asset:    income.Asset,
+				Time:     time.UnixMilli(income.Time),
+				ID:       income.TranID,
+			})
+		}
+
+		if len(page) < pageSize || query.reachedLimit(len(result)) {
+			break
+		}
+		// Фандинг всех символов начисляется в одну миллисекунду, поэтому следующая страница
+		// начинается со времени последней записи, а повторы отсекаются по tranId. Если вся
+		// страница уже получена, записи этой миллисекунды исчерпаны для API — переходим к следующей
+		startTime = time.UnixMilli(page[len(page)-1].Time)
+		if added == 0 {
+			startTime = startTime.Add(time.Millisecond)
+		}
+	}
+
+	log.Printf("Получено %d начислений фандинга с BingX", len(result))
+	return query.truncate(result), nil
+}
+
+// signedGet выполняет подписанный GET-запрос и декодирует поле data в out
+func (b *BingX) signedGet(credentials Credentials, endpoint string, params url.Values, out interface{}) error {
+	params.Set("timestamp", strconv.FormatInt(time.Now().UnixMilli(), 10))
+	query := params.Encode()
+	query += "&signature=" + signHMACSHA256Hex(credentials.SecretKey, query)
+
+	req, err := http.NewRequest("GET", endpoint+"?"+query, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-BX-APIKEY", credentials.APIKey)
+
+	resp, err := httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var response struct {
+		Code int             `json:"code"`
+		Msg  string          `json:"msg"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := decodeResponse(b.GetName(), resp, &response); err != nil {
+		return fmt.Errorf("BingX API ошибка: HTTP %d, %v", resp.StatusCode, err)
+	}
+	if response.Code != 0 {
+		return fmt.Errorf("BingX API ошибка: %d - %s", response.Code, response.Msg)
+	}
+
+	return decodeChecked(b.GetName(), schemaEndpoint(resp.Request.URL.Path), "data", response.Data, out)
+}
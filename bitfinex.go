@@ -0,0 +1,197 @@
+package exchanges
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type Bitfinex struct{}
+
+func NewBitfinex() *Bitfinex {
+	log.Println("Инициализация Bitfinex")
+	return &Bitfinex{}
+}
+
+func (b *Bitfinex) GetName() string {
+	return "Bitfinex"
+}
+
+// Позиции полей в ответе /v2/status/deriv
+const (
+	bitfinexDerivKey             = 0
+	bitfinexDerivSpotPrice       = 4
+	bitfinexDerivNextFundingTime = 8
+	bitfinexDerivNextFunding     = 9
+	bitfinexDerivCurrentFunding  = 12
+	bitfinexDerivMarkPrice       = 15
+	bitfinexDerivOpenInterest    = 18
+)
+
+// Позиции полей торгового тикера в ответе /v2/tickers
+const (
+	bitfinexTickerSymbol    = 0
+	bitfinexTickerLastPrice = 7
+	bitfinexTickerVolume    = 8
+	bitfinexTickerFields    = 11
+)
+
+// bitfinexDecodeRows декодирует позиционные массивы Bitfinex, сохраняя числа как json.Number
+func bitfinexDecodeRows(resp *http.Response) ([][]interface{}, error) {
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+
+	var rows [][]interface{}
+	if err := decoder.Decode(&rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// bitfinexString возвращает строковое поле массива по индексу
+func bitfinexString(row []interface{}, index int) string {
+	if index >= len(row) {
+		return ""
+	}
+	s, _ := row[index].(string)
+	return s
+}
+
+// bitfinexDecimal возвращает числовое поле массива по индексу, null и отсутствующие поля дают 0
+func bitfinexDecimal(row []interface{}, index int) Decimal {
+	if index >= len(row) {
+		return Decimal{}
+	}
+	n, ok := row[index].(json.Number)
+	if !ok {
+		return Decimal{}
+	}
+	return parseDecimal(n.String())
+}
+
+// bitfinexFloat возвращает числовое поле массива по индексу как float64
+func bitfinexFloat(row []interface{}, index int) float64 {
+	return bitfinexDecimal(row, index).Float64()
+}
+
+// bitfinexSettleAsset определяет актив расчетов по символу вида tBTCF0:USTF0
+func bitfinexSettleAsset(symbol string) string {
+	i := strings.Index(symbol, ":")
+	if i < 0 {
+		return ""
+	}
+	switch strings.TrimSuffix(symbol[i+1:], "F0") {
+	case "UST":
+		return "USDT"
+	case "EUT":
+		return "EURT"
+	default:
+		return strings.TrimSuffix(symbol[i+1:], "F0")
+	}
+}
+
+// Получаем объемы для всех пар
+func (b *Bitfinex) getVolumes() (map[string]Decimal, map[string]Decimal, error) {
+	resp, err := httpGet("https://api-pub.bitfinex.com/v2/tickers?symbols=ALL")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("Bitfinex API вернул код ошибки %d", resp.StatusCode)
+	}
+
+	rows, err := bitfinexDecodeRows(resp)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	volumes := make(map[string]Decimal)
+	volumesUSDT := make(map[string]Decimal)
+
+	for _, row := range rows {
+		// Тикеры фандинга (fUSD и т.п.) имеют другую длину, пропускаем их
+		if len(row) != bitfinexTickerFields {
+			continue
+		}
+		symbol := bitfinexString(row, bitfinexTickerSymbol)
+		volume := bitfinexDecimal(row, bitfinexTickerVolume)
+		volumes[symbol] = volume
+		volumesUSDT[symbol] = derived(volume.Value.Mul(bitfinexDecimal(row, bitfinexTickerLastPrice).Value))
+	}
+
+	return volumes, volumesUSDT, nil
+}
+
+func (b *Bitfinex) GetFundingRates() ([]FundingRate, error) {
+	log.Println("Запрос ставок фандинга с Bitfinex")
+
+	// Получаем объемы
+	volumes, volumesUSDT, err := b.getVolumes()
+	if err != nil {
+		log.Printf("Ошибка получения объемов Bitfinex: %v", err)
+		// Продолжаем работу без объемов
+		volumes = make(map[string]Decimal)
+		volumesUSDT = make(map[string]Decimal)
+	}
+
+	resp, err := httpGet("https://api-pub.bitfinex.com/v2/status/deriv?keys=ALL")
+	if err != nil {
+		log.Printf("Ошибка запроса к Bitfinex: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Bitfinex API вернул код ошибки %d", resp.StatusCode)
+	}
+
+	rows, err := bitfinexDecodeRows(resp)
+	if err != nil {
+		log.Printf("Ошибка декодирования ответа Bitfinex: %v", err)
+		return nil, err
+	}
+
+	location := GetLocationFromEnv()
+	result := make([]FundingRate, 0)
+
+	for _, row := range rows {
+		symbol := bitfinexString(row, bitfinexDerivKey)
+		if symbol == "" || len(row) <= bitfinexDerivOpenInterest {
+			continue
+		}
+
+		nextFundingTime := int64(bitfinexFloat(row, bitfinexDerivNextFundingTime))
+		var nextFunding string
+		if nextFundingTime > 0 {
+			nextFunding = time.UnixMilli(nextFundingTime).In(location).Format(time.RFC3339)
+		} else {
+			nextFunding = time.Now().Truncate(8 * time.Hour).Add(8 * time.Hour).In(location).Format(time.RFC3339)
+		}
+
+		rate := bitfinexDecimal(row, bitfinexDerivCurrentFunding)
+		predicted := bitfinexDecimal(row, bitfinexDerivNextFunding)
+
+		result = append(result, FundingRate{
+			Symbol:          symbol,
+			Rate:            rate.Float64(),
+			NextFunding:     nextFunding,
+			Volume24h:       volumes[symbol].Float64(),
+			VolumeUSDT24h:   volumesUSDT[symbol].Float64(),
+			PredictedRate:   predicted.Float64(),
+			MarkPrice:       bitfinexFloat(row, bitfinexDerivMarkPrice),
+			IndexPrice:      bitfinexFloat(row, bitfinexDerivSpotPrice),
+			FundingInterval: 8 * time.Hour,
+			OpenInterest:    bitfinexFloat(row, bitfinexDerivOpenInterest),
+			SettleAsset:     bitfinexSettleAsset(symbol),
+			Precise:         newPrecise(rate, predicted, volumes[symbol], volumesUSDT[symbol]),
+		})
+	}
+
+	log.Printf("Получено %d ставок фандинга с Bitfinex", len(result))
+	return result, nil
+}
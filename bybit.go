@@ -5,15 +5,21 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
 	"time"
 )
 
-type Bybit struct{}
+type Bybit struct {
+	Credentials CredentialProvider // Ключи для приватных запросов, по умолчанию из переменных окружения BYBIT_*
+
+	IncludeInverse bool // Включать бессрочные контракты с маржой в монете (category=inverse)
+}
 
 func NewBybit() *Bybit {
 	log.Println("Инициализация Bybit")
-	return &Bybit{}
+	return &Bybit{Credentials: EnvCredentials{Prefix: "BYBIT"}}
 }
 
 func (b *Bybit) GetName() string {
@@ -22,7 +28,28 @@ func (b *Bybit) GetName() string {
 
 func (b *Bybit) GetFundingRates() ([]FundingRate, error) {
 	log.Println("Запрос ставок фандинга с Bybit")
-	resp, err := http.Get("https://api.bybit.com/v5/market/tickers?category=linear")
+
+	result, err := b.getRates("linear")
+	if err != nil {
+		return nil, err
+	}
+
+	if b.IncludeInverse {
+		inverse, err := b.getRates("inverse")
+		if err != nil {
+			log.Printf("Ошибка получения ставок инверсных контрактов Bybit: %v", err)
+		} else {
+			result = append(result, inverse...)
+		}
+	}
+
+	log.Printf("Получено %d ставок фандинга с Bybit", len(result))
+	return result, nil
+}
+
+// getRates получает ставки контрактов указанной категории (linear или inverse)
+func (b *Bybit) getRates(category string) ([]FundingRate, error) {
+	resp, err := httpGet("https://api.bybit.com/v5/market/tickers?category=" + category)
 	if err != nil {
 		log.Printf("Ошибка запроса к Bybit: %v", err)
 		return nil, err
@@ -34,6 +61,8 @@ func (b *Bybit) GetFundingRates() ([]FundingRate, error) {
 			List []struct {
 				Symbol        string `json:"symbol"`
 				FundingRate   string `json:"fundingRate"`
+				MarkPrice     string `json:"markPrice"`
+				IndexPrice    string `json:"indexPrice"`
 				NextFundingAt string `json:"nextFundingTime"`
 				Volume24h     string `json:"volume24h"`
 				Turnover24h   string `json:"turnover24h"`
@@ -41,18 +70,24 @@ func (b *Bybit) GetFundingRates() ([]FundingRate, error) {
 		} `json:"result"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+	if err := decodeResponse(b.GetName(), resp, &response); err != nil {
 		log.Printf("Ошибка декодирования ответа от Bybit: %v", err)
 		return nil, err
 	}
 
+	intervals, err := b.getFundingIntervals(category)
+	if err != nil {
+		log.Printf("Ошибка получения интервалов фандинга Bybit: %v", err)
+		intervals = make(map[string]time.Duration)
+	}
+
 	result := make([]FundingRate, 0)
 	for _, rate := range response.Result.List {
 		if rate.FundingRate == "" {
 			continue
 		}
-		fundingRate := 0.0
-		if _, err := fmt.Sscanf(rate.FundingRate, "%f", &fundingRate); err != nil {
+		fundingRate, err := ParseDecimal(rate.FundingRate)
+		if err != nil {
 			log.Printf("Ошибка конвертации ставки %s: %v", rate.FundingRate, err)
 			continue
 		}
@@ -64,19 +99,389 @@ func (b *Bybit) GetFundingRates() ([]FundingRate, error) {
 			nextFundingTime = time.Unix(nextFundingTimestamp/1000, 0).In(location).Format(time.RFC3339)
 		}
 
-		result = append(result, FundingRate{
-			Symbol:        rate.Symbol,
-			Rate:          fundingRate,
-			NextFunding:   nextFundingTime,
-			Volume24h:     parseFloatFromString(rate.Volume24h),
-			VolumeUSDT24h: parseFloatFromString(rate.Turnover24h),
+		volume := parseDecimal(rate.Volume24h)
+		volumeUSDT := parseDecimal(rate.Turnover24h)
+		marginType := MarginLinear
+
+		// У инверсных контрактов volume24h указан в USD (контракт = 1 USD), а turnover24h — в монете
+		if category == "inverse" {
+			volume, volumeUSDT = volumeUSDT, volume
+			marginType = MarginInverse
+		}
+
+		fr := FundingRate{
+			Symbol:          rate.Symbol,
+			Rate:            fundingRate.Float64(),
+			NextFunding:     nextFundingTime,
+			Volume24h:       volume.Float64(),
+			VolumeUSDT24h:   volumeUSDT.Float64(),
+			MarkPrice:       parseFloatFromString(rate.MarkPrice),
+			IndexPrice:      parseFloatFromString(rate.IndexPrice),
+			FundingInterval: intervals[rate.Symbol],
+			MarginType:      marginType,
+			Precise:         newPrecise(fundingRate, Decimal{}, volume, volumeUSDT),
+		}
+
+		result = append(result, fr)
+	}
+
+	return result, nil
+}
+
+// getFundingIntervals получает интервалы фандинга контрактов категории из описаний инструментов
+func (b *Bybit) getFundingIntervals(category string) (map[string]time.Duration, error) {
+	intervals := make(map[string]time.Duration)
+	cursor := ""
+
+	for {
+		params := url.Values{}
+		params.Set("category", category)
+		params.Set("limit", "1000")
+		if cursor != "" {
+			params.Set("cursor", cursor)
+		}
+
+		resp, err := httpGet("https://api.bybit.com/v5/market/instruments-info?" + params.Encode())
+		if err != nil {
+			return nil, err
+		}
+
+		var response struct {
+			RetCode int    `json:"retCode"`
+			RetMsg  string `json:"retMsg"`
+			Result  struct {
+				List []struct {
+					Symbol          string `json:"symbol"`
+					FundingInterval int64  `json:"fundingInterval"` // в минутах
+				} `json:"list"`
+				NextPageCursor string `json:"nextPageCursor"`
+			} `json:"result"`
+		}
+
+		err = decodeResponse(b.GetName(), resp, &response)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if response.RetCode != 0 {
+			return nil, fmt.Errorf("Bybit API ошибка: %d - %s", response.RetCode, response.RetMsg)
+		}
+
+		for _, instrument := range response.Result.List {
+			if instrument.FundingInterval > 0 {
+				intervals[instrument.Symbol] = time.Duration(instrument.FundingInterval) * time.Minute
+			}
+		}
+
+		if response.Result.NextPageCursor == "" || response.Result.NextPageCursor == cursor {
+			return intervals, nil
+		}
+		cursor = response.Result.NextPageCursor
+	}
+}
+
+// GetPremiumIndex возвращает минутные значения индекса премии USDT-контракта за период (до 1000 минут)
+func (b *Bybit) GetPremiumIndex(symbol string, from, to time.Time) ([]PremiumIndexPoint, error) {
+	params := url.Values{}
+	params.Set("category", "linear")
+	params.Set("symbol", symbol)
+	params.Set("interval", "1")
+	params.Set("start", strconv.FormatInt(from.UnixMilli(), 10))
+	params.Set("end", strconv.FormatInt(to.UnixMilli(), 10))
+	params.Set("limit", "1000")
+
+	resp, err := httpGet("https://api.bybit.com/v5/market/premium-index-price-kline?" + params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var response struct {
+		RetCode int    `json:"retCode"`
+		RetMsg  string `json:"retMsg"`
+		Result  struct {
+			List [][]string `json:"list"` // [время открытия, open, high, low, close], новые свечи первыми
+		} `json:"result"`
+	}
+	if err := decodeResponse(b.GetName(), resp, &response); err != nil {
+		return nil, err
+	}
+	if response.RetCode != 0 {
+		return nil, fmt.Errorf("Bybit API ошибка: %d - %s", response.RetCode, response.RetMsg)
+	}
+
+	points := make([]PremiumIndexPoint, 0, len(response.Result.List))
+	for i := len(response.Result.List) - 1; i >= 0; i-- {
+		kline := response.Result.List[i]
+		if len(kline) < 5 {
+			continue
+		}
+		openTime, err := strconv.ParseInt(kline[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		points = append(points, PremiumIndexPoint{
+			Time:    time.UnixMilli(openTime).UTC(),
+			Premium: parseFloatFromString(kline[4]),
 		})
 	}
+	return points, nil
+}
 
-	log.Printf("Получено %d ставок фандинга с Bybit", len(result))
+// GetSpotPrices возвращает последние цены спотовых пар Bybit по символам USDT-контрактов
+func (b *Bybit) GetSpotPrices() (map[string]float64, error) {
+	resp, err := httpGet("https://api.bybit.com/v5/market/tickers?category=spot")
+	if err != nil {
+		log.Printf("Ошибка запроса спотовых цен Bybit: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var response struct {
+		RetCode int    `json:"retCode"`
+		RetMsg  string `json:"retMsg"`
+		Result  struct {
+			List []struct {
+				Symbol    string `json:"symbol"`
+				LastPrice string `json:"lastPrice"`
+			} `json:"list"`
+		} `json:"result"`
+	}
+	if err := decodeResponse(b.GetName(), resp, &response); err != nil {
+		log.Printf("Ошибка декодирования спотовых цен Bybit: %v", err)
+		return nil, err
+	}
+	if response.RetCode != 0 {
+		return nil, fmt.Errorf("Bybit API ошибка: %d - %s", response.RetCode, response.RetMsg)
+	}
+
+	// Спотовая пара и USDT-контракт Bybit называются одинаково, например BTCUSDT
+	prices := make(map[string]float64, len(response.Result.List))
+	for _, ticker := range response.Result.List {
+		if price := parseFloatFromString(ticker.LastPrice); price > 0 {
+			prices[ticker.Symbol] = price
+		}
+	}
+	return prices, nil
+}
+
+// bybitMaxLogRange максимальный интервал одного запроса журнала транзакций
+const bybitMaxLogRange = 7 * 24 * time.Hour
+
+// bybitPaymentCategories категории контрактов, по которым начисляется фандинг
+var bybitPaymentCategories = []string{"linear", "inverse"}
+
+// GetFundingPayments возвращает начисления фандинга по единому торговому счету (записи SETTLEMENT
+// журнала транзакций) для USDT- и инверсных контрактов, от новых к старым. Без From биржа отдает
+// сутки до To, длинные периоды запрашиваются окнами по 7 дней
+func (b *Bybit) GetFundingPayments(query FundingPaymentsQuery) ([]FundingPayment, error) {
+	credentials, err := resolveCredentials(b.GetName(), b.Credentials, false)
+	if err != nil {
+		return nil, err
+	}
+
+	to := query.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+
+	var result []FundingPayment
+	for _, category := range bybitPaymentCategories {
+		payments, err := b.getFundingPaymentsCategory(credentials, query, category, to)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, payments...)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Time.After(result[j].Time)
+	})
+
+	log.Printf("Получено %d начислений фандинга с Bybit", len(result))
+	return query.truncate(result), nil
+}
+
+// getFundingPaymentsCategory читает журнал транзакций категории окнами не длиннее 7 дней
+func (b *Bybit) getFundingPaymentsCategory(credentials Credentials, query FundingPaymentsQuery, category string, to time.Time) ([]FundingPayment, error) {
+	var result []FundingPayment
+	from := query.From
+	for {
+		windowEnd := to
+		if !from.IsZero() && windowEnd.Sub(from) > bybitMaxLogRange {
+			windowEnd = from.Add(bybitMaxLogRange)
+		}
+
+		payments, err := b.getFundingPaymentsWindow(credentials, query, category, from, windowEnd)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, payments...)
+
+		if from.IsZero() || !windowEnd.Before(to) || query.reachedLimit(len(result)) {
+			return result, nil
+		}
+		from = windowEnd
+	}
+}
+
+// getFundingPaymentsWindow постранично читает журнал транзакций за один интервал.
+// Без from передается только endTime: биржа отдает сутки до него
+func (b *Bybit) getFundingPaymentsWindow(credentials Credentials, query FundingPaymentsQuery, category string, from, to time.Time) ([]FundingPayment, error) {
+	var result []FundingPayment
+	cursor := ""
+
+	for {
+		params := url.Values{}
+		params.Set("accountType", "UNIFIED")
+		params.Set("category", category)
+		params.Set("type", "SETTLEMENT")
+		params.Set("limit", "50")
+		if !from.IsZero() {
+			params.Set("startTime", strconv.FormatInt(from.UnixMilli(), 10))
+		}
+		params.Set("endTime", strconv.FormatInt(to.UnixMilli(), 10))
+		if cursor != "" {
+			params.Set("cursor", cursor)
+		}
+
+		var page struct {
+			List []struct {
+				ID              string `json:"id"`
+				Symbol          string `json:"symbol"`
+				Currency        string `json:"currency"`
+				TransactionTime string `json:"transactionTime"`
+				Funding         string `json:"funding"` // больше нуля — уплачено, меньше нуля — получено
+			} `json:"list"`
+			NextPageCursor string `json:"nextPageCursor"`
+		}
+		if err := b.signedGet(credentials, "https://api.bybit.com/v5/account/transaction-log", params, &page); err != nil {
+			return nil, err
+		}
+
+		for _, entry := range page.List {
+			// Журнал не фильтруется по символу на стороне биржи
+			if entry.Funding == "" || (query.Symbol != "" && entry.Symbol != query.Symbol) {
+				continue
+			}
+			transactionTime, _ := strconv.ParseInt(entry.TransactionTime, 10, 64)
+
+			result = append(result, FundingPayment{
+				Exchange: b.GetName(),
+				Symbol:   entry.Symbol,
+				Amount:   -parseFloatFromString(entry.Funding),
+				Asset:    entry.Currency,
+				Time:     time.UnixMilli(transactionTime),
+				ID:       entry.ID,
+			})
+		}
+
+		if page.NextPageCursor == "" || page.NextPageCursor == cursor || query.reachedLimit(len(result)) {
+			return result, nil
+		}
+		cursor = page.NextPageCursor
+	}
+}
+
+// GetPositions возвращает открытые позиции по USDT-контрактам единого торгового счета
+func (b *Bybit) GetPositions() ([]Position, error) {
+	credentials, err := resolveCredentials(b.GetName(), b.Credentials, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Position
+	cursor := ""
+
+	for {
+		params := url.Values{}
+		params.Set("category", "linear")
+		params.Set("settleCoin", "USDT")
+		params.Set("limit", "200")
+		if cursor != "" {
+			params.Set("cursor", cursor)
+		}
+
+		var page struct {
+			List []struct {
+				Symbol        string `json:"symbol"`
+				Side          string `json:"side"` // Buy, Sell или пусто для закрытой позиции
+				Size          string `json:"size"`
+				AvgPrice      string `json:"avgPrice"`
+				MarkPrice     string `json:"markPrice"`
+				PositionValue string `json:"positionValue"`
+				Leverage      string `json:"leverage"`
+				UnrealisedPnl string `json:"unrealisedPnl"`
+			} `json:"list"`
+			NextPageCursor string `json:"nextPageCursor"`
+		}
+		if err := b.signedGet(credentials, "https://api.bybit.com/v5/position/list", params, &page); err != nil {
+			return nil, err
+		}
+
+		for _, item := range page.List {
+			size := parseFloatFromString(item.Size)
+			if size == 0 {
+				continue
+			}
+			if item.Side == "Sell" {
+				size = -size
+			}
+
+			markPrice := parseFloatFromString(item.MarkPrice)
+			position := newPosition(b.GetName(), item.Symbol, size, size*markPrice)
+			position.EntryPrice = parseFloatFromString(item.AvgPrice)
+			position.MarkPrice = markPrice
+			position.Leverage = parseFloatFromString(item.Leverage)
+			position.UnrealizedPnL = parseFloatFromString(item.UnrealisedPnl)
+			result = append(result, position)
+		}
+
+		if page.NextPageCursor == "" || page.NextPageCursor == cursor {
+			break
+		}
+		cursor = page.NextPageCursor
+	}
+
+	log.Printf("Получено %d позиций с Bybit", len(result))
 	return result, nil
 }
 
+// signedGet выполняет подписанный GET-запрос к API v5 и декодирует поле result в out
+func (b *Bybit) signedGet(credentials Credentials, endpoint string, params url.Values, out interface{}) error {
+	const recvWindow = "5000"
+	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	query := params.Encode()
+
+	req, err := http.NewRequest("GET", endpoint+"?"+query, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-BAPI-API-KEY", credentials.APIKey)
+	req.Header.Set("X-BAPI-TIMESTAMP", timestamp)
+	req.Header.Set("X-BAPI-RECV-WINDOW", recvWindow)
+	req.Header.Set("X-BAPI-SIGN", signHMACSHA256Hex(credentials.SecretKey, timestamp+credentials.APIKey+recvWindow+query))
+
+	resp, err := httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var response struct {
+		RetCode int             `json:"retCode"`
+		RetMsg  string          `json:"retMsg"`
+		Result  json.RawMessage `json:"result"`
+	}
+	if err := decodeResponse(b.GetName(), resp, &response); err != nil {
+		return fmt.Errorf("Bybit API ошибка: HTTP %d, %v", resp.StatusCode, err)
+	}
+	if response.RetCode != 0 {
+		return fmt.Errorf("Bybit API ошибка: %d - %s", response.RetCode, response.RetMsg)
+	}
+
+	return decodeChecked(b.GetName(), schemaEndpoint(resp.Request.URL.Path), "result", response.Result, out)
+}
+
 // parseFloatFromString безопасно преобразует строку в float64
 func parseFloatFromString(s string) float64 {
 	if s == "" {
@@ -0,0 +1,521 @@
+// Команда crx выводит ставки фандинга поддерживаемых бирж.
+//
+//	crx rates   [-exchange binance,bybit] [-symbol BTC] [-min-volume 1e6] [-sort -rate] [-limit 20]
+//	crx top     [-n 10] — самые высокие и самые низкие ставки по всем биржам
+//	crx arb     [-n 10] — лучшие межбиржевые спреды ставок
+//	crx basis   [-n 10] [-horizon 720h] — базис спот/контракт и доходность связки лонг спот / шорт контракт
+//	crx history -db rates.db [-exchange binance,bybit] [-symbol BTCUSDT] [-since 24h]
+//	crx watch   [-interval 30s] — таблица ставок с периодическим обновлением
+//
+// Все команды поддерживают -format table|json|csv, -v для вывода логов бирж и -record dir
+// для записи ответов бирж в фикстуры exchangetest.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+	"github.com/petrixs/cr-exchanges/exchangetest"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "rates":
+		err = runRates(os.Args[2:])
+	case "top":
+		err = runTop(os.Args[2:])
+	case "arb":
+		err = runArb(os.Args[2:])
+	case "basis":
+		err = runBasis(os.Args[2:])
+	case "history":
+		err = runHistory(os.Args[2:])
+	case "watch":
+		err = runWatch(os.Args[2:])
+	case "exchanges":
+		fmt.Println(strings.Join(exchanges.ExchangeNames(), "\n"))
+	case "-h", "-help", "--help", "help":
+		usage()
+	default:
+		fmt.Fprintf(os.Stderr, "неизвестная команда %s\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ошибка:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `Использование: crx <команда> [флаги]
+
+Команды:
+  rates      ставки одной или всех бирж
+  top        самые высокие и самые низкие ставки
+  arb        лучшие межбиржевые спреды
+  basis      базис спот/контракт и доходность кэрри
+  history    история ставок из базы SQLite (записывается командой watch -db)
+  watch      таблица ставок с обновлением
+  exchanges  список поддерживаемых бирж
+
+Флаги команды: crx <команда> -h`)
+}
+
+// commonFlags флаги выбора бирж, фильтрации и вывода
+type commonFlags struct {
+	exchanges string
+	symbol    string
+	minVolume float64
+	format    string
+	verbose   bool
+	record    string
+}
+
+func (c *commonFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&c.exchanges, "exchange", "", "биржи через запятую (по умолчанию все): "+strings.Join(exchanges.ExchangeNames(), ","))
+	fs.StringVar(&c.symbol, "symbol", "", "актив, например BTC")
+	fs.Float64Var(&c.minVolume, "min-volume", 0, "минимальный объем за 24 часа в USDT")
+	fs.StringVar(&c.format, "format", "table", "формат вывода: table, json, csv")
+	fs.BoolVar(&c.verbose, "v", false, "выводить логи бирж")
+	fs.StringVar(&c.record, "record", "", "каталог, в который записываются запросы и ответы бирж в виде фикстур для тестов")
+}
+
+// setup проверяет флаги и отключает логи бирж, если не задан -v
+func (c *commonFlags) setup() error {
+	if !c.verbose {
+		log.SetOutput(io.Discard)
+	}
+	if c.record != "" {
+		transport, err := exchangetest.NewRecordTransport(c.record, nil)
+		if err != nil {
+			return err
+		}
+		exchanges.SetHTTPClient(&http.Client{Transport: transport})
+	}
+	switch c.format {
+	case "table", "json", "csv":
+		return nil
+	default:
+		return fmt.Errorf("неизвестный формат %s", c.format)
+	}
+}
+
+// selectedExchanges создает выбранные биржи
+func (c *commonFlags) selectedExchanges() ([]exchanges.Exchange, error) {
+	names := exchanges.ExchangeNames()
+	if c.exchanges != "" {
+		names = strings.Split(c.exchanges, ",")
+	}
+
+	var result []exchanges.Exchange
+	for _, name := range names {
+		exchange, err := exchanges.NewExchangeByName(name)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, exchange)
+	}
+	return result, nil
+}
+
+// history читает из хранилища записи выбранных бирж, прошедшие фильтры символа и объема
+func (c *commonFlags) history(store *exchanges.SQLiteStore, from time.Time) ([]row, error) {
+	query := exchanges.HistoryQuery{From: from}
+
+	// В базе хранятся имена бирж из GetName, а флаг принимает и короткие имена
+	var names map[string]bool
+	if c.exchanges != "" {
+		list, err := c.selectedExchanges()
+		if err != nil {
+			return nil, err
+		}
+		names = make(map[string]bool, len(list))
+		for _, exchange := range list {
+			names[exchange.GetName()] = true
+		}
+		if len(list) == 1 {
+			query.Exchange = list[0].GetName()
+		}
+	}
+
+	symbol := ""
+	if c.symbol != "" {
+		symbol = exchanges.CanonicalSymbol(c.symbol)
+	}
+
+	var rows []row
+	err := store.Iterate(query, func(record exchanges.HistoryRecord) error {
+		if names != nil && !names[record.Exchange] {
+			return nil
+		}
+		if record.VolumeUSDT24h < c.minVolume {
+			return nil
+		}
+		if symbol != "" && exchanges.CanonicalSymbol(record.Symbol) != symbol {
+			return nil
+		}
+		captured := record.CapturedAt
+		rows = append(rows, row{Exchange: record.Exchange, FundingRate: record.FundingRate, CapturedAt: &captured})
+		return nil
+	})
+	return rows, err
+}
+
+// fetch параллельно обновляет ставки выбранных бирж в кэше; ошибки бирж выводятся в stderr
+func (c *commonFlags) fetch(cache *exchanges.RatesCache) error {
+	list, err := c.selectedExchanges()
+	if err != nil {
+		return err
+	}
+	update(cache, list)
+	return nil
+}
+
+// update параллельно обновляет ставки бирж в кэше; ошибки бирж выводятся в stderr
+func update(cache *exchanges.RatesCache, list []exchanges.Exchange) {
+	var wg sync.WaitGroup
+	for _, exchange := range list {
+		wg.Add(1)
+		go func(exchange exchanges.Exchange) {
+			defer wg.Done()
+			if err := cache.UpdateRates(exchange); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", exchange.GetName(), err)
+			}
+		}(exchange)
+	}
+	wg.Wait()
+}
+
+// filter возвращает ставки, прошедшие фильтры символа и объема
+func (c *commonFlags) filter(all map[string][]exchanges.FundingRate) []row {
+	symbol := ""
+	if c.symbol != "" {
+		symbol = exchanges.CanonicalSymbol(c.symbol)
+	}
+
+	var rows []row
+	for exchange, rates := range all {
+		for _, rate := range rates {
+			if rate.VolumeUSDT24h < c.minVolume {
+				continue
+			}
+			if symbol != "" && exchanges.CanonicalSymbol(rate.Symbol) != symbol {
+				continue
+			}
+			rows = append(rows, row{Exchange: exchange, FundingRate: rate})
+		}
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Exchange != rows[j].Exchange {
+			return rows[i].Exchange < rows[j].Exchange
+		}
+		return rows[i].Symbol < rows[j].Symbol
+	})
+	return rows
+}
+
+func runRates(args []string) error {
+	fs := flag.NewFlagSet("rates", flag.ExitOnError)
+	var common commonFlags
+	common.register(fs)
+	sortKey := fs.String("sort", "", "сортировка: rate, -rate, abs_rate, volume, symbol")
+	limit := fs.Int("limit", 0, "максимум строк, 0 — без ограничения")
+	fs.Parse(args)
+
+	if err := common.setup(); err != nil {
+		return err
+	}
+
+	cache := &exchanges.RatesCache{Rates: make(map[string][]exchanges.FundingRate)}
+	if err := common.fetch(cache); err != nil {
+		return err
+	}
+
+	rows := common.filter(cache.GetAllRates())
+	if err := sortRows(rows, *sortKey); err != nil {
+		return err
+	}
+	if *limit > 0 && len(rows) > *limit {
+		rows = rows[:*limit]
+	}
+	return writeRows(os.Stdout, common.format, rows)
+}
+
+func runTop(args []string) error {
+	fs := flag.NewFlagSet("top", flag.ExitOnError)
+	var common commonFlags
+	common.register(fs)
+	n := fs.Int("n", 10, "число строк в каждой группе")
+	fs.Parse(args)
+
+	if err := common.setup(); err != nil {
+		return err
+	}
+
+	cache := &exchanges.RatesCache{Rates: make(map[string][]exchanges.FundingRate)}
+	if err := common.fetch(cache); err != nil {
+		return err
+	}
+
+	rows := common.filter(cache.GetAllRates())
+	sortRows(rows, "-rate")
+
+	highest := rows
+	if len(highest) > *n {
+		highest = highest[:*n]
+	}
+
+	lowest := make([]row, 0, *n)
+	for i := len(rows) - 1; i >= 0 && len(lowest) < *n; i-- {
+		lowest = append(lowest, rows[i])
+	}
+
+	if common.format != "table" {
+		for i := range highest {
+			highest[i].Group = "highest"
+		}
+		for i := range lowest {
+			lowest[i].Group = "lowest"
+		}
+		return writeRows(os.Stdout, common.format, append(append([]row{}, highest...), lowest...))
+	}
+
+	fmt.Println("Самые высокие ставки:")
+	if err := writeRows(os.Stdout, common.format, highest); err != nil {
+		return err
+	}
+	fmt.Println("\nСамые низкие ставки:")
+	return writeRows(os.Stdout, common.format, lowest)
+}
+
+func runArb(args []string) error {
+	fs := flag.NewFlagSet("arb", flag.ExitOnError)
+	var common commonFlags
+	common.register(fs)
+	n := fs.Int("n", 10, "число спредов")
+	fs.Parse(args)
+
+	if err := common.setup(); err != nil {
+		return err
+	}
+
+	cache := &exchanges.RatesCache{Rates: make(map[string][]exchanges.FundingRate)}
+	if err := common.fetch(cache); err != nil {
+		return err
+	}
+
+	spreads := exchanges.CrossExchangeSpreads(cache.GetAllRates(), common.minVolume)
+	if common.symbol != "" {
+		symbol := exchanges.CanonicalSymbol(common.symbol)
+		filtered := spreads[:0]
+		for _, spread := range spreads {
+			if spread.Symbol == symbol {
+				filtered = append(filtered, spread)
+			}
+		}
+		spreads = filtered
+	}
+	if len(spreads) > *n {
+		spreads = spreads[:*n]
+	}
+	return writeSpreads(os.Stdout, common.format, spreads)
+}
+
+func runBasis(args []string) error {
+	fs := flag.NewFlagSet("basis", flag.ExitOnError)
+	var common commonFlags
+	common.register(fs)
+	n := fs.Int("n", 10, "число строк")
+	horizon := fs.Duration("horizon", exchanges.DefaultBasisHorizon, "срок схождения базиса для пересчета в годовой")
+	fs.Parse(args)
+
+	if err := common.setup(); err != nil {
+		return err
+	}
+
+	cache := &exchanges.RatesCache{Rates: make(map[string][]exchanges.FundingRate)}
+	if err := common.fetch(cache); err != nil {
+		return err
+	}
+	list, err := common.selectedExchanges()
+	if err != nil {
+		return err
+	}
+
+	options := exchanges.BasisOptions{Horizon: *horizon, MinVolumeUSDT: common.minVolume}
+	symbol := ""
+	if common.symbol != "" {
+		symbol = exchanges.CanonicalSymbol(common.symbol)
+	}
+
+	var result []exchanges.Basis
+	for _, exchange := range list {
+		// Спотовые цены есть не у всех бирж
+		provider, ok := exchange.(exchanges.SpotPriceProvider)
+		if !ok {
+			continue
+		}
+		basis, err := exchanges.FetchBasis(provider, cache.GetRates(exchange.GetName()), options)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", exchange.GetName(), err)
+			continue
+		}
+		for _, b := range basis {
+			if symbol == "" || exchanges.CanonicalSymbol(b.Symbol) == symbol {
+				result = append(result, b)
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Carry > result[j].Carry })
+	if len(result) > *n {
+		result = result[:*n]
+	}
+	return writeBasis(os.Stdout, common.format, result)
+}
+
+func runHistory(args []string) error {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	var common commonFlags
+	common.register(fs)
+	dbPath := fs.String("db", "rates.db", "путь к базе SQLite")
+	since := fs.Duration("since", 24*time.Hour, "глубина истории")
+	limit := fs.Int("limit", 0, "максимум строк, 0 — без ограничения")
+	fs.Parse(args)
+
+	if err := common.setup(); err != nil {
+		return err
+	}
+
+	store, err := exchanges.NewSQLiteStore(*dbPath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	rows, err := common.history(store, time.Now().Add(-*since))
+	if err != nil {
+		return err
+	}
+
+	// Показываем последние записи
+	if *limit > 0 && len(rows) > *limit {
+		rows = rows[len(rows)-*limit:]
+	}
+	return writeRows(os.Stdout, common.format, rows)
+}
+
+func runWatch(args []string) error {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	var common commonFlags
+	common.register(fs)
+	sortKey := fs.String("sort", "-abs_rate", "сортировка: rate, -rate, abs_rate, volume, symbol")
+	limit := fs.Int("limit", 30, "максимум строк, 0 — без ограничения")
+	interval := fs.Duration("interval", 30*time.Second, "интервал обновления")
+	dbPath := fs.String("db", "", "база SQLite, в которую записывается история ставок для crx history")
+	fs.Parse(args)
+
+	if err := common.setup(); err != nil {
+		return err
+	}
+
+	// Экземпляры бирж переиспользуются между обновлениями: HTX вычисляет интервал по прошлым опросам
+	list, err := common.selectedExchanges()
+	if err != nil {
+		return err
+	}
+
+	cache, closeStore, err := newWatchCache(*dbPath)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	for {
+		update(cache, list)
+
+		rows := common.filter(cache.GetAllRates())
+		if err := sortRows(rows, *sortKey); err != nil {
+			return err
+		}
+		if *limit > 0 && len(rows) > *limit {
+			rows = rows[:*limit]
+		}
+
+		if common.format == "table" {
+			// Очищаем экран и возвращаем курсор в начало
+			fmt.Print("\033[H\033[2J")
+			fmt.Printf("Обновлено %s, следующее обновление через %s\n\n", time.Now().Format(time.TimeOnly), *interval)
+		}
+		if err := writeRows(os.Stdout, common.format, rows); err != nil {
+			return err
+		}
+
+		time.Sleep(*interval)
+	}
+}
+
+// newWatchCache создает кэш для watch; если задан путь к базе, каждое обновление записывается в историю
+func newWatchCache(dbPath string) (*exchanges.RatesCache, func(), error) {
+	cache := &exchanges.RatesCache{Rates: make(map[string][]exchanges.FundingRate)}
+	if dbPath == "" {
+		return cache, func() {}, nil
+	}
+
+	store, err := exchanges.NewSQLiteStore(dbPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	cache.SetStore(store)
+	return cache, func() { store.Close() }, nil
+}
+
+// sortRows сортирует строки по ключу; префикс "-" задает убывание.
+// Ставки сравниваются приведенными к 8 часам, т.к. интервалы фандинга бирж различаются
+func sortRows(rows []row, key string) error {
+	desc := strings.HasPrefix(key, "-")
+	key = strings.TrimPrefix(key, "-")
+
+	var less func(a, b row) bool
+	switch key {
+	case "":
+		return nil
+	case "rate":
+		less = func(a, b row) bool { return a.Rate8h() < b.Rate8h() }
+	case "abs_rate":
+		less = func(a, b row) bool { return math.Abs(a.Rate8h()) < math.Abs(b.Rate8h()) }
+	case "volume":
+		less = func(a, b row) bool { return a.VolumeUSDT24h < b.VolumeUSDT24h }
+	case "symbol":
+		less = func(a, b row) bool { return a.Symbol < b.Symbol }
+	default:
+		return fmt.Errorf("неизвестный ключ сортировки %s", key)
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if desc {
+			return less(rows[j], rows[i])
+		}
+		return less(rows[i], rows[j])
+	})
+	return nil
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+)
+
+func TestHistoryExchanges(t *testing.T) {
+	store, err := exchanges.NewSQLiteStore(filepath.Join(t.TempDir(), "rates.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	now := time.Now()
+	rates := []exchanges.FundingRate{
+		{Symbol: "BTCUSDT", Rate: 0.0001, VolumeUSDT24h: 5e6},
+		{Symbol: "ETHUSDT", Rate: 0.0002, VolumeUSDT24h: 1000},
+	}
+	for _, name := range []string{"Binance", "Bybit", "OKX"} {
+		if err := store.SaveRates(name, rates, now.Add(-time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		flags commonFlags
+		want  []string
+	}{
+		{name: "Все биржи", flags: commonFlags{}, want: []string{"Binance BTCUSDT", "Binance ETHUSDT", "Bybit BTCUSDT", "Bybit ETHUSDT", "OKX BTCUSDT", "OKX ETHUSDT"}},
+		{name: "Одна биржа", flags: commonFlags{exchanges: "bybit"}, want: []string{"Bybit BTCUSDT", "Bybit ETHUSDT"}},
+		{name: "Несколько бирж", flags: commonFlags{exchanges: "binance, okx", symbol: "BTC"}, want: []string{"Binance BTCUSDT", "OKX BTCUSDT"}},
+		{name: "Объем", flags: commonFlags{exchanges: "binance,bybit", minVolume: 1e6}, want: []string{"Binance BTCUSDT", "Bybit BTCUSDT"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := tt.flags.history(store, now.Add(-24*time.Hour))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]string, 0, len(rows))
+			for _, r := range rows {
+				if r.CapturedAt == nil {
+					t.Fatalf("нет времени снимка у %s %s", r.Exchange, r.Symbol)
+				}
+				got = append(got, r.Exchange+" "+r.Symbol)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("строки %v, ожидалось %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := (&commonFlags{exchanges: "binance,unknown"}).history(store, now.Add(-24*time.Hour)); err == nil {
+		t.Error("ожидалась ошибка для неизвестной биржи")
+	}
+}
+
+func TestSortRows(t *testing.T) {
+	rows := []row{
+		{Exchange: "A", FundingRate: exchanges.FundingRate{Symbol: "BTC", Rate: 0.0001, FundingInterval: 8 * time.Hour, VolumeUSDT24h: 10}},
+		// Часовая ставка 0.00005 за 8 часов — 0.0004
+		{Exchange: "B", FundingRate: exchanges.FundingRate{Symbol: "ETH", Rate: 0.00005, FundingInterval: time.Hour, VolumeUSDT24h: 30}},
+		{Exchange: "C", FundingRate: exchanges.FundingRate{Symbol: "SOL", Rate: -0.0003, FundingInterval: 8 * time.Hour, VolumeUSDT24h: 20}},
+	}
+
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "rate", want: "SOL,BTC,ETH"},
+		{key: "-rate", want: "ETH,BTC,SOL"},
+		{key: "abs_rate", want: "BTC,SOL,ETH"},
+		{key: "-volume", want: "ETH,SOL,BTC"},
+		{key: "symbol", want: "BTC,ETH,SOL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			sorted := append([]row(nil), rows...)
+			if err := sortRows(sorted, tt.key); err != nil {
+				t.Fatal(err)
+			}
+			symbols := make([]string, len(sorted))
+			for i, r := range sorted {
+				symbols[i] = r.Symbol
+			}
+			if got := strings.Join(symbols, ","); got != tt.want {
+				t.Errorf("порядок %s, ожидалось %s", got, tt.want)
+			}
+		})
+	}
+
+	if err := sortRows(rows, "apr"); err == nil {
+		t.Error("ожидалась ошибка для неизвестного ключа")
+	}
+}
+
+func TestWriteRowsCSV(t *testing.T) {
+	captured := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	rows := []row{{Exchange: "Binance", CapturedAt: &captured, FundingRate: exchanges.FundingRate{
+		Symbol: "BTCUSDT", Rate: 0.0001, FundingInterval: 4 * time.Hour, NextFunding: "2026-05-01T16:00:00Z", VolumeUSDT24h: 1e6,
+	}}}
+
+	var buf bytes.Buffer
+	if err := writeRows(&buf, "csv", rows); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0][0] != "captured_at" {
+		t.Fatalf("CSV %v", records)
+	}
+	want := []string{"2026-05-01T12:00:00Z", "Binance", "BTCUSDT", "0.0001", "0.0002", "0.219", "14400", "0", "2026-05-01T16:00:00Z", "0", "1000000"}
+	if strings.Join(records[1], ",") != strings.Join(want, ",") {
+		t.Errorf("строка %v, ожидалось %v", records[1], want)
+	}
+}
+
+// fixedExchange биржа с заданным списком ставок
+type fixedExchange struct {
+	name  string
+	rates []exchanges.FundingRate
+}
+
+func (e fixedExchange) GetName() string { return e.name }
+
+func (e fixedExchange) GetFundingRates() ([]exchanges.FundingRate, error) { return e.rates, nil }
+
+func TestWatchRecordsHistory(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "rates.db")
+	cache, closeStore, err := newWatchCache(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	update(cache, []exchanges.Exchange{fixedExchange{name: "Binance", rates: []exchanges.FundingRate{{Symbol: "BTCUSDT", Rate: 0.0001}}}})
+	closeStore()
+
+	// История, записанная watch -db, читается командой history
+	store, err := exchanges.NewSQLiteStore(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	rows, err := (&commonFlags{}).history(store, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].Exchange != "Binance" || rows[0].Symbol != "BTCUSDT" {
+		t.Errorf("строки истории %+v", rows)
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+)
+
+// row строка вывода: ставка биржи и, для истории, время снимка
+type row struct {
+	Exchange   string
+	Group      string // highest/lowest для команды top
+	CapturedAt *time.Time
+	exchanges.FundingRate
+}
+
+// jsonRow представление строки в формате JSON
+type jsonRow struct {
+	Group         string     `json:"group,omitempty"`
+	CapturedAt    *time.Time `json:"captured_at,omitempty"`
+	Exchange      string     `json:"exchange"`
+	Symbol        string     `json:"symbol"`
+	Rate          float64    `json:"rate"`
+	Rate8h        float64    `json:"rate_8h"`
+	APR           float64    `json:"apr"`
+	Interval      int64      `json:"funding_interval_seconds"`
+	PredictedRate float64    `json:"predicted_rate,omitempty"`
+	NextFunding   string     `json:"next_funding"`
+	Volume24h     float64    `json:"volume_24h"`
+	VolumeUSDT24h float64    `json:"volume_usdt_24h"`
+}
+
+func writeRows(w io.Writer, format string, rows []row) error {
+	withTime := len(rows) > 0 && rows[0].CapturedAt != nil
+
+	switch format {
+	case "json":
+		out := make([]jsonRow, 0, len(rows))
+		for _, r := range rows {
+			out = append(out, jsonRow{
+				Group:         r.Group,
+				CapturedAt:    r.CapturedAt,
+				Exchange:      r.Exchange,
+				Symbol:        r.Symbol,
+				Rate:          r.Rate,
+				Rate8h:        r.Rate8h(),
+				APR:           r.APR(),
+				Interval:      int64(r.Interval() / time.Second),
+				PredictedRate: r.PredictedRate,
+				NextFunding:   r.NextFunding,
+				Volume24h:     r.Volume24h,
+				VolumeUSDT24h: r.VolumeUSDT24h,
+			})
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(out)
+
+	case "csv":
+		cw := csv.NewWriter(w)
+		header := []string{"exchange", "symbol", "rate", "rate_8h", "apr", "funding_interval_seconds", "predicted_rate",
+			"next_funding", "volume_24h", "volume_usdt_24h"}
+		if withTime {
+			header = append([]string{"captured_at"}, header...)
+		}
+		if len(rows) > 0 && rows[0].Group != "" {
+			header = append([]string{"group"}, header...)
+		}
+		cw.Write(header)
+		for _, r := range rows {
+			record := []string{r.Exchange, r.Symbol, formatFloat(r.Rate), formatFloat(r.Rate8h()), formatFloat(r.APR()),
+				strconv.FormatInt(int64(r.Interval()/time.Second), 10), formatFloat(r.PredictedRate), r.NextFunding,
+				formatFloat(r.Volume24h), formatFloat(r.VolumeUSDT24h)}
+			if withTime {
+				record = append([]string{r.CapturedAt.UTC().Format(time.RFC3339)}, record...)
+			}
+			if r.Group != "" {
+				record = append([]string{r.Group}, record...)
+			}
+			cw.Write(record)
+		}
+		cw.Flush()
+		return cw.Error()
+
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+		if withTime {
+			fmt.Fprint(tw, "ВРЕМЯ\t")
+		}
+		fmt.Fprintln(tw, "БИРЖА\tСИМВОЛ\tСТАВКА %\tИНТЕРВАЛ\tЗА 8Ч %\tAPR %\tПРОГНОЗ %\tСЛЕД. ФАНДИНГ\tОБЪЕМ USDT\t")
+		for _, r := range rows {
+			if withTime {
+				fmt.Fprintf(tw, "%s\t", r.CapturedAt.Format("2006-01-02 15:04:05"))
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%.4f\t%s\t%.4f\t%.2f\t%s\t%s\t%.0f\t\n", r.Exchange, r.Symbol, r.Rate*100,
+				formatInterval(r.FundingInterval), r.Rate8h()*100, r.APR()*100, formatPercent(r.PredictedRate),
+				r.NextFunding, r.VolumeUSDT24h)
+		}
+		return tw.Flush()
+	}
+}
+
+func writeSpreads(w io.Writer, format string, spreads []exchanges.Spread) error {
+	switch format {
+	case "json":
+		type leg struct {
+			Exchange string  `json:"exchange"`
+			Symbol   string  `json:"symbol"`
+			Rate     float64 `json:"rate"`
+			Rate8h   float64 `json:"rate_8h"`
+		}
+		type spread struct {
+			Symbol string  `json:"symbol"`
+			Spread float64 `json:"spread_8h"`
+			APR    float64 `json:"apr"`
+			Long   leg     `json:"long"`
+			Short  leg     `json:"short"`
+		}
+		out := make([]spread, 0, len(spreads))
+		for _, s := range spreads {
+			out = append(out, spread{
+				Symbol: s.Symbol,
+				Spread: s.Spread,
+				APR:    s.APR(),
+				Long:   leg{s.Low.Exchange, s.Low.Symbol, s.Low.Rate, s.Low.Rate8h()},
+				Short:  leg{s.High.Exchange, s.High.Symbol, s.High.Rate, s.High.Rate8h()},
+			})
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(out)
+
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"symbol", "spread_8h", "apr", "long_exchange", "long_symbol", "long_rate", "short_exchange", "short_symbol", "short_rate"})
+		for _, s := range spreads {
+			cw.Write([]string{s.Symbol, formatFloat(s.Spread), formatFloat(s.APR()), s.Low.Exchange, s.Low.Symbol, formatFloat(s.Low.Rate),
+				s.High.Exchange, s.High.Symbol, formatFloat(s.High.Rate)})
+		}
+		cw.Flush()
+		return cw.Error()
+
+	default:
+		// Лонг на бирже с низкой ставкой, шорт на бирже с высокой; ставки приведены к 8 часам
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(tw, "АКТИВ\tСПРЕД 8Ч %\tAPR %\tЛОНГ\tЗА 8Ч %\tШОРТ\tЗА 8Ч %\t")
+		for _, s := range spreads {
+			fmt.Fprintf(tw, "%s\t%.4f\t%.2f\t%s %s\t%.4f\t%s %s\t%.4f\t\n", s.Symbol, s.Spread*100, s.APR()*100,
+				s.Low.Exchange, s.Low.Symbol, s.Low.Rate8h()*100, s.High.Exchange, s.High.Symbol, s.High.Rate8h()*100)
+		}
+		return tw.Flush()
+	}
+}
+
+func writeBasis(w io.Writer, format string, basis []exchanges.Basis) error {
+	switch format {
+	case "json":
+		type item struct {
+			Exchange        string  `json:"exchange"`
+			Symbol          string  `json:"symbol"`
+			SpotPrice       float64 `json:"spot_price"`
+			MarkPrice       float64 `json:"mark_price"`
+			Basis           float64 `json:"basis"`
+			AnnualizedBasis float64 `json:"annualized_basis"`
+			FundingAPR      float64 `json:"funding_apr"`
+			Carry           float64 `json:"carry"`
+		}
+		out := make([]item, 0, len(basis))
+		for _, b := range basis {
+			out = append(out, item(b))
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(out)
+
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"exchange", "symbol", "spot_price", "mark_price", "basis", "annualized_basis", "funding_apr", "carry"})
+		for _, b := range basis {
+			cw.Write([]string{b.Exchange, b.Symbol, formatFloat(b.SpotPrice), formatFloat(b.MarkPrice), formatFloat(b.Basis),
+				formatFloat(b.AnnualizedBasis), formatFloat(b.FundingAPR), formatFloat(b.Carry)})
+		}
+		cw.Flush()
+		return cw.Error()
+
+	default:
+		// Доходность связки лонг спот / шорт контракт
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(tw, "БИРЖА\tСИМВОЛ\tСПОТ\tМАРКИРОВКА\tБАЗИС %\tБАЗИС ГОД %\tФАНДИНГ APR %\tКЭРРИ %\t")
+		for _, b := range basis {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%.4f\t%.2f\t%.2f\t%.2f\t\n", b.Exchange, b.Symbol, formatFloat(b.SpotPrice),
+				formatFloat(b.MarkPrice), b.Basis*100, b.AnnualizedBasis*100, b.FundingAPR*100, b.Carry*100)
+		}
+		return tw.Flush()
+	}
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// formatInterval выводит интервал фандинга в часах, "-" — биржа его не сообщает
+func formatInterval(d time.Duration) string {
+	if d <= 0 {
+		return "-"
+	}
+	return strconv.FormatFloat(d.Hours(), 'f', -1, 64) + "ч"
+}
+
+func formatPercent(v float64) string {
+	if v == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.4f", v*100)
+}
@@ -0,0 +1,137 @@
+package exchanges
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type CoinbaseIntl struct{}
+
+func NewCoinbaseIntl() *CoinbaseIntl {
+	log.Println("Инициализация Coinbase International")
+	return &CoinbaseIntl{}
+}
+
+func (c *CoinbaseIntl) GetName() string {
+	return "CoinbaseIntl"
+}
+
+// Структура инструмента Coinbase International
+type coinbaseIntlInstrument struct {
+	Symbol          string `json:"symbol"`
+	Type            string `json:"type"`
+	QuoteAssetName  string `json:"quote_asset_name"`
+	TradingState    string `json:"trading_state"`
+	Qty24h          string `json:"qty_24hr"`
+	Notional24h     string `json:"notional_24hr"`
+	FundingInterval string `json:"funding_interval"` // в наносекундах
+	Quote           struct {
+		MarkPrice        string `json:"mark_price"`
+		IndexPrice       string `json:"index_price"`
+		PredictedFunding string `json:"predicted_funding"`
+	} `json:"quote"`
+}
+
+func (c *CoinbaseIntl) GetFundingRates() ([]FundingRate, error) {
+	log.Println("Запрос ставок фандинга с Coinbase International")
+
+	resp, err := httpGet("https://api.international.coinbase.com/api/v1/instruments")
+	if err != nil {
+		log.Printf("Ошибка запроса инструментов к Coinbase International: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Coinbase International API вернул код ошибки %d", resp.StatusCode)
+	}
+
+	var instruments []coinbaseIntlInstrument
+	if err := decodeResponse(c.GetName(), resp, &instruments); err != nil {
+		log.Printf("Ошибка декодирования инструментов от Coinbase International: %v", err)
+		return nil, err
+	}
+
+	location := GetLocationFromEnv()
+	result := make([]FundingRate, 0)
+
+	for _, instrument := range instruments {
+		if instrument.Type != "PERP" || instrument.TradingState != "TRADING" {
+			continue
+		}
+
+		// Фандинг на Coinbase International начисляется каждый час
+		interval := time.Hour
+		if ns, err := strconv.ParseInt(instrument.FundingInterval, 10, 64); err == nil && ns > 0 {
+			interval = time.Duration(ns)
+		}
+
+		predicted := parseDecimal(instrument.Quote.PredictedFunding)
+		volume := parseDecimal(instrument.Qty24h)
+		volumeUSDT := parseDecimal(instrument.Notional24h)
+
+		// Последняя ставка публикуется только в истории фандинга инструмента
+		rate, err := c.getLastFundingRate(instrument.Symbol)
+		if err != nil {
+			log.Printf("Ошибка получения ставки фандинга для %s с Coinbase International: %v", instrument.Symbol, err)
+			rate = predicted
+		}
+
+		nextFunding := time.Now().Truncate(interval).Add(interval)
+
+		result = append(result, FundingRate{
+			Symbol:          instrument.Symbol,
+			Rate:            rate.Float64(),
+			NextFunding:     nextFunding.In(location).Format(time.RFC3339),
+			Volume24h:       volume.Float64(),
+			VolumeUSDT24h:   volumeUSDT.Float64(),
+			PredictedRate:   predicted.Float64(),
+			MarkPrice:       parseFloatFromString(instrument.Quote.MarkPrice),
+			IndexPrice:      parseFloatFromString(instrument.Quote.IndexPrice),
+			FundingInterval: interval,
+			SettleAsset:     instrument.QuoteAssetName,
+			Precise:         newPrecise(rate, predicted, volume, volumeUSDT),
+		})
+
+		// Добавляем задержку между запросами
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	log.Printf("Получено %d ставок фандинга с Coinbase International", len(result))
+	return result, nil
+}
+
+// getLastFundingRate получает последнюю начисленную ставку фандинга инструмента
+func (c *CoinbaseIntl) getLastFundingRate(symbol string) (Decimal, error) {
+	url := fmt.Sprintf("https://api.international.coinbase.com/api/v1/instruments/%s/funding?result_limit=1", symbol)
+
+	resp, err := httpGet(url)
+	if err != nil {
+		return Decimal{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Decimal{}, fmt.Errorf("Coinbase International API вернул код ошибки %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Results []struct {
+			FundingRate string `json:"funding_rate"`
+			EventTime   string `json:"event_time"`
+		} `json:"results"`
+	}
+
+	if err := decodeResponse(c.GetName(), resp, &response); err != nil {
+		return Decimal{}, err
+	}
+
+	if len(response.Results) == 0 {
+		return Decimal{}, fmt.Errorf("нет данных о фандинг ставке для %s", symbol)
+	}
+
+	return ParseDecimal(response.Results[0].FundingRate)
+}
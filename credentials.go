@@ -0,0 +1,197 @@
+package exchanges
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Credentials API-ключи счета биржи. При выводе через fmt и логирование секреты скрываются
+type Credentials struct {
+	APIKey     string `json:"api_key"`
+	SecretKey  string `json:"secret_key"`
+	Passphrase string `json:"passphrase,omitempty"` // нужен OKX
+}
+
+func (c Credentials) String() string {
+	return fmt.Sprintf("Credentials{APIKey: %s, SecretKey: %s, Passphrase: %s}",
+		redactKey(c.APIKey), redactSecret(c.SecretKey), redactSecret(c.Passphrase))
+}
+
+// GoString скрывает секреты и при выводе через %#v
+func (c Credentials) GoString() string {
+	return c.String()
+}
+
+// redactKey оставляет начало идентификатора ключа, если он достаточно длинный, чтобы его узнать
+func redactKey(key string) string {
+	if key == "" {
+		return "<не задан>"
+	}
+	if len(key) < 12 {
+		return "***"
+	}
+	return key[:4] + "***"
+}
+
+// redactSecret полностью скрывает секрет
+func redactSecret(secret string) string {
+	if secret == "" {
+		return "<не задан>"
+	}
+	return "***"
+}
+
+// CredentialProvider источник API-ключей. Ключи запрашиваются перед каждым приватным запросом,
+// поэтому провайдер может поддерживать их ротацию
+type CredentialProvider interface {
+	Credentials() (Credentials, error)
+}
+
+// staticCredentials ключи, переданные в коде
+type staticCredentials struct {
+	credentials Credentials
+}
+
+// NewStaticCredentials создает провайдер с ключами в памяти
+func NewStaticCredentials(apiKey, secretKey, passphrase string) CredentialProvider {
+	return staticCredentials{Credentials{APIKey: apiKey, SecretKey: secretKey, Passphrase: passphrase}}
+}
+
+func (s staticCredentials) Credentials() (Credentials, error) {
+	return s.credentials, nil
+}
+
+func (s staticCredentials) String() string {
+	return s.credentials.String()
+}
+
+// GoString скрывает секреты при выводе через %#v: поле credentials не экспортировано,
+// и без этого метода fmt вывел бы его без Credentials.GoString
+func (s staticCredentials) GoString() string {
+	return s.String()
+}
+
+// EnvCredentials читает ключи из переменных окружения <Prefix>_API_KEY, <Prefix>_SECRET_KEY
+// и <Prefix>_PASSPHRASE, например OKX_API_KEY
+type EnvCredentials struct {
+	Prefix string
+}
+
+func (e EnvCredentials) Credentials() (Credentials, error) {
+	credentials := Credentials{
+		APIKey:     os.Getenv(e.Prefix + "_API_KEY"),
+		SecretKey:  os.Getenv(e.Prefix + "_SECRET_KEY"),
+		Passphrase: os.Getenv(e.Prefix + "_PASSPHRASE"),
+	}
+	if credentials.APIKey == "" || credentials.SecretKey == "" {
+		return credentials, fmt.Errorf("переменные окружения %s_API_KEY и %s_SECRET_KEY не заданы", e.Prefix, e.Prefix)
+	}
+	return credentials, nil
+}
+
+func (e EnvCredentials) String() string {
+	return "EnvCredentials(" + e.Prefix + "_*)"
+}
+
+func (e EnvCredentials) GoString() string {
+	return e.String()
+}
+
+// FileCredentials читает ключи счета Account из JSON-файла вида
+// {"okx-main": {"api_key": "...", "secret_key": "...", "passphrase": "..."}}.
+// Файл перечитывается при каждом запросе ключей
+type FileCredentials struct {
+	Path    string
+	Account string
+}
+
+func (f FileCredentials) Credentials() (Credentials, error) {
+	accounts, err := readCredentialsFile(f.Path)
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	credentials, ok := accounts[f.Account]
+	if !ok {
+		return Credentials{}, fmt.Errorf("счет %s не найден в %s", f.Account, f.Path)
+	}
+	return credentials, nil
+}
+
+func (f FileCredentials) String() string {
+	return "FileCredentials(" + f.Path + ", " + f.Account + ")"
+}
+
+func (f FileCredentials) GoString() string {
+	return f.String()
+}
+
+// readCredentialsFile читает все счета из файла ключей
+func readCredentialsFile(path string) (map[string]Credentials, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения файла ключей: %v", err)
+	}
+
+	var accounts map[string]Credentials
+	// Текст ошибки декодирования может содержать фрагмент файла, поэтому не выводим его
+	if err := json.Unmarshal(data, &accounts); err != nil {
+		return nil, fmt.Errorf("файл ключей %s не является корректным JSON", path)
+	}
+	return accounts, nil
+}
+
+// Accounts именованные счета, например "binance-main", "binance-hedge"
+type Accounts map[string]CredentialProvider
+
+// LoadAccountsFile создает счета для всех записей файла ключей (формат — см. FileCredentials)
+func LoadAccountsFile(path string) (Accounts, error) {
+	file, err := readCredentialsFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make(Accounts, len(file))
+	for name := range file {
+		accounts[name] = FileCredentials{Path: path, Account: name}
+	}
+	return accounts, nil
+}
+
+// Get возвращает провайдер ключей счета
+func (a Accounts) Get(name string) (CredentialProvider, error) {
+	provider, ok := a[name]
+	if !ok {
+		return nil, fmt.Errorf("неизвестный счет %s, доступны: %s", name, strings.Join(a.Names(), ", "))
+	}
+	return provider, nil
+}
+
+// Names возвращает отсортированные имена счетов
+func (a Accounts) Names() []string {
+	names := make([]string, 0, len(a))
+	for name := range a {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolveCredentials запрашивает ключи у провайдера биржи и проверяет, что они заполнены
+func resolveCredentials(exchange string, provider CredentialProvider, needPassphrase bool) (Credentials, error) {
+	if provider == nil {
+		return Credentials{}, errNoCredentials(exchange)
+	}
+
+	credentials, err := provider.Credentials()
+	if err != nil {
+		return Credentials{}, fmt.Errorf("%s: %v", exchange, err)
+	}
+	if credentials.APIKey == "" || credentials.SecretKey == "" || (needPassphrase && credentials.Passphrase == "") {
+		return Credentials{}, errNoCredentials(exchange)
+	}
+	return credentials, nil
+}
@@ -0,0 +1,169 @@
+package exchanges_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+)
+
+// roundTripFunc транспорт, отвечающий функцией
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestOKXDeprecatedCredentialFields(t *testing.T) {
+	var apiKey, passphrase string
+	exchanges.SetHTTPClient(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		apiKey = req.Header.Get("OK-ACCESS-KEY")
+		passphrase = req.Header.Get("OK-ACCESS-PASSPHRASE")
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"code":"0","msg":"","data":[]}`)),
+			Request:    req,
+		}, nil
+	})})
+	t.Cleanup(func() { exchanges.SetHTTPClient(nil) })
+
+	provider := exchanges.NewStaticCredentials("provider-key", "provider-secret", "provider-pass")
+	tests := []struct {
+		name    string
+		okx     *exchanges.OKX
+		wantKey string
+		wantErr bool
+	}{
+		{name: "Провайдер", okx: &exchanges.OKX{Credentials: provider}, wantKey: "provider-key"},
+		{name: "Устаревшие поля", okx: &exchanges.OKX{ApiKey: "legacy-key", SecretKey: "legacy-secret", Passphrase: "legacy-pass"}, wantKey: "legacy-key"},
+		{name: "Устаревшие поля важнее провайдера", okx: &exchanges.OKX{Credentials: provider, ApiKey: "legacy-key", SecretKey: "legacy-secret", Passphrase: "legacy-pass"}, wantKey: "legacy-key"},
+		{name: "Без пароля", okx: &exchanges.OKX{ApiKey: "legacy-key", SecretKey: "legacy-secret"}, wantErr: true},
+		{name: "Без ключей", okx: &exchanges.OKX{}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiKey, passphrase = "", ""
+			_, err := tt.okx.GetFundingPayments(exchanges.FundingPaymentsQuery{})
+			if tt.wantErr {
+				if err == nil || apiKey != "" {
+					t.Fatalf("ожидалась ошибка без запроса, ошибка %v, ключ %q", err, apiKey)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if apiKey != tt.wantKey || passphrase == "" {
+				t.Errorf("ключ %q, пароль %q, ожидался ключ %q", apiKey, passphrase, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestCredentialsRedacted(t *testing.T) {
+	const (
+		apiKey     = "AKIAEXAMPLEKEY123"
+		secretKey  = "supersecretvalue"
+		passphrase = "passphrase-value"
+	)
+	static := exchanges.NewStaticCredentials(apiKey, secretKey, passphrase)
+
+	values := map[string]interface{}{
+		"Credentials":          exchanges.Credentials{APIKey: apiKey, SecretKey: secretKey, Passphrase: passphrase},
+		"NewStaticCredentials": static,
+		"EnvCredentials":       exchanges.EnvCredentials{Prefix: "OKX"},
+		"FileCredentials":      exchanges.FileCredentials{Path: "keys.json", Account: "okx-main"},
+		"Accounts":             exchanges.Accounts{"okx-main": static},
+		"OKX":                  &exchanges.OKX{Credentials: static, ApiKey: apiKey, SecretKey: secretKey, Passphrase: passphrase},
+		"OKX по значению":      exchanges.OKX{ApiKey: apiKey, SecretKey: secretKey, Passphrase: passphrase},
+		"Binance":              &exchanges.Binance{Credentials: static},
+		"Bybit":                exchanges.Bybit{Credentials: static},
+	}
+
+	for name, value := range values {
+		for _, verb := range []string{"%v", "%+v", "%#v", "%s"} {
+			out := fmt.Sprintf(verb, value)
+			for _, secret := range []string{apiKey, secretKey, passphrase} {
+				if strings.Contains(out, secret) {
+					t.Errorf("%s %s: секрет в выводе %s", name, verb, out)
+				}
+			}
+		}
+	}
+}
+
+func TestEnvCredentials(t *testing.T) {
+	t.Setenv("CRXTEST_API_KEY", "key")
+	t.Setenv("CRXTEST_SECRET_KEY", "secret")
+	t.Setenv("CRXTEST_PASSPHRASE", "pass")
+
+	got, err := exchanges.EnvCredentials{Prefix: "CRXTEST"}.Credentials()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != (exchanges.Credentials{APIKey: "key", SecretKey: "secret", Passphrase: "pass"}) {
+		t.Errorf("ключи %#v", got)
+	}
+
+	t.Setenv("CRXTEST_SECRET_KEY", "")
+	if _, err := (exchanges.EnvCredentials{Prefix: "CRXTEST"}).Credentials(); err == nil {
+		t.Error("ожидалась ошибка без секретного ключа")
+	}
+}
+
+func TestFileCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	data := `{"okx-main": {"api_key": "okx-key", "secret_key": "okx-secret", "passphrase": "okx-pass"},
+		"binance-main": {"api_key": "binance-key", "secret_key": "binance-secret"}}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := exchanges.FileCredentials{Path: path, Account: "okx-main"}.Credentials()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != (exchanges.Credentials{APIKey: "okx-key", SecretKey: "okx-secret", Passphrase: "okx-pass"}) {
+		t.Errorf("ключи %#v", got)
+	}
+	if _, err := (exchanges.FileCredentials{Path: path, Account: "bybit-main"}).Credentials(); err == nil {
+		t.Error("ожидалась ошибка для неизвестного счета")
+	}
+
+	accounts, err := exchanges.LoadAccountsFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := strings.Join(accounts.Names(), ","); names != "binance-main,okx-main" {
+		t.Errorf("счета %s", names)
+	}
+	provider, err := accounts.Get("binance-main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := provider.Credentials(); err != nil || got.APIKey != "binance-key" {
+		t.Errorf("ключи %#v, ошибка %v", got, err)
+	}
+	if _, err := accounts.Get("bybit-main"); err == nil {
+		t.Error("ожидалась ошибка для неизвестного счета")
+	}
+
+	// Ошибка разбора не должна содержать фрагмент файла с секретами
+	if err := os.WriteFile(path, []byte(`{"okx-main": {"secret_key": "leaked-secret"`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = exchanges.FileCredentials{Path: path, Account: "okx-main"}.Credentials()
+	if err == nil || strings.Contains(err.Error(), "leaked-secret") {
+		t.Errorf("ошибка %v", err)
+	}
+	if _, err := (exchanges.FileCredentials{Path: filepath.Join(t.TempDir(), "missing.json"), Account: "okx-main"}).Credentials(); err == nil {
+		t.Error("ожидалась ошибка для отсутствующего файла")
+	}
+}
@@ -0,0 +1,122 @@
+package exchanges
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/shopspring/decimal"
+)
+
+// Decimal точное десятичное значение вместе с записью, в которой его прислала биржа.
+// Разбирается из JSON-числа и из строки; пустая строка и null дают ноль
+type Decimal struct {
+	Value decimal.Decimal
+	Raw   string // исходная запись, например "0.00010000"; пусто, если значение вычислено
+}
+
+// ParseDecimal разбирает десятичную строку биржи, сохраняя исходную запись
+func ParseDecimal(s string) (Decimal, error) {
+	raw := strings.TrimSpace(s)
+	value, err := decimal.NewFromString(raw)
+	if err != nil {
+		return Decimal{}, fmt.Errorf("ошибка разбора числа %q: %v", s, err)
+	}
+	return Decimal{Value: value, Raw: raw}, nil
+}
+
+// parseDecimal разбирает строку как ParseDecimal, пустая строка и ошибка дают ноль
+func parseDecimal(s string) Decimal {
+	d, _ := ParseDecimal(s)
+	return d
+}
+
+// derived возвращает вычисленное значение без исходной записи
+func derived(value decimal.Decimal) Decimal {
+	return Decimal{Value: value}
+}
+
+// Float64 возвращает ближайшее значение float64 для аналитики
+func (d Decimal) Float64() float64 {
+	return d.Value.InexactFloat64()
+}
+
+// IsZero сообщает, что значение равно нулю
+func (d Decimal) IsZero() bool {
+	return d.Value.IsZero()
+}
+
+// String возвращает исходную запись биржи, а для вычисленных значений — точную десятичную запись
+func (d Decimal) String() string {
+	if d.Raw != "" {
+		return d.Raw
+	}
+	return d.Value.String()
+}
+
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*d = Decimal{}
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		if strings.TrimSpace(s) == "" {
+			*d = Decimal{}
+			return nil
+		}
+		parsed, err := ParseDecimal(s)
+		if err != nil {
+			return err
+		}
+		*d = parsed
+		return nil
+	}
+	parsed, err := ParseDecimal(string(data))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// MarshalJSON записывает значение строкой, чтобы не терять точность и исходную запись
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// PreciseValues точные значения ставки и объемов строки FundingRate
+type PreciseValues struct {
+	Rate          Decimal
+	PredictedRate Decimal
+	Volume24h     Decimal
+	VolumeUSDT24h Decimal
+}
+
+// preciseDecimals включает заполнение FundingRate.Precise
+var preciseDecimals atomic.Bool
+
+// SetPreciseDecimals включает или отключает заполнение FundingRate.Precise во всех клиентах бирж.
+// По умолчанию отключено: точные значения нужны учету, аналитике достаточно float64
+func SetPreciseDecimals(enabled bool) {
+	preciseDecimals.Store(enabled)
+}
+
+// newPrecise возвращает точные значения строки или nil, если они отключены
+func newPrecise(rate, predicted, volume, volumeUSDT Decimal) *PreciseValues {
+	if !preciseDecimals.Load() {
+		return nil
+	}
+	return &PreciseValues{
+		Rate:          rate,
+		PredictedRate: predicted,
+		Volume24h:     volume,
+		VolumeUSDT24h: volumeUSDT,
+	}
+}
@@ -0,0 +1,137 @@
+package exchanges_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+	"github.com/petrixs/cr-exchanges/exchangetest"
+)
+
+func TestDecimalUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantRaw string
+		wantErr bool
+	}{
+		{name: "Строка с незначащими нулями", input: `"0.00010000"`, want: "0.0001", wantRaw: "0.00010000"},
+		{name: "Число", input: `-0.00025`, want: "-0.00025", wantRaw: "-0.00025"},
+		{name: "Экспонента", input: `1e-4`, want: "0.0001", wantRaw: "1e-4"},
+		{name: "Пустая строка", input: `""`, want: "0"},
+		{name: "null", input: `null`, want: "0"},
+		{name: "Не число", input: `"abc"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d exchanges.Decimal
+			err := json.Unmarshal([]byte(tt.input), &d)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ошибка %v, ожидалась: %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if d.Value.String() != tt.want || d.Raw != tt.wantRaw {
+				t.Errorf("значение %s, запись %q; ожидалось %s, %q", d.Value, d.Raw, tt.want, tt.wantRaw)
+			}
+		})
+	}
+}
+
+func TestPreciseDecimals(t *testing.T) {
+	replayFixtures(t, "bybit/ok", "", "")
+	exchanges.SetPreciseDecimals(true)
+	t.Cleanup(func() { exchanges.SetPreciseDecimals(false) })
+
+	rates, err := (&exchanges.Bybit{}).GetFundingRates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rates) == 0 {
+		t.Fatal("нет ставок")
+	}
+
+	for _, rate := range rates {
+		if rate.Precise == nil {
+			t.Fatalf("%s: нет точных значений", rate.Symbol)
+		}
+		if rate.Precise.Rate.Float64() != rate.Rate || rate.Precise.VolumeUSDT24h.Float64() != rate.VolumeUSDT24h {
+			t.Errorf("%s: точные значения %s, %s расходятся с %v, %v", rate.Symbol,
+				rate.Precise.Rate, rate.Precise.VolumeUSDT24h, rate.Rate, rate.VolumeUSDT24h)
+		}
+	}
+	// Исходная запись биржи сохраняется без изменений
+	if got := rates[0].Precise.VolumeUSDT24h.String(); got != "720030000.25" {
+		t.Errorf("запись объема %s, ожидалось 720030000.25", got)
+	}
+}
+
+func TestPreciseDecimalsPublishedValues(t *testing.T) {
+	exchanges.SetPreciseDecimals(true)
+	t.Cleanup(func() { exchanges.SetPreciseDecimals(false) })
+
+	tests := []struct {
+		name       string
+		fixtures   string
+		exchange   fundingRatesGetter
+		rate       string
+		predicted  string
+		volume     string // пусто — объем вычислен и не имеет исходной записи
+		volumeUSDT string
+	}{
+		// Ставка Paradex хранится за опубликованный период, без пересчета к часу
+		{name: "Paradex", fixtures: "paradex/ok", exchange: &exchanges.Paradex{}, rate: "0.0008", predicted: "0", volumeUSDT: "90000000"},
+		// Hyperliquid сообщает оборот в USD, объем в монете вычисляется по маркировочной цене
+		{name: "Hyperliquid", fixtures: "hyperliquid/ok", exchange: &exchanges.Hyperliquid{}, rate: "0.0000125", predicted: "0", volumeUSDT: "1500000000.5"},
+		{name: "Coinbase International", fixtures: "coinbaseintl/ok", exchange: &exchanges.CoinbaseIntl{}, rate: "0.00001", predicted: "0.000012", volume: "1500.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replayFixtures(t, tt.fixtures, "", "")
+			rates, err := tt.exchange.GetFundingRates()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rates) == 0 || rates[0].Precise == nil {
+				t.Fatalf("нет точных значений: %+v", rates)
+			}
+
+			rate, precise := rates[0], rates[0].Precise
+			if precise.Rate.String() != tt.rate || precise.PredictedRate.String() != tt.predicted {
+				t.Errorf("ставка %s, прогноз %s, ожидалось %s, %s", precise.Rate, precise.PredictedRate, tt.rate, tt.predicted)
+			}
+			if precise.Rate.Float64() != rate.Rate || precise.PredictedRate.Float64() != rate.PredictedRate ||
+				precise.Volume24h.Float64() != rate.Volume24h || precise.VolumeUSDT24h.Float64() != rate.VolumeUSDT24h {
+				t.Errorf("точные значения %+v расходятся со ставкой %+v", precise, rate)
+			}
+			if precise.Volume24h.Raw != tt.volume {
+				t.Errorf("запись объема %q, ожидалось %q", precise.Volume24h.Raw, tt.volume)
+			}
+			if tt.volumeUSDT != "" && precise.VolumeUSDT24h.Raw != tt.volumeUSDT {
+				t.Errorf("запись объема USDT %q, ожидалось %q", precise.VolumeUSDT24h.Raw, tt.volumeUSDT)
+			}
+		})
+	}
+}
+
+func TestPreciseDecimalsEstimatedPrediction(t *testing.T) {
+	server := newMockServer(t)
+	server.SetRate(exchangetest.VenueBinance, "BTC", exchangetest.MockRate{Rate: 0.0001, Volume: 1000, Price: 50000, Premium: 0.002})
+	exchanges.SetPreciseDecimals(true)
+	t.Cleanup(func() { exchanges.SetPreciseDecimals(false) })
+
+	rates, err := (&exchanges.Binance{PredictSymbols: []string{"BTCUSDT"}}).GetFundingRates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rates) != 1 || rates[0].Precise == nil {
+		t.Fatalf("ставки %+v", rates)
+	}
+	if got := rates[0].Precise.PredictedRate; got.Float64() != rates[0].PredictedRate || got.IsZero() {
+		t.Errorf("точный прогноз %s, прогноз %v", got, rates[0].PredictedRate)
+	}
+}
@@ -0,0 +1,171 @@
+package exchanges
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// RateEventType тип изменения в снимке ставок биржи
+type RateEventType string
+
+const (
+	EventListed         RateEventType = "listed"          // символ появился в снимке
+	EventDelisted       RateEventType = "delisted"        // символ пропал из снимка
+	EventRateChanged    RateEventType = "rate_changed"    // ставка изменилась больше порога
+	EventFundingSettled RateEventType = "funding_settled" // время следующего фандинга сдвинулось вперед
+	EventUpdated        RateEventType = "updated"         // снимок биржи обновлен, отправляется последним
+)
+
+// RateEvent изменение ставки, вычисленное при обновлении кэша.
+// Для EventListed заполнено только Current, для EventDelisted — только Previous,
+// для EventUpdated — только Exchange и Time
+type RateEvent struct {
+	Type     RateEventType
+	Exchange string
+	Symbol   string
+	Previous FundingRate
+	Current  FundingRate
+	Time     time.Time
+}
+
+// DiffRates сравнивает два снимка ставок биржи и возвращает события об изменениях.
+// Изменение ставки сообщается, если модуль разницы больше threshold
+func DiffRates(exchange string, previous, current []FundingRate, threshold float64, at time.Time) []RateEvent {
+	prev := make(map[string]FundingRate, len(previous))
+	for _, rate := range previous {
+		prev[rate.Symbol] = rate
+	}
+
+	var events []RateEvent
+	seen := make(map[string]bool, len(current))
+
+	for _, curr := range current {
+		seen[curr.Symbol] = true
+
+		old, ok := prev[curr.Symbol]
+		if !ok {
+			events = append(events, RateEvent{Type: EventListed, Exchange: exchange, Symbol: curr.Symbol, Current: curr, Time: at})
+			continue
+		}
+
+		if fundingSettled(old, curr, at) {
+			events = append(events, RateEvent{Type: EventFundingSettled, Exchange: exchange, Symbol: curr.Symbol, Previous: old, Current: curr, Time: at})
+		}
+
+		if math.Abs(curr.Rate-old.Rate) > threshold {
+			events = append(events, RateEvent{Type: EventRateChanged, Exchange: exchange, Symbol: curr.Symbol, Previous: old, Current: curr, Time: at})
+		}
+	}
+
+	for _, old := range previous {
+		if !seen[old.Symbol] {
+			events = append(events, RateEvent{Type: EventDelisted, Exchange: exchange, Symbol: old.Symbol, Previous: old, Time: at})
+		}
+	}
+
+	return events
+}
+
+// fundingSettled проверяет, что прошлое время фандинга наступило, а новое сдвинулось вперед.
+// Биржи, которые вычисляют время фандинга от текущего момента, не дают ложных событий
+func fundingSettled(previous, current FundingRate, at time.Time) bool {
+	prevTime, err := time.Parse(time.RFC3339, previous.NextFunding)
+	if err != nil {
+		return false
+	}
+	currTime, err := time.Parse(time.RFC3339, current.NextFunding)
+	if err != nil {
+		return false
+	}
+	return currTime.After(prevTime) && !prevTime.After(at)
+}
+
+// Subscription подписка на события изменения ставок с ограниченным буфером
+type Subscription struct {
+	C <-chan RateEvent
+
+	ch      chan RateEvent
+	cache   *RatesCache
+	dropped atomic.Uint64
+}
+
+// Dropped возвращает число событий, отброшенных из-за переполнения буфера
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close отменяет подписку и закрывает канал событий
+func (s *Subscription) Close() {
+	s.cache.subsMu.Lock()
+	defer s.cache.subsMu.Unlock()
+
+	if _, ok := s.cache.subs[s]; ok {
+		delete(s.cache.subs, s)
+		close(s.ch)
+	}
+}
+
+// Subscribe регистрирует подписчика на события изменения ставок. Если подписчик не успевает
+// читать канал и буфер размера buffer заполнен, новые события отбрасываются, а EventUpdated
+// вытесняет самое старое. Число потерянных событий возвращает Subscription.Dropped
+func (c *RatesCache) Subscribe(buffer int) *Subscription {
+	if buffer < 1 {
+		buffer = 1
+	}
+
+	ch := make(chan RateEvent, buffer)
+	sub := &Subscription{C: ch, ch: ch, cache: c}
+
+	c.subsMu.Lock()
+	if c.subs == nil {
+		c.subs = make(map[*Subscription]struct{})
+	}
+	c.subs[sub] = struct{}{}
+	c.subsMu.Unlock()
+
+	return sub
+}
+
+// SetRateChangeThreshold задает минимальное изменение ставки для события EventRateChanged
+func (c *RatesCache) SetRateChangeThreshold(threshold float64) {
+	c.Mu.Lock()
+	c.rateChangeThreshold = threshold
+	c.Mu.Unlock()
+}
+
+// hasSubscribers сообщает, есть ли зарегистрированные подписчики
+func (c *RatesCache) hasSubscribers() bool {
+	c.subsMu.Lock()
+	defer c.subsMu.Unlock()
+	return len(c.subs) > 0
+}
+
+// publish рассылает события всем подписчикам без блокировки. EventUpdated не теряется:
+// при полном буфере он вытесняет самое старое событие
+func (c *RatesCache) publish(events []RateEvent) {
+	c.subsMu.Lock()
+	defer c.subsMu.Unlock()
+
+	for sub := range c.subs {
+		for _, event := range events {
+			select {
+			case sub.ch <- event:
+				continue
+			default:
+			}
+
+			if event.Type != EventUpdated {
+				sub.dropped.Add(1)
+				continue
+			}
+			select {
+			case <-sub.ch:
+				sub.dropped.Add(1)
+			default:
+			}
+			// Пишет в канал только publish под subsMu, поэтому место в буфере есть
+			sub.ch <- event
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package exchanges_test
+
+import (
+	"testing"
+	"time"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+	"github.com/petrixs/cr-exchanges/exchangetest"
+)
+
+// eventKinds возвращает типы и символы событий в порядке получения
+func eventKinds(events []exchanges.RateEvent) []string {
+	kinds := make([]string, 0, len(events))
+	for _, event := range events {
+		kinds = append(kinds, string(event.Type)+" "+event.Symbol)
+	}
+	return kinds
+}
+
+func sameKinds(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDiffRates(t *testing.T) {
+	at := time.Date(2026, 5, 1, 8, 0, 30, 0, time.UTC)
+	const (
+		passed = "2026-05-01T08:00:00Z"
+		next   = "2026-05-01T16:00:00Z"
+	)
+
+	tests := []struct {
+		name     string
+		previous []exchanges.FundingRate
+		current  []exchanges.FundingRate
+		want     []string
+	}{
+		{
+			name:    "Листинг",
+			current: []exchanges.FundingRate{{Symbol: "BTCUSDT", Rate: 0.0001, NextFunding: next}},
+			want:    []string{"listed BTCUSDT"},
+		},
+		{
+			name:     "Делистинг",
+			previous: []exchanges.FundingRate{{Symbol: "BTCUSDT", Rate: 0.0001, NextFunding: next}},
+			want:     []string{"delisted BTCUSDT"},
+		},
+		{
+			name:     "Изменение в пределах порога",
+			previous: []exchanges.FundingRate{{Symbol: "BTCUSDT", Rate: 0.0001, NextFunding: next}},
+			current:  []exchanges.FundingRate{{Symbol: "BTCUSDT", Rate: 0.00015, NextFunding: next}},
+		},
+		{
+			name:     "Изменение больше порога",
+			previous: []exchanges.FundingRate{{Symbol: "BTCUSDT", Rate: 0.0001, NextFunding: next}},
+			current:  []exchanges.FundingRate{{Symbol: "BTCUSDT", Rate: 0.0003, NextFunding: next}},
+			want:     []string{"rate_changed BTCUSDT"},
+		},
+		{
+			name:     "Выплата фандинга",
+			previous: []exchanges.FundingRate{{Symbol: "BTCUSDT", Rate: 0.0001, NextFunding: passed}},
+			current:  []exchanges.FundingRate{{Symbol: "BTCUSDT", Rate: 0.0001, NextFunding: next}},
+			want:     []string{"funding_settled BTCUSDT"},
+		},
+		{
+			// Биржа вычисляет время выплаты от текущего момента: прошлое время еще не наступило
+			name:     "Сдвиг времени до выплаты",
+			previous: []exchanges.FundingRate{{Symbol: "BTCUSDT", Rate: 0.0001, NextFunding: "2026-05-01T08:01:00Z"}},
+			current:  []exchanges.FundingRate{{Symbol: "BTCUSDT", Rate: 0.0001, NextFunding: next}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := exchanges.DiffRates("Binance", tt.previous, tt.current, 0.0001, at)
+			if got := eventKinds(events); !sameKinds(got, tt.want) {
+				t.Fatalf("события %v, ожидалось %v", got, tt.want)
+			}
+			for _, event := range events {
+				if event.Exchange != "Binance" || !event.Time.Equal(at) {
+					t.Errorf("событие %+v", event)
+				}
+			}
+		})
+	}
+}
+
+// readEvents читает события до EventUpdated
+func readEvents(t *testing.T, sub *exchanges.Subscription) []string {
+	t.Helper()
+
+	var events []exchanges.RateEvent
+	for {
+		select {
+		case event := <-sub.C:
+			events = append(events, event)
+			if event.Type == exchanges.EventUpdated {
+				return eventKinds(events)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("нет события updated, получено %v", eventKinds(events))
+		}
+	}
+}
+
+func TestSubscribeUpdateRates(t *testing.T) {
+	server := newMockServer(t)
+	server.SetRate(exchangetest.VenueBybit, "BTC", exchangetest.MockRate{Rate: 0.0001, Volume: 1000, Price: 50000})
+
+	cache := newTestCache()
+	cache.SetRateChangeThreshold(0.0001)
+	sub := cache.Subscribe(16)
+	defer sub.Close()
+
+	if err := cache.UpdateRates(&exchanges.Bybit{}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := readEvents(t, sub), []string{"listed BTCUSDT", "updated "}; !sameKinds(got, want) {
+		t.Errorf("первое обновление: %v, ожидалось %v", got, want)
+	}
+
+	server.SetRate(exchangetest.VenueBybit, "BTC", exchangetest.MockRate{Rate: 0.0005, Volume: 1000, Price: 50000})
+	server.SetRate(exchangetest.VenueBybit, "ETH", exchangetest.MockRate{Rate: 0.0001, Volume: 1000, Price: 3000})
+	if err := cache.UpdateRates(&exchanges.Bybit{}); err != nil {
+		t.Fatal(err)
+	}
+	got := readEvents(t, sub)
+	if len(got) != 3 || got[2] != "updated " || !containsKind(got, "rate_changed BTCUSDT") || !containsKind(got, "listed ETHUSDT") {
+		t.Errorf("второе обновление: %v", got)
+	}
+	if sub.Dropped() != 0 {
+		t.Errorf("отброшено %d событий", sub.Dropped())
+	}
+}
+
+func containsKind(kinds []string, kind string) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSubscribeOverflowKeepsUpdated(t *testing.T) {
+	cache := newTestCache()
+	sub := cache.Subscribe(1)
+	defer sub.Close()
+
+	next := time.Now().Add(time.Hour).Format(time.RFC3339)
+	exchange := &staticExchange{name: "Binance", rates: []exchanges.FundingRate{
+		{Symbol: "BTCUSDT", Rate: 0.0001, NextFunding: next},
+		{Symbol: "ETHUSDT", Rate: 0.0002, NextFunding: next},
+	}}
+	// Подписчик не читает канал: листинги не помещаются в буфер, но updated доходит
+	if err := cache.UpdateRates(exchange); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case event := <-sub.C:
+		if event.Type != exchanges.EventUpdated {
+			t.Errorf("в буфере %s, ожидалось updated", event.Type)
+		}
+	default:
+		t.Fatal("буфер пуст")
+	}
+	if sub.Dropped() != 2 {
+		t.Errorf("отброшено %d событий, ожидалось 2", sub.Dropped())
+	}
+}
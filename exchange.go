@@ -12,12 +12,42 @@ type Exchange interface {
 	GetFundingRates() ([]FundingRate, error)
 }
 
+// MarginType описывает тип маржи контракта
+type MarginType string
+
+const (
+	MarginLinear  MarginType = "linear"  // маржа и расчеты в стейблкоине, объем контракта в базовой валюте
+	MarginInverse MarginType = "inverse" // маржа и расчеты в базовой монете, номинал контракта в USD
+)
+
 type FundingRate struct {
-	Symbol        string
-	Rate          float64
-	NextFunding   string
-	Volume24h     float64 // Объем за 24 часа в базовой валюте
-	VolumeUSDT24h float64 // Объем за 24 часа в USDT
+	Symbol          string
+	Rate            float64
+	NextFunding     string
+	Volume24h       float64       // Объем за 24 часа в базовой валюте
+	VolumeUSDT24h   float64       // Объем за 24 часа в USDT
+	PredictedRate   float64       // Прогнозная ставка следующего фандинга (0, если биржа не публикует)
+	MarkPrice       float64       // Маркировочная цена
+	IndexPrice      float64       // Индексная цена
+	FundingInterval time.Duration // Интервал между выплатами фандинга (0, если неизвестен)
+	OpenInterest    float64       // Открытый интерес в базовой валюте
+	SettleAsset     string        // Актив расчетов (USDT, USDC, ...), пусто — биржа не сообщает
+	MarginType      MarginType    // Тип маржи (linear/inverse), пусто — биржа не сообщает
+
+	Issues  []ValidationCheck // Проблемы, отмеченные проверкой качества (ActionFlag)
+	Precise *PreciseValues    // Точные значения ставки и объемов, nil — если не включены SetPreciseDecimals
+}
+
+// ExchangeStatus состояние обновлений биржи в кэше
+type ExchangeStatus struct {
+	Name          string
+	LastUpdate    time.Time     // время последнего успешного обновления
+	LastDuration  time.Duration // длительность последнего запроса ставок
+	Rows          int           // число ставок в последнем снимке
+	Updates       uint64        // число успешных обновлений
+	Errors        uint64        // число неудачных обновлений
+	LastError     string        // текст последней ошибки
+	LastErrorTime time.Time
 }
 
 // RatesCache хранит кэшированные ставки фандинга
@@ -25,6 +55,18 @@ type RatesCache struct {
 	Rates      map[string][]FundingRate // ключ - имя биржи
 	Mu         sync.RWMutex
 	LastUpdate time.Time
+
+	version uint64                    // увеличивается при каждом изменении ставок
+	status  map[string]ExchangeStatus // ключ - имя биржи
+
+	store               RateStore // хранилище истории, nil — история не сохраняется
+	rateChangeThreshold float64   // порог изменения ставки для событий
+
+	validation        *ValidationRules            // nil — DefaultValidationRules
+	validationReports map[string]ValidationReport // ключ - имя биржи
+
+	subsMu sync.Mutex
+	subs   map[*Subscription]struct{}
 }
 
 var (
@@ -33,21 +75,90 @@ var (
 	}
 )
 
-// UpdateRates обновляет ставки в кэше для указанной биржи
+// UpdateRates обновляет ставки в кэше для указанной биржи. Снимок проверяется правилами
+// SetValidationRules, по умолчанию DefaultValidationRules: строки с NaN и повторы символов удаляются
 func (c *RatesCache) UpdateRates(exchange Exchange) error {
+	name := exchange.GetName()
+	started := time.Now()
 	rates, err := exchange.GetFundingRates()
+	duration := time.Since(started)
+	if err == nil {
+		c.Mu.RLock()
+		rules := c.validationRules()
+		c.Mu.RUnlock()
+
+		var report ValidationReport
+		rates, report, err = ValidateRates(name, rates, rules, time.Now())
+		c.Mu.Lock()
+		c.saveValidationReport(report)
+		c.Mu.Unlock()
+		if report.Dropped > 0 || report.Rejected {
+			log.Printf("Проверка ставок %s: удалено %d из %d строк (%s)", name, report.Dropped, report.Rows, report.issueSummary())
+		}
+	}
 	if err != nil {
+		c.Mu.Lock()
+		status := c.exchangeStatus(name)
+		status.LastDuration = duration
+		status.Errors++
+		status.LastError = err.Error()
+		status.LastErrorTime = time.Now()
+		c.status[name] = status
+		c.Mu.Unlock()
 		return err
 	}
 
+	now := time.Now()
+
 	c.Mu.Lock()
-	c.Rates[exchange.GetName()] = rates
-	c.LastUpdate = time.Now()
+	previous := c.Rates[name]
+	c.Rates[name] = rates
+	c.LastUpdate = now
+	c.version++
+	status := c.exchangeStatus(name)
+	status.LastUpdate = now
+	status.LastDuration = duration
+	status.Rows = len(rates)
+	status.Updates++
+	c.status[name] = status
+	store := c.store
+	threshold := c.rateChangeThreshold
 	c.Mu.Unlock()
 
+	if c.hasSubscribers() {
+		events := DiffRates(name, previous, rates, threshold, now)
+		events = append(events, RateEvent{Type: EventUpdated, Exchange: name, Time: now})
+		c.publish(events)
+	}
+
+	if store != nil {
+		if err := store.SaveRates(name, rates, now); err != nil {
+			log.Printf("Ошибка сохранения истории ставок %s: %v", name, err)
+		}
+	}
+
 	return nil
 }
 
+// exchangeStatus возвращает текущее состояние биржи, вызывается под c.Mu
+func (c *RatesCache) exchangeStatus(name string) ExchangeStatus {
+	if c.status == nil {
+		c.status = make(map[string]ExchangeStatus)
+	}
+	status, ok := c.status[name]
+	if !ok {
+		status.Name = name
+	}
+	return status
+}
+
+// SetStore задает хранилище, в которое добавляется каждый полученный снимок ставок
+func (c *RatesCache) SetStore(store RateStore) {
+	c.Mu.Lock()
+	c.store = store
+	c.Mu.Unlock()
+}
+
 // GetRates возвращает кэшированные ставки для указанной биржи
 func (c *RatesCache) GetRates(exchangeName string) []FundingRate {
 	c.Mu.RLock()
@@ -59,8 +170,18 @@ func (c *RatesCache) GetRates(exchangeName string) []FundingRate {
 func (c *RatesCache) GetAllRates() map[string][]FundingRate {
 	c.Mu.RLock()
 	defer c.Mu.RUnlock()
+	return c.copyRates()
+}
+
+// snapshot возвращает копию всех ставок и версию, которой она соответствует
+func (c *RatesCache) snapshot() (map[string][]FundingRate, uint64) {
+	c.Mu.RLock()
+	defer c.Mu.RUnlock()
+	return c.copyRates(), c.version
+}
 
-	// Создаем копию карты, чтобы избежать гонки данных
+// copyRates копирует карту ставок, чтобы избежать гонки данных, вызывается под c.Mu
+func (c *RatesCache) copyRates() map[string][]FundingRate {
 	result := make(map[string][]FundingRate, len(c.Rates))
 	for k, v := range c.Rates {
 		rates := make([]FundingRate, len(v))
@@ -77,6 +198,25 @@ func (c *RatesCache) GetLastUpdate() time.Time {
 	return c.LastUpdate
 }
 
+// Version возвращает номер версии данных кэша, который меняется при каждом обновлении ставок
+func (c *RatesCache) Version() uint64 {
+	c.Mu.RLock()
+	defer c.Mu.RUnlock()
+	return c.version
+}
+
+// GetStatus возвращает состояние обновлений всех бирж
+func (c *RatesCache) GetStatus() map[string]ExchangeStatus {
+	c.Mu.RLock()
+	defer c.Mu.RUnlock()
+
+	result := make(map[string]ExchangeStatus, len(c.status))
+	for k, v := range c.status {
+		result[k] = v
+	}
+	return result
+}
+
 // GetGlobalCache возвращает глобальный кэш ставок
 func GetGlobalCache() *RatesCache {
 	return globalCache
@@ -0,0 +1,294 @@
+package exchangetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Venue биржа, которую эмулирует MockServer
+type Venue string
+
+const (
+	VenueBinance     Venue = "binance"
+	VenueBybit       Venue = "bybit"
+	VenueOKX         Venue = "okx"
+	VenueGate        Venue = "gate"
+	VenueHTX         Venue = "htx"
+	VenueKuCoin      Venue = "kucoin"
+	VenueBingX       Venue = "bingx"
+	VenueMEXC        Venue = "mexc"
+	VenueHyperliquid Venue = "hyperliquid"
+)
+
+// venueHosts хосты API, запросы к которым обслуживает MockServer
+var venueHosts = map[string]Venue{
+	"fapi.binance.com":       VenueBinance,
+	"api.binance.com":        VenueBinance,
+	"api.bybit.com":          VenueBybit,
+	"www.okx.com":            VenueOKX,
+	"api.gateio.ws":          VenueGate,
+	"api.hbdm.com":           VenueHTX,
+	"api-futures.kucoin.com": VenueKuCoin,
+	"open-api.bingx.com":     VenueBingX,
+	"contract.mexc.com":      VenueMEXC,
+	"api.hyperliquid.xyz":    VenueHyperliquid,
+}
+
+// Venues возвращает все биржи, которые эмулирует MockServer
+func Venues() []Venue {
+	return []Venue{VenueBinance, VenueBybit, VenueOKX, VenueGate, VenueHTX, VenueKuCoin, VenueBingX, VenueMEXC, VenueHyperliquid}
+}
+
+// MockRate состояние бессрочного USDT-контракта на эмулируемой бирже
+type MockRate struct {
+	Rate        float64
+	NextFunding time.Time     // нулевое — ближайшая граница интервала от текущего времени
+	Interval    time.Duration // 0 — 8 часов; Hyperliquid всегда платит раз в час
+	Volume      float64       // объем за 24 часа в базовой валюте
+	Price       float64       // последняя цена; оборот в USDT = Volume * Price
+	SpotPrice   float64       // цена спотовой пары, 0 — совпадает с Price
+	Premium     float64       // индекс премии в минутных свечах
+}
+
+// Fault сбой, который MockServer имитирует на запросах к бирже
+type Fault struct {
+	Path    string        // префикс пути запроса, пусто — любой запрос к бирже
+	Latency time.Duration // задержка перед ответом
+	Status  int           // код ответа вместо обычного, например 429 или 503
+	Body    string        // тело ответа вместо обычного (с кодом Status или 200), например обрезанный JSON
+	Drop    bool          // разорвать соединение без ответа
+	Times   int           // сколько запросов затронет сбой, 0 — до вызова ClearFaults
+}
+
+// MockServer локальный сервер с состоянием, эмулирующий публичные API фандинга бирж.
+// Ставки задаются через SetRate и могут меняться между запросами, сбои — через AddFault.
+// Символ задается базовым активом (BTC), формат символа конкретной биржи возвращает Symbol
+type MockServer struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	rates    map[Venue]map[string]MockRate // биржа -> базовый актив -> состояние
+	faults   map[Venue][]*Fault
+	requests map[Venue]int
+}
+
+// NewMockServer запускает сервер без ставок; его нужно остановить через Close
+func NewMockServer() *MockServer {
+	s := &MockServer{
+		rates:    make(map[Venue]map[string]MockRate),
+		faults:   make(map[Venue][]*Fault),
+		requests: make(map[Venue]int),
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// URL адрес сервера
+func (s *MockServer) URL() string {
+	return s.server.URL
+}
+
+// Client возвращает клиент, перенаправляющий запросы к биржам на этот сервер.
+// Его передают в exchanges.SetHTTPClient
+func (s *MockServer) Client() *http.Client {
+	target, _ := url.Parse(s.server.URL)
+	return &http.Client{Transport: &RewriteTransport{Target: target}}
+}
+
+func (s *MockServer) Close() {
+	s.server.CloseClientConnections()
+	s.server.Close()
+}
+
+// SetRate задает или заменяет состояние контракта base на бирже
+func (s *MockServer) SetRate(venue Venue, base string, rate MockRate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.rates[venue] == nil {
+		s.rates[venue] = make(map[string]MockRate)
+	}
+	s.rates[venue][strings.ToUpper(base)] = rate
+}
+
+// RemoveRate убирает контракт base с биржи, как при делистинге
+func (s *MockServer) RemoveRate(venue Venue, base string) {
+	s.mu.Lock()
+	delete(s.rates[venue], strings.ToUpper(base))
+	s.mu.Unlock()
+}
+
+// AddFault добавляет сбой на бирже. Из подходящих по пути сбоев срабатывает добавленный раньше
+func (s *MockServer) AddFault(venue Venue, fault Fault) {
+	s.mu.Lock()
+	s.faults[venue] = append(s.faults[venue], &fault)
+	s.mu.Unlock()
+}
+
+// ClearFaults убирает все сбои биржи
+func (s *MockServer) ClearFaults(venue Venue) {
+	s.mu.Lock()
+	delete(s.faults, venue)
+	s.mu.Unlock()
+}
+
+// Requests возвращает число запросов к бирже, включая завершившиеся сбоем
+func (s *MockServer) Requests(venue Venue) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[venue]
+}
+
+// Symbol возвращает символ, под которым биржа отдает ставку контракта base в FundingRate
+func Symbol(venue Venue, base string) string {
+	base = strings.ToUpper(base)
+	switch venue {
+	case VenueBinance, VenueBybit:
+		return base + "USDT"
+	case VenueOKX:
+		return base + "-USDT-SWAP"
+	case VenueGate, VenueMEXC:
+		return base + "_USDT"
+	case VenueHTX, VenueHyperliquid:
+		return base
+	case VenueKuCoin:
+		if base == "BTC" {
+			base = "XBT"
+		}
+		return base + "USDTM"
+	case VenueBingX:
+		return base + "-USDT"
+	}
+	return base
+}
+
+// mockContract состояние контракта с подставленными значениями по умолчанию
+type mockContract struct {
+	Base string
+	MockRate
+}
+
+// Spot цена спотовой пары того же актива
+func (c mockContract) Spot() float64 {
+	if c.SpotPrice > 0 {
+		return c.SpotPrice
+	}
+	return c.Price
+}
+
+// Turnover оборот за 24 часа в USDT
+func (c mockContract) Turnover() float64 {
+	return c.Volume * c.Price
+}
+
+// premiumKlines возвращает время открытия минутных свечей индекса премии в периоде [from, to]
+func premiumKlines(from, to time.Time) []time.Time {
+	var times []time.Time
+	for t := from.Truncate(time.Minute); !t.After(to); t = t.Add(time.Minute) {
+		if !t.Before(from) {
+			times = append(times, t)
+		}
+	}
+	return times
+}
+
+// contracts возвращает контракты биржи в порядке активов; вызывается под s.mu
+func (s *MockServer) contracts(venue Venue, now time.Time) []mockContract {
+	list := make([]mockContract, 0, len(s.rates[venue]))
+	for base, rate := range s.rates[venue] {
+		if venue == VenueHyperliquid {
+			rate.Interval = time.Hour
+		}
+		if rate.Interval <= 0 {
+			rate.Interval = 8 * time.Hour
+		}
+		if rate.NextFunding.IsZero() {
+			rate.NextFunding = now.Truncate(rate.Interval).Add(rate.Interval)
+		}
+		list = append(list, mockContract{Base: base, MockRate: rate})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Base < list[j].Base })
+	return list
+}
+
+// takeFault возвращает сбой для запроса и уменьшает его счетчик; вызывается под s.mu
+func (s *MockServer) takeFault(venue Venue, path string) (Fault, bool) {
+	faults := s.faults[venue]
+	for i, fault := range faults {
+		if !strings.HasPrefix(path, fault.Path) {
+			continue
+		}
+		if fault.Times > 0 {
+			fault.Times--
+			if fault.Times == 0 {
+				s.faults[venue] = append(faults[:i:i], faults[i+1:]...)
+			}
+		}
+		return *fault, true
+	}
+	return Fault{}, false
+}
+
+func (s *MockServer) serve(w http.ResponseWriter, r *http.Request) {
+	venue, ok := venueHosts[strings.ToLower(originalHost(r))]
+	if !ok {
+		http.Error(w, `{"error":"exchangetest: неизвестная биржа"}`, http.StatusNotFound)
+		return
+	}
+
+	s.mu.Lock()
+	s.requests[venue]++
+	fault, faulty := s.takeFault(venue, r.URL.Path)
+	s.mu.Unlock()
+
+	if faulty && fault.Latency > 0 {
+		select {
+		case <-time.After(fault.Latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if faulty && fault.Drop {
+		if hijacker, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hijacker.Hijack(); err == nil {
+				conn.Close()
+				return
+			}
+		}
+		panic(http.ErrAbortHandler)
+	}
+
+	if faulty && (fault.Status != 0 || fault.Body != "") {
+		status := fault.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		body := fault.Body
+		if body == "" {
+			body = fmt.Sprintf(`{"code":%d,"msg":%q}`, status, http.StatusText(status))
+		}
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "1")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+		return
+	}
+
+	s.mu.Lock()
+	status, payload := s.respond(venue, r, time.Now())
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(payload)
+}
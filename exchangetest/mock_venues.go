@@ -0,0 +1,443 @@
+package exchangetest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// object тело JSON-ответа
+type object map[string]interface{}
+
+// decimal форматирует число так, как его передают биржи в строковых полях
+func decimal(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// respond формирует ответ биржи по текущему состоянию; вызывается под s.mu
+func (s *MockServer) respond(venue Venue, r *http.Request, now time.Time) (int, interface{}) {
+	contracts := s.contracts(venue, now)
+	path := r.URL.Path
+	query := r.URL.Query()
+
+	switch venue {
+	case VenueBinance:
+		return binanceResponse(path, query, contracts, now)
+	case VenueBybit:
+		return bybitResponse(path, query, contracts, now)
+	case VenueOKX:
+		return okxResponse(path, query, contracts, now)
+	case VenueGate:
+		return gateResponse(path, contracts)
+	case VenueHTX:
+		return htxResponse(path, contracts, now)
+	case VenueKuCoin:
+		return kucoinResponse(path, contracts, now)
+	case VenueBingX:
+		return bingxResponse(path, contracts, now)
+	case VenueMEXC:
+		return mexcResponse(path, contracts, now)
+	case VenueHyperliquid:
+		return hyperliquidResponse(r, contracts)
+	}
+	return notFound()
+}
+
+// queryMillis разбирает время в миллисекундах из параметра запроса
+func queryMillis(query url.Values, name string) time.Time {
+	ms, _ := strconv.ParseInt(query.Get(name), 10, 64)
+	return time.UnixMilli(ms)
+}
+
+func notFound() (int, interface{}) {
+	return http.StatusNotFound, object{"error": "exchangetest: метод не эмулируется"}
+}
+
+func binanceResponse(path string, query url.Values, contracts []mockContract, now time.Time) (int, interface{}) {
+	list := make([]object, 0, len(contracts))
+	switch path {
+	case "/fapi/v1/premiumIndex":
+		for _, c := range contracts {
+			list = append(list, object{
+				"symbol":          Symbol(VenueBinance, c.Base),
+				"markPrice":       decimal(c.Price),
+				"indexPrice":      decimal(c.Price),
+				"lastFundingRate": decimal(c.Rate),
+				"interestRate":    "0.00010000",
+				"nextFundingTime": c.NextFunding.UnixMilli(),
+				"time":            now.UnixMilli(),
+			})
+		}
+	case "/fapi/v1/ticker/24hr":
+		for _, c := range contracts {
+			list = append(list, object{
+				"symbol":      Symbol(VenueBinance, c.Base),
+				"lastPrice":   decimal(c.Price),
+				"volume":      decimal(c.Volume),
+				"quoteVolume": decimal(c.Turnover()),
+			})
+		}
+	case "/api/v3/ticker/price":
+		for _, c := range contracts {
+			list = append(list, object{
+				"symbol": Symbol(VenueBinance, c.Base),
+				"price":  decimal(c.Spot()),
+			})
+		}
+	case "/fapi/v1/premiumIndexKlines":
+		from, to := queryMillis(query, "startTime"), queryMillis(query, "endTime")
+		klines := make([][]interface{}, 0)
+		for _, c := range contracts {
+			if Symbol(VenueBinance, c.Base) != query.Get("symbol") {
+				continue
+			}
+			for _, t := range premiumKlines(from, to) {
+				premium := decimal(c.Premium)
+				klines = append(klines, []interface{}{t.UnixMilli(), premium, premium, premium, premium, "0",
+					t.Add(time.Minute).UnixMilli() - 1, "0", 12, "0", "0", "0"})
+			}
+		}
+		return http.StatusOK, klines
+	case "/fapi/v1/fundingInfo":
+		// Binance перечисляет только контракты с нестандартными параметрами
+		for _, c := range contracts {
+			if c.Interval == 8*time.Hour {
+				continue
+			}
+			list = append(list, object{
+				"symbol":                   Symbol(VenueBinance, c.Base),
+				"adjustedFundingRateCap":   "0.02000000",
+				"adjustedFundingRateFloor": "-0.02000000",
+				"fundingIntervalHours":     int64(c.Interval / time.Hour),
+			})
+		}
+	default:
+		return notFound()
+	}
+	return http.StatusOK, list
+}
+
+func bybitResponse(path string, query url.Values, contracts []mockContract, now time.Time) (int, interface{}) {
+	category := query.Get("category")
+	// Эмулируются только USDT-контракты и спотовые пары к USDT
+	if category != "linear" && category != "spot" {
+		contracts = nil
+	}
+
+	list := make([]object, 0, len(contracts))
+	switch {
+	case path == "/v5/market/tickers" && category == "spot":
+		for _, c := range contracts {
+			list = append(list, object{
+				"symbol":    Symbol(VenueBybit, c.Base),
+				"lastPrice": decimal(c.Spot()),
+			})
+		}
+	case path == "/v5/market/tickers":
+		for _, c := range contracts {
+			list = append(list, object{
+				"symbol":          Symbol(VenueBybit, c.Base),
+				"lastPrice":       decimal(c.Price),
+				"markPrice":       decimal(c.Price),
+				"indexPrice":      decimal(c.Spot()),
+				"fundingRate":     decimal(c.Rate),
+				"nextFundingTime": strconv.FormatInt(c.NextFunding.UnixMilli(), 10),
+				"volume24h":       decimal(c.Volume),
+				"turnover24h":     decimal(c.Turnover()),
+			})
+		}
+	case path == "/v5/market/premium-index-price-kline":
+		// Bybit возвращает свечи от новых к старым
+		from, to := queryMillis(query, "start"), queryMillis(query, "end")
+		klines := make([][]string, 0)
+		for _, c := range contracts {
+			if Symbol(VenueBybit, c.Base) != query.Get("symbol") {
+				continue
+			}
+			times := premiumKlines(from, to)
+			for i := len(times) - 1; i >= 0; i-- {
+				premium := decimal(c.Premium)
+				klines = append(klines, []string{strconv.FormatInt(times[i].UnixMilli(), 10), premium, premium, premium, premium})
+			}
+		}
+		return http.StatusOK, object{"retCode": 0, "retMsg": "OK", "result": object{"category": category, "list": klines}, "time": now.UnixMilli()}
+	case path == "/v5/market/instruments-info":
+		if category == "spot" {
+			break
+		}
+		for _, c := range contracts {
+			list = append(list, object{
+				"symbol":          Symbol(VenueBybit, c.Base),
+				"contractType":    "LinearPerpetual",
+				"status":          "Trading",
+				"settleCoin":      "USDT",
+				"fundingInterval": int64(c.Interval / time.Minute),
+			})
+		}
+	default:
+		return notFound()
+	}
+
+	result := object{"category": category, "list": list}
+	if path == "/v5/market/instruments-info" {
+		result["nextPageCursor"] = ""
+	}
+	return http.StatusOK, object{"retCode": 0, "retMsg": "OK", "result": result, "time": now.UnixMilli()}
+}
+
+func okxResponse(path string, query url.Values, contracts []mockContract, now time.Time) (int, interface{}) {
+	data := make([]object, 0, len(contracts))
+	switch {
+	case path == "/api/v5/market/tickers" && query.Get("instType") == "SPOT":
+		for _, c := range contracts {
+			data = append(data, object{
+				"instType": "SPOT",
+				"instId":   strings.TrimSuffix(Symbol(VenueOKX, c.Base), "-SWAP"),
+				"last":     decimal(c.Spot()),
+				"ts":       strconv.FormatInt(now.UnixMilli(), 10),
+			})
+		}
+	case path == "/api/v5/market/tickers":
+		for _, c := range contracts {
+			data = append(data, object{
+				"instType":  "SWAP",
+				"instId":    Symbol(VenueOKX, c.Base),
+				"last":      decimal(c.Price),
+				"volCcy24h": decimal(c.Volume),
+				"ts":        strconv.FormatInt(now.UnixMilli(), 10),
+			})
+		}
+	case path == "/api/v5/public/mark-price":
+		for _, c := range contracts {
+			data = append(data, object{
+				"instType": "SWAP",
+				"instId":   Symbol(VenueOKX, c.Base),
+				"markPx":   decimal(c.Price),
+				"ts":       strconv.FormatInt(now.UnixMilli(), 10),
+			})
+		}
+	case path == "/api/v5/public/instruments":
+		for _, c := range contracts {
+			data = append(data, object{
+				"instType":  "SWAP",
+				"instId":    Symbol(VenueOKX, c.Base),
+				"ctType":    "linear",
+				"ctVal":     "1",
+				"settleCcy": "USDT",
+				"state":     "live",
+			})
+		}
+	case path == "/api/v5/public/funding-rate":
+		instID := query.Get("instId")
+		for _, c := range contracts {
+			if Symbol(VenueOKX, c.Base) != instID {
+				continue
+			}
+			// fundingTime — ближайшая выплата, nextFundingTime — следующая за ней
+			data = append(data, object{
+				"instType":        "SWAP",
+				"instId":          instID,
+				"fundingRate":     decimal(c.Rate),
+				"fundingTime":     strconv.FormatInt(c.NextFunding.UnixMilli(), 10),
+				"nextFundingTime": strconv.FormatInt(c.NextFunding.Add(c.Interval).UnixMilli(), 10),
+			})
+		}
+		if len(data) == 0 {
+			return http.StatusBadRequest, object{"code": "51001", "msg": "Instrument ID does not exist", "data": data}
+		}
+	default:
+		return notFound()
+	}
+	return http.StatusOK, object{"code": "0", "msg": "", "data": data}
+}
+
+func gateResponse(path string, contracts []mockContract) (int, interface{}) {
+	if path == "/api/v4/spot/tickers" {
+		list := make([]object, 0, len(contracts))
+		for _, c := range contracts {
+			list = append(list, object{
+				"currency_pair": Symbol(VenueGate, c.Base),
+				"last":          decimal(c.Spot()),
+			})
+		}
+		return http.StatusOK, list
+	}
+	if path == "/api/v4/futures/usdt/tickers" {
+		list := make([]object, 0, len(contracts))
+		for _, c := range contracts {
+			list = append(list, object{
+				"contract":         Symbol(VenueGate, c.Base),
+				"last":             decimal(c.Price),
+				"mark_price":       decimal(c.Price),
+				"volume_24h_base":  decimal(c.Volume),
+				"volume_24h_quote": decimal(c.Turnover()),
+			})
+		}
+		return http.StatusOK, list
+	}
+	if path != "/api/v4/futures/usdt/contracts" {
+		return notFound()
+	}
+
+	list := make([]object, 0, len(contracts))
+	for _, c := range contracts {
+		list = append(list, object{
+			"name":               Symbol(VenueGate, c.Base),
+			"type":               "direct",
+			"mark_price":         decimal(c.Price),
+			"index_price":        decimal(c.Spot()),
+			"last_price":         decimal(c.Price),
+			"funding_rate":       decimal(c.Rate),
+			"funding_interval":   int64(c.Interval / time.Second),
+			"funding_next_apply": c.NextFunding.Unix(),
+		})
+	}
+	return http.StatusOK, list
+}
+
+func htxResponse(path string, contracts []mockContract, now time.Time) (int, interface{}) {
+	list := make([]object, 0, len(contracts))
+	switch path {
+	case "/linear-swap-ex/market/detail/batch_merged":
+		for _, c := range contracts {
+			list = append(list, object{
+				"contract_code":  c.Base + "-USDT",
+				"business_type":  "swap",
+				"close":          decimal(c.Price),
+				"vol":            decimal(c.Volume),
+				"trade_turnover": decimal(c.Turnover()),
+			})
+		}
+		return http.StatusOK, object{"status": "ok", "ticks": list, "ts": now.UnixMilli()}
+	case "/linear-swap-api/v1/swap_batch_funding_rate":
+		for _, c := range contracts {
+			list = append(list, object{
+				"symbol":        c.Base,
+				"contract_code": c.Base + "-USDT",
+				"fee_asset":     "USDT",
+				"funding_rate":  decimal(c.Rate),
+				"funding_time":  strconv.FormatInt(c.NextFunding.UnixMilli(), 10),
+			})
+		}
+		return http.StatusOK, object{"status": "ok", "data": list, "ts": now.UnixMilli()}
+	}
+	return notFound()
+}
+
+func kucoinResponse(path string, contracts []mockContract, now time.Time) (int, interface{}) {
+	if path != "/api/v1/contracts/active" {
+		return notFound()
+	}
+
+	list := make([]object, 0, len(contracts))
+	for _, c := range contracts {
+		list = append(list, object{
+			"symbol":                 Symbol(VenueKuCoin, c.Base),
+			"settleCurrency":         "USDT",
+			"fundingFeeRate":         c.Rate,
+			"fundingRateGranularity": c.Interval.Milliseconds(),
+			"nextFundingRateTime":    c.NextFunding.Sub(now).Milliseconds(), // мс до выплаты
+			"markPrice":              c.Price,
+			"volumeOf24h":            c.Volume,
+			"turnoverOf24h":          c.Turnover(),
+			"status":                 "Open",
+		})
+	}
+	return http.StatusOK, object{"code": "200000", "data": list}
+}
+
+func bingxResponse(path string, contracts []mockContract, now time.Time) (int, interface{}) {
+	list := make([]object, 0, len(contracts))
+	switch path {
+	case "/openApi/swap/v2/ticker/24hr":
+		for _, c := range contracts {
+			list = append(list, object{
+				"symbol":      Symbol(VenueBingX, c.Base),
+				"lastPrice":   decimal(c.Price),
+				"volume":      c.Volume,
+				"quoteVolume": c.Turnover(),
+			})
+		}
+	case "/openApi/swap/v2/quote/fundingRate":
+		for _, c := range contracts {
+			list = append(list, object{
+				"symbol":          Symbol(VenueBingX, c.Base),
+				"fundingRate":     c.Rate,
+				"fundingTime":     c.NextFunding.Add(-c.Interval).UnixMilli(),
+				"nextFundingTime": c.NextFunding.UnixMilli(),
+			})
+		}
+	default:
+		return notFound()
+	}
+	return http.StatusOK, object{"code": 0, "msg": "", "data": list}
+}
+
+func mexcResponse(path string, contracts []mockContract, now time.Time) (int, interface{}) {
+	const fundingPrefix = "/api/v1/contract/funding_rate/"
+
+	switch {
+	case path == "/api/v1/contract/ticker":
+		list := make([]object, 0, len(contracts))
+		for _, c := range contracts {
+			list = append(list, object{
+				"symbol":      Symbol(VenueMEXC, c.Base),
+				"lastPrice":   c.Price,
+				"volume24":    c.Volume,
+				"amount24":    c.Turnover(),
+				"fundingRate": c.Rate,
+			})
+		}
+		return http.StatusOK, object{"success": true, "code": 0, "data": list}
+	case strings.HasPrefix(path, fundingPrefix):
+		symbol := strings.TrimPrefix(path, fundingPrefix)
+		for _, c := range contracts {
+			if Symbol(VenueMEXC, c.Base) != symbol {
+				continue
+			}
+			return http.StatusOK, object{"success": true, "code": 0, "data": object{
+				"symbol":         symbol,
+				"fundingRate":    c.Rate,
+				"collectCycle":   int64(c.Interval / time.Hour),
+				"nextSettleTime": c.NextFunding.UnixMilli(),
+				"timestamp":      now.UnixMilli(),
+			}}
+		}
+		return http.StatusOK, object{"success": false, "code": 1001, "message": "contract not exists"}
+	}
+	return notFound()
+}
+
+func hyperliquidResponse(r *http.Request, contracts []mockContract) (int, interface{}) {
+	var request struct {
+		Type string `json:"type"`
+	}
+	body, _ := io.ReadAll(r.Body)
+	if r.Method != http.MethodPost || r.URL.Path != "/info" || json.Unmarshal(body, &request) != nil {
+		return http.StatusUnprocessableEntity, object{"error": "Failed to deserialize the JSON body"}
+	}
+	if request.Type != "metaAndAssetCtxs" {
+		return notFound()
+	}
+
+	universe := make([]object, 0, len(contracts))
+	contexts := make([]object, 0, len(contracts))
+	for _, c := range contracts {
+		universe = append(universe, object{"name": Symbol(VenueHyperliquid, c.Base), "szDecimals": 3, "maxLeverage": 20})
+		contexts = append(contexts, object{
+			"dayNtlVlm":    decimal(c.Turnover()),
+			"funding":      decimal(c.Rate),
+			"markPx":       decimal(c.Price),
+			"midPx":        decimal(c.Price),
+			"oraclePx":     decimal(c.Price),
+			"openInterest": "0.0",
+			"premium":      "0.0",
+			"prevDayPx":    decimal(c.Price),
+		})
+	}
+	return http.StatusOK, []interface{}{object{"universe": universe}, contexts}
+}
@@ -0,0 +1,199 @@
+package exchangetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Redacted значение, которым заменяются секреты в записанных фикстурах
+const Redacted = "REDACTED"
+
+// ScrubbedHeaders заголовки с ключами и подписями, значения которых не попадают в фикстуры
+var ScrubbedHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-MBX-APIKEY",         // Binance
+	"X-BAPI-API-KEY",       // Bybit
+	"X-BAPI-SIGN",          // Bybit
+	"OK-ACCESS-KEY",        // OKX
+	"OK-ACCESS-SIGN",       // OKX
+	"OK-ACCESS-PASSPHRASE", // OKX
+	"KEY",                  // Gate.io
+	"SIGN",                 // Gate.io
+	"X-BX-APIKEY",          // BingX
+}
+
+// ScrubbedParams параметры запроса с подписями и ключами, значения которых не попадают в фикстуры
+var ScrubbedParams = []string{"signature", "sign", "apiKey", "api_key"}
+
+// droppedHeaders заголовки ответа, которые сервер воспроизведения выставляет сам
+var droppedHeaders = []string{"Content-Length", "Content-Encoding", "Transfer-Encoding", "Connection", "Date"}
+
+// RecordTransport выполняет запросы через Base и сохраняет каждый запрос с ответом в каталог
+// Dir как фикстуру. Ключи и подписи вырезаются, поэтому записанный каталог можно
+// сразу передавать в LoadFixtures и NewReplayServer
+type RecordTransport struct {
+	Dir  string
+	Base http.RoundTripper // nil — http.DefaultTransport
+
+	mu  sync.Mutex
+	seq int
+}
+
+// NewRecordTransport создает каталог dir и возвращает транспорт, записывающий в него фикстуры.
+// Номера файлов продолжают уже записанные в каталоге
+func NewRecordTransport(dir string, base http.RoundTripper) (*RecordTransport, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	existing, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	return &RecordTransport{Dir: dir, Base: base, seq: len(existing)}, nil
+}
+
+func (t *RecordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		requestBody = body
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	fixture := Fixture{
+		Method:        req.Method,
+		URL:           scrubURL(req.URL),
+		RequestHeader: scrubHeader(req.Header),
+		Status:        resp.StatusCode,
+		Header:        scrubHeader(resp.Header),
+	}
+	for _, name := range droppedHeaders {
+		delete(fixture.Header, name)
+	}
+	if len(fixture.Header) == 0 {
+		fixture.Header = nil
+	}
+	if len(requestBody) > 0 {
+		fixture.RequestBody = rawJSONOrString(requestBody)
+	}
+	if json.Valid(body) {
+		fixture.Body = body
+	} else {
+		fixture.BodyText = string(body)
+	}
+
+	if err := t.save(req.URL, fixture); err != nil {
+		return nil, fmt.Errorf("exchangetest: ошибка записи фикстуры: %v", err)
+	}
+	return resp, nil
+}
+
+// save записывает фикстуру в файл вида 0001_get_fapi.binance.com_fapi_v1_premiumIndex.json
+func (t *RecordTransport) save(u *url.URL, fixture Fixture) error {
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	t.seq++
+	name := fmt.Sprintf("%04d_%s_%s%s.json", t.seq, strings.ToLower(fixture.Method), u.Host, fileNamePart(u.Path))
+	t.mu.Unlock()
+
+	return os.WriteFile(filepath.Join(t.Dir, name), append(data, '\n'), 0o644)
+}
+
+// scrubURL возвращает адрес запроса с вырезанными подписями и ключами
+func scrubURL(u *url.URL) string {
+	clone := *u
+	query := clone.Query()
+	changed := false
+	for name := range query {
+		if containsFold(ScrubbedParams, name) {
+			query.Set(name, Redacted)
+			changed = true
+		}
+	}
+	if changed {
+		clone.RawQuery = query.Encode()
+	}
+	return clone.String()
+}
+
+// scrubHeader копирует заголовки, заменяя значения секретных на Redacted
+func scrubHeader(header http.Header) http.Header {
+	clone := header.Clone()
+	for name := range clone {
+		if containsFold(ScrubbedHeaders, name) {
+			clone[name] = []string{Redacted}
+		}
+	}
+	delete(clone, OriginalHostHeader)
+	return clone
+}
+
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// rawJSONOrString сохраняет тело запроса как JSON, а не-JSON тело — как строку JSON
+func rawJSONOrString(body []byte) json.RawMessage {
+	if json.Valid(body) {
+		return body
+	}
+	quoted, _ := json.Marshal(string(body))
+	return quoted
+}
+
+// fileNamePart переводит путь запроса в часть имени файла
+func fileNamePart(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '.' {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	name := strings.TrimRight(b.String(), "_")
+	if len(name) > 80 {
+		name = name[:80]
+	}
+	return name
+}
@@ -0,0 +1,114 @@
+package exchangetest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+)
+
+// ReplayServer локальный сервер, отвечающий на запросы записанными фикстурами.
+// Запрос сопоставляется по методу, хосту, пути, параметрам (кроме IgnoredParams) и, если
+// в фикстуре задано, телу запроса. На запрос без фикстуры сервер отвечает 404
+type ReplayServer struct {
+	server *httptest.Server
+
+	mu            sync.Mutex
+	fixtures      []Fixture
+	ignoredParams []string
+	unmatched     []string
+}
+
+// NewReplayServer запускает сервер с фикстурами; его нужно остановить через Close
+func NewReplayServer(fixtures ...Fixture) *ReplayServer {
+	s := &ReplayServer{
+		fixtures:      fixtures,
+		ignoredParams: DefaultIgnoredParams,
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Add добавляет фикстуры; при совпадении ключей используется добавленная раньше
+func (s *ReplayServer) Add(fixtures ...Fixture) {
+	s.mu.Lock()
+	s.fixtures = append(s.fixtures, fixtures...)
+	s.mu.Unlock()
+}
+
+// SetIgnoredParams задает параметры запроса, не участвующие в сопоставлении
+func (s *ReplayServer) SetIgnoredParams(params ...string) {
+	s.mu.Lock()
+	s.ignoredParams = params
+	s.mu.Unlock()
+}
+
+// URL адрес сервера
+func (s *ReplayServer) URL() string {
+	return s.server.URL
+}
+
+// Client возвращает клиент, перенаправляющий запросы к любым биржам на этот сервер.
+// Его передают в exchanges.SetHTTPClient
+func (s *ReplayServer) Client() *http.Client {
+	target, _ := url.Parse(s.server.URL)
+	return &http.Client{Transport: &RewriteTransport{Target: target}}
+}
+
+// Unmatched возвращает запросы, для которых не нашлось фикстуры
+func (s *ReplayServer) Unmatched() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.unmatched...)
+}
+
+func (s *ReplayServer) Close() {
+	s.server.Close()
+}
+
+func (s *ReplayServer) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	s.mu.Lock()
+	key := requestKey(r.Method, originalHost(r), r.URL.Path, r.URL.Query(), s.ignoredParams)
+	fixture, ok := s.match(key, body)
+	if !ok {
+		s.unmatched = append(s.unmatched, key)
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		http.Error(w, `{"error":"exchangetest: фикстура не найдена"}`, http.StatusNotFound)
+		return
+	}
+
+	for name, values := range fixture.Header {
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+	if w.Header().Get("Content-Type") == "" && len(fixture.Body) > 0 {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.WriteHeader(fixture.Status)
+	w.Write(fixture.ResponseBody())
+}
+
+// match ищет фикстуру по ключу запроса; вызывается под s.mu
+func (s *ReplayServer) match(key string, body []byte) (Fixture, bool) {
+	for _, fixture := range s.fixtures {
+		u, err := url.Parse(fixture.URL)
+		if err != nil {
+			continue
+		}
+		if requestKey(fixture.Method, u.Host, u.Path, u.Query(), s.ignoredParams) != key {
+			continue
+		}
+		if len(fixture.RequestBody) > 0 && !sameJSON(fixture.RequestBody, body) {
+			continue
+		}
+		return fixture, true
+	}
+	return Fixture{}, false
+}
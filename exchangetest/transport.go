@@ -0,0 +1,38 @@
+package exchangetest
+
+import (
+	"net/http"
+	"net/url"
+)
+
+// OriginalHostHeader заголовок, в котором RewriteTransport передает исходный хост биржи
+const OriginalHostHeader = "X-Exchangetest-Host"
+
+// RewriteTransport перенаправляет запросы к биржам на локальный сервер, сохраняя исходный хост
+// в заголовке OriginalHostHeader
+type RewriteTransport struct {
+	Target *url.URL          // адрес локального сервера
+	Base   http.RoundTripper // nil — http.DefaultTransport
+}
+
+func (t *RewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	clone := req.Clone(req.Context())
+	clone.Header.Set(OriginalHostHeader, req.URL.Host)
+	clone.URL.Scheme = t.Target.Scheme
+	clone.URL.Host = t.Target.Host
+	clone.Host = ""
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(clone)
+}
+
+// originalHost возвращает хост биржи, к которой обращался клиент
+func originalHost(r *http.Request) string {
+	if host := r.Header.Get(OriginalHostHeader); host != "" {
+		return host
+	}
+	return r.Host
+}
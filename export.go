@@ -0,0 +1,198 @@
+package exchanges
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress/zstd"
+)
+
+// ExportRow строка выгрузки ставок со стабильной схемой для CSV и Parquet
+type ExportRow struct {
+	Exchange        string     `parquet:"exchange,dict"`
+	Symbol          string     `parquet:"symbol,dict"`
+	CanonicalSymbol string     `parquet:"canonical_symbol,dict"`
+	Rate            float64    `parquet:"rate"`
+	FundingInterval int64      `parquet:"funding_interval_seconds"`  // 0 — неизвестен
+	NextFunding     *time.Time `parquet:"next_funding_utc,optional"` // nil — время не распознано
+	Volume24h       float64    `parquet:"volume_24h"`                // в базовой валюте
+	VolumeUSDT24h   float64    `parquet:"volume_usdt_24h"`           // в USDT
+	CapturedAt      time.Time  `parquet:"captured_at"`               // время снимка
+
+	// Точные десятичные записи из FundingRate.Precise; пусто, если Precise не заполнен
+	RateDecimal          string `parquet:"rate_decimal,optional"`
+	Volume24hDecimal     string `parquet:"volume_24h_decimal,optional"`
+	VolumeUSDT24hDecimal string `parquet:"volume_usdt_24h_decimal,optional"`
+}
+
+// exportColumns имена колонок в порядке полей ExportRow
+var exportColumns = []string{
+	"exchange", "symbol", "canonical_symbol", "rate", "funding_interval_seconds",
+	"next_funding_utc", "volume_24h", "volume_usdt_24h", "captured_at",
+	"rate_decimal", "volume_24h_decimal", "volume_usdt_24h_decimal",
+}
+
+// NewExportRow формирует строку выгрузки из ставки биржи
+func NewExportRow(exchange string, rate FundingRate, capturedAt time.Time) ExportRow {
+	row := ExportRow{
+		Exchange:        exchange,
+		Symbol:          rate.Symbol,
+		CanonicalSymbol: CanonicalSymbol(rate.Symbol),
+		Rate:            rate.Rate,
+		FundingInterval: int64(rate.FundingInterval / time.Second),
+		Volume24h:       rate.Volume24h,
+		VolumeUSDT24h:   rate.VolumeUSDT24h,
+		CapturedAt:      capturedAt.UTC(),
+	}
+	if nextFunding, err := time.Parse(time.RFC3339, rate.NextFunding); err == nil {
+		nextFunding = nextFunding.UTC()
+		row.NextFunding = &nextFunding
+	}
+	if rate.Precise != nil {
+		row.RateDecimal = decimalColumn(rate.Precise.Rate)
+		row.Volume24hDecimal = decimalColumn(rate.Precise.Volume24h)
+		row.VolumeUSDT24hDecimal = decimalColumn(rate.Precise.VolumeUSDT24h)
+	}
+	return row
+}
+
+// decimalColumn возвращает запись точного значения; нулевое без исходной записи — пусто
+func decimalColumn(d Decimal) string {
+	if d.Raw == "" && d.IsZero() {
+		return ""
+	}
+	return d.String()
+}
+
+// Exporter записывает строки выгрузки в выбранном формате
+type Exporter interface {
+	Write(rows ...ExportRow) error
+	// Close дописывает буферизованные данные, но не закрывает исходный io.Writer
+	Close() error
+}
+
+// CSVExporter пишет строки в CSV с заголовком
+type CSVExporter struct {
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+func NewCSVExporter(w io.Writer) *CSVExporter {
+	return &CSVExporter{writer: csv.NewWriter(w)}
+}
+
+func (e *CSVExporter) Write(rows ...ExportRow) error {
+	if !e.headerWritten {
+		if err := e.writer.Write(exportColumns); err != nil {
+			return err
+		}
+		e.headerWritten = true
+	}
+
+	for _, row := range rows {
+		nextFunding := ""
+		if row.NextFunding != nil {
+			nextFunding = row.NextFunding.Format(time.RFC3339)
+		}
+		record := []string{
+			row.Exchange,
+			row.Symbol,
+			row.CanonicalSymbol,
+			strconv.FormatFloat(row.Rate, 'f', -1, 64),
+			strconv.FormatInt(row.FundingInterval, 10),
+			nextFunding,
+			strconv.FormatFloat(row.Volume24h, 'f', -1, 64),
+			strconv.FormatFloat(row.VolumeUSDT24h, 'f', -1, 64),
+			row.CapturedAt.Format(time.RFC3339Nano),
+			row.RateDecimal,
+			row.Volume24hDecimal,
+			row.VolumeUSDT24hDecimal,
+		}
+		if err := e.writer.Write(record); err != nil {
+			return err
+		}
+	}
+	return e.writer.Error()
+}
+
+func (e *CSVExporter) Close() error {
+	if !e.headerWritten {
+		if err := e.writer.Write(exportColumns); err != nil {
+			return err
+		}
+		e.headerWritten = true
+	}
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// parquetRowGroupSize ограничивает число строк в памяти при потоковой записи Parquet
+const parquetRowGroupSize = 100_000
+
+// ParquetExporter пишет строки в Apache Parquet со сжатием zstd.
+// Строки сбрасываются группами, поэтому большие выгрузки не держатся в памяти целиком
+type ParquetExporter struct {
+	writer *parquet.GenericWriter[ExportRow]
+}
+
+func NewParquetExporter(w io.Writer) *ParquetExporter {
+	return &ParquetExporter{
+		writer: parquet.NewGenericWriter[ExportRow](w,
+			parquet.Compression(&zstd.Codec{}),
+			parquet.MaxRowsPerRowGroup(parquetRowGroupSize),
+		),
+	}
+}
+
+func (e *ParquetExporter) Write(rows ...ExportRow) error {
+	_, err := e.writer.Write(rows)
+	return err
+}
+
+func (e *ParquetExporter) Close() error {
+	return e.writer.Close()
+}
+
+// ExportSnapshot записывает снимок ставок (например, результат RatesCache.GetAllRates)
+// и закрывает экспортер
+func ExportSnapshot(exporter Exporter, rates map[string][]FundingRate, capturedAt time.Time) error {
+	for exchange, list := range rates {
+		rows := make([]ExportRow, 0, len(list))
+		for _, rate := range list {
+			rows = append(rows, NewExportRow(exchange, rate, capturedAt))
+		}
+		if err := exporter.Write(rows...); err != nil {
+			return err
+		}
+	}
+	return exporter.Close()
+}
+
+// ExportHistory потоково записывает выборку из истории ставок и закрывает экспортер
+func ExportHistory(exporter Exporter, store *SQLiteStore, query HistoryQuery) error {
+	const batchSize = 1000
+
+	batch := make([]ExportRow, 0, batchSize)
+	err := store.Iterate(query, func(record HistoryRecord) error {
+		batch = append(batch, NewExportRow(record.Exchange, record.FundingRate, record.CapturedAt))
+		if len(batch) < batchSize {
+			return nil
+		}
+		err := exporter.Write(batch...)
+		batch = batch[:0]
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(batch) > 0 {
+		if err := exporter.Write(batch...); err != nil {
+			return err
+		}
+	}
+	return exporter.Close()
+}
@@ -0,0 +1,177 @@
+package exchanges_test
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+)
+
+// wantExportColumns колонки выгрузки в порядке записи
+var wantExportColumns = []string{
+	"exchange", "symbol", "canonical_symbol", "rate", "funding_interval_seconds",
+	"next_funding_utc", "volume_24h", "volume_usdt_24h", "captured_at",
+	"rate_decimal", "volume_24h_decimal", "volume_usdt_24h_decimal",
+}
+
+func mustDecimal(t *testing.T, s string) exchanges.Decimal {
+	t.Helper()
+
+	d, err := exchanges.ParseDecimal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// exportSnapshotRates снимок с точными значениями, временем фандинга в другой зоне и без него
+func exportSnapshotRates(t *testing.T) map[string][]exchanges.FundingRate {
+	t.Helper()
+
+	return map[string][]exchanges.FundingRate{
+		"Binance": {
+			{
+				Symbol: "BTCUSDT", Rate: 0.0001, NextFunding: "2026-05-01T11:00:00+03:00", FundingInterval: 8 * time.Hour,
+				Volume24h: 1500.5, VolumeUSDT24h: 90030000.5,
+				Precise: &exchanges.PreciseValues{
+					Rate:          mustDecimal(t, "0.00010000"),
+					Volume24h:     mustDecimal(t, "1500.500"),
+					VolumeUSDT24h: mustDecimal(t, "90030000.50"),
+				},
+			},
+			{Symbol: "ETHUSDT", Rate: -0.00005, NextFunding: "скоро"},
+		},
+	}
+}
+
+var exportCapturedAt = time.Date(2026, 5, 1, 7, 30, 15, 123456789, time.FixedZone("MSK", 3*60*60))
+
+func createExportFile(t *testing.T, name string) *os.File {
+	t.Helper()
+
+	f, err := os.Create(filepath.Join(t.TempDir(), name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func readCSV(t *testing.T, path string) [][]string {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) == 0 || !reflect.DeepEqual(records[0], wantExportColumns) {
+		t.Fatalf("заголовок %v, ожидалось %v", records, wantExportColumns)
+	}
+	return records[1:]
+}
+
+func TestExportSnapshotCSV(t *testing.T) {
+	f := createExportFile(t, "snapshot.csv")
+	if err := exchanges.ExportSnapshot(exchanges.NewCSVExporter(f), exportSnapshotRates(t), exportCapturedAt); err != nil {
+		t.Fatal(err)
+	}
+
+	rows := readCSV(t, f.Name())
+	want := [][]string{
+		{"Binance", "BTCUSDT", "BTC", "0.0001", "28800", "2026-05-01T08:00:00Z", "1500.5", "90030000.5",
+			"2026-05-01T04:30:15.123456789Z", "0.00010000", "1500.500", "90030000.50"},
+		// Нераспознанное время фандинга и отсутствие точных значений — пустые колонки
+		{"Binance", "ETHUSDT", "ETH", "-0.00005", "0", "", "0", "0", "2026-05-01T04:30:15.123456789Z", "", "", ""},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("строки\n%v\nожидалось\n%v", rows, want)
+	}
+}
+
+func TestExportSnapshotParquet(t *testing.T) {
+	f := createExportFile(t, "snapshot.parquet")
+	if err := exchanges.ExportSnapshot(exchanges.NewParquetExporter(f), exportSnapshotRates(t), exportCapturedAt); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := parquet.OpenFile(f, info.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var columns []string
+	for _, field := range file.Schema().Fields() {
+		columns = append(columns, field.Name())
+	}
+	if !reflect.DeepEqual(columns, wantExportColumns) {
+		t.Errorf("колонки %v, ожидалось %v", columns, wantExportColumns)
+	}
+
+	rows, err := parquet.ReadFile[exchanges.ExportRow](f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("строки %+v", rows)
+	}
+
+	btc, eth := rows[0], rows[1]
+	nextFunding := time.Date(2026, 5, 1, 8, 0, 0, 0, time.UTC)
+	if btc.NextFunding == nil || !btc.NextFunding.Equal(nextFunding) || !btc.CapturedAt.Equal(exportCapturedAt) {
+		t.Errorf("время BTC: фандинг %v, снимок %v", btc.NextFunding, btc.CapturedAt)
+	}
+	if btc.Rate != 0.0001 || btc.FundingInterval != 28800 || btc.CanonicalSymbol != "BTC" {
+		t.Errorf("строка BTC %+v", btc)
+	}
+	if btc.RateDecimal != "0.00010000" || btc.Volume24hDecimal != "1500.500" || btc.VolumeUSDT24hDecimal != "90030000.50" {
+		t.Errorf("точные значения BTC %q %q %q", btc.RateDecimal, btc.Volume24hDecimal, btc.VolumeUSDT24hDecimal)
+	}
+	if eth.NextFunding != nil || eth.RateDecimal != "" || eth.Rate != -0.00005 {
+		t.Errorf("строка ETH %+v", eth)
+	}
+}
+
+func TestExportHistory(t *testing.T) {
+	store := newTestStore(t)
+	start := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	for i, rate := range []float64{0.0001, 0.0002, 0.0003} {
+		rates := []exchanges.FundingRate{{Symbol: "BTCUSDT", Rate: rate, NextFunding: "2026-05-01T08:00:00Z"}}
+		if err := store.SaveRates("Binance", rates, start.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f := createExportFile(t, "history.csv")
+	if err := exchanges.ExportHistory(exchanges.NewCSVExporter(f), store, exchanges.HistoryQuery{Exchange: "Binance"}); err != nil {
+		t.Fatal(err)
+	}
+
+	rows := readCSV(t, f.Name())
+	if len(rows) != 3 {
+		t.Fatalf("строки %v", rows)
+	}
+	for i, row := range rows {
+		if want := start.Add(time.Duration(i) * time.Minute).Format(time.RFC3339Nano); row[8] != want {
+			t.Errorf("строка %d: время снимка %s, ожидалось %s", i, row[8], want)
+		}
+	}
+	// История не хранит точные значения: колонки decimal пустые
+	if last := rows[2]; last[3] != "0.0003" || last[9] != "" {
+		t.Errorf("последняя строка %v", last)
+	}
+}
@@ -0,0 +1,285 @@
+package exchanges_test
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+	"github.com/petrixs/cr-exchanges/exchangetest"
+)
+
+func TestMain(m *testing.M) {
+	// Время следующего фандинга форматируется в локальной зоне, фиксируем UTC
+	log.SetOutput(io.Discard)
+	time.Local = time.UTC
+	os.Unsetenv("TIMEZONE")
+	os.Exit(m.Run())
+}
+
+type fundingRatesGetter interface {
+	GetFundingRates() ([]exchanges.FundingRate, error)
+}
+
+func TestGetFundingRatesFixtures(t *testing.T) {
+	tests := []struct {
+		name     string
+		exchange fundingRatesGetter
+		fixtures string // каталог в testdata/fixtures
+		want     []exchanges.FundingRate
+		wantErr  string
+		// Время следующего фандинга вычисляется от текущего времени и проверяется отдельно
+		dynamicNextFunding bool
+	}{
+		{
+			name:     "Binance",
+			exchange: &exchanges.Binance{},
+			fixtures: "binance/ok",
+			want: []exchanges.FundingRate{
+				{Symbol: "BTCUSDT", Rate: 0.0001, NextFunding: "2026-01-01T08:00:00Z", Volume24h: 1500.5, VolumeUSDT24h: 90030000.25, MarkPrice: 60000.5, IndexPrice: 59990.12, FundingInterval: 8 * time.Hour, MarginType: exchanges.MarginLinear},
+				{Symbol: "ETHUSDT", Rate: -0.00005, NextFunding: "2026-01-01T08:00:00Z", Volume24h: 20000, VolumeUSDT24h: 60002000, MarkPrice: 3000.1, IndexPrice: 3000, FundingInterval: 8 * time.Hour, MarginType: exchanges.MarginLinear},
+				{Symbol: "XYZUSDT", Rate: 0.0025, NextFunding: "2026-01-01T04:00:00Z", MarkPrice: 1.25, IndexPrice: 1.249, FundingInterval: 4 * time.Hour, MarginType: exchanges.MarginLinear},
+			},
+		},
+		{
+			name:     "Bybit",
+			exchange: &exchanges.Bybit{},
+			fixtures: "bybit/ok",
+			want: []exchanges.FundingRate{
+				{Symbol: "BTCUSDT", Rate: 0.0001, NextFunding: "2026-01-01T08:00:00Z", Volume24h: 12000.5, VolumeUSDT24h: 720030000.25, MarkPrice: 60000.4, IndexPrice: 59990.1, FundingInterval: 8 * time.Hour, MarginType: exchanges.MarginLinear},
+				{Symbol: "ETHUSDT", Rate: -0.00025, NextFunding: "2026-01-01T04:00:00Z", Volume24h: 50000, VolumeUSDT24h: 150005000, MarkPrice: 3000, IndexPrice: 2999.9, FundingInterval: 4 * time.Hour, MarginType: exchanges.MarginLinear},
+			},
+		},
+		{
+			name:     "OKX",
+			exchange: &exchanges.OKX{},
+			fixtures: "okx/ok",
+			want: []exchanges.FundingRate{
+				{Symbol: "BTC-USDT-SWAP", Rate: 0.0001, NextFunding: "2026-01-01T16:00:00Z", Volume24h: 1000.5, VolumeUSDT24h: 60030000, MarkPrice: 60010.5, FundingInterval: 8 * time.Hour, SettleAsset: "USDT", MarginType: exchanges.MarginLinear},
+			},
+		},
+		{
+			name:     "OKX ошибка API",
+			exchange: &exchanges.OKX{},
+			fixtures: "okx/error",
+			wantErr:  "50011",
+		},
+		{
+			name:     "Gate",
+			exchange: &exchanges.Gate{},
+			fixtures: "gate/ok",
+			want: []exchanges.FundingRate{
+				{Symbol: "BTC_USDT", Rate: 0.0001, NextFunding: "2026-01-01T08:00:00Z", Volume24h: 1500.5, VolumeUSDT24h: 90030000.25, MarkPrice: 60000.5, IndexPrice: 59990.1, FundingInterval: 8 * time.Hour},
+				{Symbol: "ETH_USDT", Rate: -0.0002, NextFunding: "2026-01-01T04:00:00Z", Volume24h: 20000, VolumeUSDT24h: 60002000, MarkPrice: 3000.1, IndexPrice: 3000, FundingInterval: 4 * time.Hour},
+			},
+		},
+		{
+			name:     "HTX",
+			exchange: &exchanges.HTX{},
+			fixtures: "htx/ok",
+			want: []exchanges.FundingRate{
+				{Symbol: "BTC", Rate: 0.0001, NextFunding: "2026-01-01T08:00:00Z", Volume24h: 2000, VolumeUSDT24h: 120000000},
+				{Symbol: "ETH", Rate: -0.00005, NextFunding: "2026-01-01T08:00:00Z"},
+			},
+		},
+		{
+			name:     "KuCoin",
+			exchange: &exchanges.KuCoin{},
+			fixtures: "kucoin/ok",
+			want: []exchanges.FundingRate{
+				{Symbol: "XBTUSDTM", Rate: 0.0001, Volume24h: 1500.5, VolumeUSDT24h: 90030000.5, FundingInterval: 8 * time.Hour},
+				{Symbol: "ETHUSDTM", Rate: -0.00025, Volume24h: 20000, VolumeUSDT24h: 60002000, FundingInterval: 4 * time.Hour},
+			},
+			dynamicNextFunding: true,
+		},
+		{
+			name:     "KuCoin ошибка API",
+			exchange: &exchanges.KuCoin{},
+			fixtures: "kucoin/error",
+			wantErr:  "400100",
+		},
+		{
+			name:     "BingX",
+			exchange: &exchanges.BingX{},
+			fixtures: "bingx/ok",
+			want: []exchanges.FundingRate{
+				{Symbol: "BTC-USDT", Rate: 0.0001, NextFunding: "2026-01-01T08:00:00Z", Volume24h: 1500.5, VolumeUSDT24h: 90030000.5, FundingInterval: 8 * time.Hour},
+				{Symbol: "ETH-USDT", Rate: -0.0003, NextFunding: "2026-01-01T08:00:00Z", Volume24h: 20000, VolumeUSDT24h: 60002000, FundingInterval: 8 * time.Hour},
+			},
+		},
+		{
+			name:     "MEXC",
+			exchange: &exchanges.MEXC{},
+			fixtures: "mexc/ok",
+			want: []exchanges.FundingRate{
+				{Symbol: "BTC_USDT", Rate: 0.0001, NextFunding: "2026-01-01T08:00:00Z", Volume24h: 1500, VolumeUSDT24h: 90000000, FundingInterval: 8 * time.Hour},
+			},
+		},
+		{
+			name:     "MEXC ошибка API",
+			exchange: &exchanges.MEXC{},
+			fixtures: "mexc/error",
+			wantErr:  "MEXC API error",
+		},
+		{
+			name:     "Hyperliquid",
+			exchange: &exchanges.Hyperliquid{},
+			fixtures: "hyperliquid/ok",
+			want: []exchanges.FundingRate{
+				{Symbol: "BTC", Rate: 0.0000125, Volume24h: 1500000000.5 / 60000.5, VolumeUSDT24h: 1500000000.5, FundingInterval: time.Hour, SettleAsset: "USDC"},
+			},
+			dynamicNextFunding: true,
+		},
+		{
+			name:     "Coinbase International",
+			exchange: &exchanges.CoinbaseIntl{},
+			fixtures: "coinbaseintl/ok",
+			want: []exchanges.FundingRate{
+				{Symbol: "BTC-PERP", Rate: 0.00001, Volume24h: 1500.5, VolumeUSDT24h: 90030000.5, PredictedRate: 0.000012, MarkPrice: 60000.5, IndexPrice: 59990, FundingInterval: time.Hour, SettleAsset: "USDC"},
+			},
+			dynamicNextFunding: true,
+		},
+		{
+			name:     "Phemex",
+			exchange: &exchanges.Phemex{},
+			fixtures: "phemex/ok",
+			want: []exchanges.FundingRate{
+				{Symbol: "BTCUSDT", Rate: 0.0001, Volume24h: 1500.5, VolumeUSDT24h: 90030000.5, PredictedRate: 0.00012, MarkPrice: 60000.5, IndexPrice: 60000, FundingInterval: 8 * time.Hour},
+				// Инверсный контракт: оборот в BTC, объем в контрактах по 1 USD
+				{Symbol: "BTCUSD", Rate: 0.0001, Volume24h: 1500.5, VolumeUSDT24h: 90030000, PredictedRate: -0.00005, MarkPrice: 60000.5, IndexPrice: 60000, FundingInterval: 8 * time.Hour, SettleAsset: "BTC", MarginType: exchanges.MarginInverse},
+			},
+			dynamicNextFunding: true,
+		},
+		{
+			name:     "WOO X",
+			exchange: &exchanges.WOOX{},
+			fixtures: "woox/ok",
+			want: []exchanges.FundingRate{
+				{Symbol: "PERP_BTC_USDT", Rate: 0.0001, NextFunding: "2026-01-01T08:00:00Z", Volume24h: 1500.5, VolumeUSDT24h: 90030000.5, PredictedRate: 0.00012, MarkPrice: 60000.5, IndexPrice: 60000, FundingInterval: 8 * time.Hour},
+				{Symbol: "PERP_ETH_USDT", Rate: -0.00005, NextFunding: "2026-01-01T04:00:00Z", PredictedRate: -0.0001, FundingInterval: 4 * time.Hour},
+			},
+		},
+		{
+			name:     "Aevo",
+			exchange: &exchanges.Aevo{},
+			fixtures: "aevo/ok",
+			want: []exchanges.FundingRate{
+				{Symbol: "BTC-PERP", Rate: 0.0000125, NextFunding: "2026-01-01T08:00:00Z", Volume24h: 1500.5, VolumeUSDT24h: 90030000.5, MarkPrice: 60000.5, IndexPrice: 60000, FundingInterval: time.Hour, OpenInterest: 1200.5, SettleAsset: "USDC"},
+			},
+		},
+		{
+			name:     "Paradex",
+			exchange: &exchanges.Paradex{},
+			fixtures: "paradex/ok",
+			want: []exchanges.FundingRate{
+				{Symbol: "BTC-USD-PERP", Rate: 0.0008, Volume24h: 1500, VolumeUSDT24h: 90000000, MarkPrice: 60000, IndexPrice: 59990, FundingInterval: 8 * time.Hour, OpenInterest: 1200.5, SettleAsset: "USDC"},
+			},
+			dynamicNextFunding: true,
+		},
+		{
+			name:     "Bitfinex",
+			exchange: &exchanges.Bitfinex{},
+			fixtures: "bitfinex/ok",
+			want: []exchanges.FundingRate{
+				{Symbol: "tBTCF0:USTF0", Rate: 0.0001, NextFunding: "2026-01-01T08:00:00Z", Volume24h: 1500.5, VolumeUSDT24h: 90030000, PredictedRate: 0.00012, MarkPrice: 60000.2, IndexPrice: 60000, FundingInterval: 8 * time.Hour, OpenInterest: 1200.5, SettleAsset: "USDT"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixtures, err := exchangetest.LoadFixtures(filepath.Join("testdata", "fixtures", tt.fixtures))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(fixtures) == 0 {
+				t.Fatalf("нет фикстур в %s", tt.fixtures)
+			}
+
+			server := exchangetest.NewReplayServer(fixtures...)
+			defer server.Close()
+			exchanges.SetHTTPClient(server.Client())
+			defer exchanges.SetHTTPClient(nil)
+
+			start := time.Now()
+			got, err := tt.exchange.GetFundingRates()
+
+			if tt.wantErr != "" {
+				if err == nil {
+					t.Fatalf("ожидалась ошибка %q, получено %d ставок", tt.wantErr, len(got))
+				}
+				if !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ошибка %q не содержит %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("неожиданная ошибка: %v", err)
+			}
+			if unmatched := server.Unmatched(); len(unmatched) > 0 {
+				t.Errorf("запросы без фикстур: %v", unmatched)
+			}
+
+			if tt.dynamicNextFunding {
+				for i := range got {
+					checkNextFundingAhead(t, got[i], start)
+					got[i].NextFunding = ""
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ставки не совпадают\nполучено: %+v\nожидалось: %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// checkNextFundingAhead проверяет, что вычисленное время фандинга лежит в пределах одного интервала
+// после начала запроса
+func checkNextFundingAhead(t *testing.T, rate exchanges.FundingRate, start time.Time) {
+	t.Helper()
+
+	next, err := time.Parse(time.RFC3339, rate.NextFunding)
+	if err != nil {
+		t.Errorf("%s: некорректное время фандинга %q: %v", rate.Symbol, rate.NextFunding, err)
+		return
+	}
+
+	interval := rate.Interval()
+	if next.Before(start.Truncate(time.Second)) || next.After(start.Add(interval)) {
+		t.Errorf("%s: время фандинга %s вне интервала (%s, %s]", rate.Symbol, next, start.Format(time.RFC3339), start.Add(interval).Format(time.RFC3339))
+	}
+}
+
+func TestHTXFundingIntervalFromFundingTime(t *testing.T) {
+	htx := &exchanges.HTX{}
+	intervals := func(scenario string) map[string]time.Duration {
+		t.Helper()
+
+		replayFixtures(t, scenario, "", "")
+		rates, err := htx.GetFundingRates()
+		if err != nil {
+			t.Fatal(err)
+		}
+		result := make(map[string]time.Duration, len(rates))
+		for _, rate := range rates {
+			result[rate.Symbol] = rate.FundingInterval
+		}
+		return result
+	}
+
+	// Первый опрос: HTX не сообщает интервал
+	if got := intervals("htx/ok"); got["BTC"] != 0 || got["ETH"] != 0 {
+		t.Fatalf("интервалы до сдвига funding_time: %v", got)
+	}
+	// После выплаты funding_time сдвигается на интервал контракта
+	got := intervals("htx/next")
+	if got["BTC"] != 8*time.Hour || got["ETH"] != 4*time.Hour {
+		t.Errorf("интервалы %v, ожидалось BTC 8h, ETH 4h", got)
+	}
+}
@@ -1,18 +1,24 @@
 package exchanges
 
 import (
+	"crypto/sha512"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 )
 
-type Gate struct{}
+type Gate struct {
+	Credentials CredentialProvider // Ключи для приватных запросов, по умолчанию из переменных окружения GATE_*
+}
 
 func NewGate() *Gate {
 	log.Println("Инициализация Gate.io")
-	return &Gate{}
+	return &Gate{Credentials: EnvCredentials{Prefix: "GATE"}}
 }
 
 func (g *Gate) GetName() string {
@@ -21,7 +27,7 @@ func (g *Gate) GetName() string {
 
 func (g *Gate) GetFundingRates() ([]FundingRate, error) {
 	log.Println("Запрос ставок фандинга с Gate.io")
-	resp, err := http.Get("https://api.gateio.ws/api/v4/futures/usdt/contracts")
+	resp, err := httpGet("https://api.gateio.ws/api/v4/futures/usdt/contracts")
 	if err != nil {
 		log.Printf("Ошибка запроса к Gate.io: %v", err)
 		return nil, err
@@ -29,24 +35,33 @@ func (g *Gate) GetFundingRates() ([]FundingRate, error) {
 	defer resp.Body.Close()
 
 	var contracts []struct {
-		Name        string  `json:"name"`
-		FundingRate string  `json:"funding_rate"`
-		FundingTime int64   `json:"funding_next_apply"`
-		TradeSize   float64 `json:"trade_size"`
+		Name            string `json:"name"`
+		FundingRate     string `json:"funding_rate"`
+		MarkPrice       string `json:"mark_price"`
+		IndexPrice      string `json:"index_price"`
+		FundingTime     int64  `json:"funding_next_apply"`
+		FundingInterval int64  `json:"funding_interval"` // в секундах
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&contracts); err != nil {
+	if err := decodeResponse(g.GetName(), resp, &contracts); err != nil {
 		log.Printf("Ошибка декодирования ответа от Gate.io: %v", err)
 		return nil, err
 	}
 
+	// Список контрактов содержит только накопленный объем за все время, объемы за 24 часа — в тикерах
+	volumes, err := g.getVolumes()
+	if err != nil {
+		log.Printf("Ошибка получения объемов Gate.io: %v", err)
+		volumes = make(map[string]gateVolume)
+	}
+
 	result := make([]FundingRate, 0)
 	for _, contract := range contracts {
 		if contract.FundingRate == "" {
 			continue
 		}
-		fundingRate := 0.0
-		if _, err := fmt.Sscanf(contract.FundingRate, "%f", &fundingRate); err != nil {
+		fundingRate, err := ParseDecimal(contract.FundingRate)
+		if err != nil {
 			log.Printf("Ошибка конвертации ставки %s: %v", contract.FundingRate, err)
 			continue
 		}
@@ -55,15 +70,169 @@ func (g *Gate) GetFundingRates() ([]FundingRate, error) {
 		location := GetLocationFromEnv()
 		nextFunding := time.Unix(contract.FundingTime, 0).In(location).Format(time.RFC3339)
 
+		volume := volumes[contract.Name]
 		result = append(result, FundingRate{
-			Symbol:        contract.Name,
-			Rate:          fundingRate,
-			NextFunding:   nextFunding,
-			Volume24h:     contract.TradeSize,
-			VolumeUSDT24h: 0,
+			Symbol:          contract.Name,
+			Rate:            fundingRate.Float64(),
+			NextFunding:     nextFunding,
+			Volume24h:       volume.Base.Float64(),
+			VolumeUSDT24h:   volume.Quote.Float64(),
+			MarkPrice:       parseFloatFromString(contract.MarkPrice),
+			IndexPrice:      parseFloatFromString(contract.IndexPrice),
+			FundingInterval: time.Duration(contract.FundingInterval) * time.Second,
+			Precise:         newPrecise(fundingRate, Decimal{}, volume.Base, volume.Quote),
 		})
 	}
 
 	log.Printf("Получено %d ставок фандинга с Gate.io", len(result))
 	return result, nil
 }
+
+// gateVolume объемы контракта за 24 часа
+type gateVolume struct {
+	Base  Decimal // в базовой валюте
+	Quote Decimal // в USDT
+}
+
+// getVolumes получает объемы USDT-контрактов за 24 часа из тикеров
+func (g *Gate) getVolumes() (map[string]gateVolume, error) {
+	resp, err := httpGet("https://api.gateio.ws/api/v4/futures/usdt/tickers")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var tickers []struct {
+		Contract       string  `json:"contract"`
+		Volume24hBase  Decimal `json:"volume_24h_base"`
+		Volume24hQuote Decimal `json:"volume_24h_quote"`
+	}
+	if err := decodeResponse(g.GetName(), resp, &tickers); err != nil {
+		return nil, err
+	}
+
+	volumes := make(map[string]gateVolume, len(tickers))
+	for _, ticker := range tickers {
+		volumes[ticker.Contract] = gateVolume{Base: ticker.Volume24hBase, Quote: ticker.Volume24hQuote}
+	}
+	return volumes, nil
+}
+
+// GetSpotPrices возвращает последние цены спотовых пар Gate.io по символам USDT-контрактов
+func (g *Gate) GetSpotPrices() (map[string]float64, error) {
+	resp, err := httpGet("https://api.gateio.ws/api/v4/spot/tickers")
+	if err != nil {
+		log.Printf("Ошибка запроса спотовых цен Gate.io: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var tickers []struct {
+		CurrencyPair string `json:"currency_pair"`
+		Last         string `json:"last"`
+	}
+	if err := decodeResponse(g.GetName(), resp, &tickers); err != nil {
+		log.Printf("Ошибка декодирования спотовых цен Gate.io: %v", err)
+		return nil, err
+	}
+
+	// Спотовая пара и USDT-контракт Gate.io называются одинаково, например BTC_USDT
+	prices := make(map[string]float64, len(tickers))
+	for _, ticker := range tickers {
+		if price := parseFloatFromString(ticker.Last); price > 0 {
+			prices[ticker.CurrencyPair] = price
+		}
+	}
+	return prices, nil
+}
+
+// GetPositions возвращает открытые позиции по USDT-контрактам
+func (g *Gate) GetPositions() ([]Position, error) {
+	credentials, err := resolveCredentials(g.GetName(), g.Credentials, false)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("holding", "true")
+
+	var positions []struct {
+		Contract           string `json:"contract"`
+		Size               int64  `json:"size"` // в контрактах, со знаком
+		Leverage           string `json:"leverage"`
+		CrossLeverageLimit string `json:"cross_leverage_limit"`
+		EntryPrice         string `json:"entry_price"`
+		MarkPrice          string `json:"mark_price"`
+		Value              string `json:"value"` // стоимость по маркировочной цене в USDT
+		UnrealisedPnl      string `json:"unrealised_pnl"`
+	}
+	if err := g.signedGet(credentials, "/api/v4/futures/usdt/positions", params, &positions); err != nil {
+		return nil, err
+	}
+
+	var result []Position
+	for _, item := range positions {
+		markPrice := parseFloatFromString(item.MarkPrice)
+		if item.Size == 0 || markPrice == 0 {
+			continue
+		}
+
+		// Размер контракта зависит от инструмента, поэтому размер в монете получаем из стоимости
+		value := parseFloatFromString(item.Value)
+		size := value / markPrice
+		if item.Size < 0 {
+			size = -size
+		}
+
+		// Нулевое плечо означает кросс-маржу с ограничением cross_leverage_limit
+		leverage := parseFloatFromString(item.Leverage)
+		if leverage == 0 {
+			leverage = parseFloatFromString(item.CrossLeverageLimit)
+		}
+
+		position := newPosition(g.GetName(), item.Contract, size, value)
+		position.EntryPrice = parseFloatFromString(item.EntryPrice)
+		position.MarkPrice = markPrice
+		position.Leverage = leverage
+		position.UnrealizedPnL = parseFloatFromString(item.UnrealisedPnl)
+		result = append(result, position)
+	}
+
+	log.Printf("Получено %d позиций с Gate.io", len(result))
+	return result, nil
+}
+
+// signedGet выполняет подписанный (APIv4, HMAC-SHA512) GET-запрос и декодирует ответ в out
+func (g *Gate) signedGet(credentials Credentials, path string, params url.Values, out interface{}) error {
+	query := params.Encode()
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	bodyHash := sha512.Sum512(nil)
+	signature := signHMACSHA512Hex(credentials.SecretKey,
+		"GET\n"+path+"\n"+query+"\n"+hex.EncodeToString(bodyHash[:])+"\n"+timestamp)
+
+	req, err := http.NewRequest("GET", "https://api.gateio.ws"+path+"?"+query, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("KEY", credentials.APIKey)
+	req.Header.Set("Timestamp", timestamp)
+	req.Header.Set("SIGN", signature)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Label   string `json:"label"`
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiErr)
+		return fmt.Errorf("Gate.io API ошибка: HTTP %d, %s - %s", resp.StatusCode, apiErr.Label, apiErr.Message)
+	}
+
+	return decodeResponse(g.GetName(), resp, out)
+}
@@ -0,0 +1,39 @@
+package exchanges
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// currentHTTPClient клиент для запросов к биржам, см. SetHTTPClient
+var currentHTTPClient atomic.Pointer[http.Client]
+
+// SetHTTPClient задает HTTP-клиент для всех запросов к биржам: прокси, общий таймаут,
+// запись и воспроизведение ответов в тестах. nil возвращает http.DefaultClient
+func SetHTTPClient(client *http.Client) {
+	currentHTTPClient.Store(client)
+}
+
+// httpClient возвращает клиент для запросов к биржам
+func httpClient() *http.Client {
+	if client := currentHTTPClient.Load(); client != nil {
+		return client
+	}
+	return http.DefaultClient
+}
+
+// httpClientWithTimeout возвращает клиент для запросов к биржам с таймаутом,
+// если у заданного клиента собственный таймаут не установлен
+func httpClientWithTimeout(timeout time.Duration) *http.Client {
+	client := *httpClient()
+	if client.Timeout == 0 {
+		client.Timeout = timeout
+	}
+	return &client
+}
+
+// httpGet выполняет GET-запрос клиентом для запросов к биржам
+func httpGet(url string) (*http.Response, error) {
+	return httpClient().Get(url)
+}
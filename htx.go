@@ -1,15 +1,26 @@
 package exchanges
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
-	"net/http"
 	"strconv"
+	"sync"
 	"time"
 )
 
-type HTX struct{}
+// HTX не публикует интервал фандинга: он вычисляется по сдвигу funding_time между опросами,
+// поэтому экземпляр хранит наблюдения и должен переиспользоваться
+type HTX struct {
+	mu        sync.Mutex
+	observed  map[string]htxObservation // символ -> последнее наблюдение времени выплаты
+	intervals map[string]time.Duration  // символ -> вычисленный интервал
+}
+
+// htxObservation время выплаты, которое биржа сообщала при последнем опросе
+type htxObservation struct {
+	fundingTime int64 // в миллисекундах
+	seenAt      time.Time
+}
 
 func NewHTX() *HTX {
 	log.Println("Инициализация HTX")
@@ -31,27 +42,25 @@ type htxVolumeResponse struct {
 }
 
 // Получаем объемы для всех пар
-func (h *HTX) getVolumes() (map[string]float64, map[string]float64, error) {
-	resp, err := http.Get("https://api.hbdm.com/linear-swap-ex/market/detail/batch_merged")
+func (h *HTX) getVolumes() (map[string]Decimal, map[string]Decimal, error) {
+	resp, err := httpGet("https://api.hbdm.com/linear-swap-ex/market/detail/batch_merged")
 	if err != nil {
 		return nil, nil, err
 	}
 	defer resp.Body.Close()
 
 	var volumeData htxVolumeResponse
-	if err := json.NewDecoder(resp.Body).Decode(&volumeData); err != nil {
+	if err := decodeResponse(h.GetName(), resp, &volumeData); err != nil {
 		return nil, nil, err
 	}
 
-	volumes := make(map[string]float64)
-	volumesUSDT := make(map[string]float64)
+	volumes := make(map[string]Decimal)
+	volumesUSDT := make(map[string]Decimal)
 
 	for _, item := range volumeData.Ticks {
 		// Используем символ как есть из contract_code
-		vol, _ := strconv.ParseFloat(item.Vol, 64)
-		turnover, _ := strconv.ParseFloat(item.TradeTurnover, 64)
-		volumes[item.ContractCode] = vol
-		volumesUSDT[item.ContractCode] = turnover
+		volumes[item.ContractCode] = parseDecimal(item.Vol)
+		volumesUSDT[item.ContractCode] = parseDecimal(item.TradeTurnover)
 	}
 
 	return volumes, volumesUSDT, nil
@@ -65,12 +74,12 @@ func (h *HTX) GetFundingRates() ([]FundingRate, error) {
 	if err != nil {
 		log.Printf("Ошибка получения объемов HTX: %v", err)
 		// Продолжаем работу без объемов
-		volumes = make(map[string]float64)
-		volumesUSDT = make(map[string]float64)
+		volumes = make(map[string]Decimal)
+		volumesUSDT = make(map[string]Decimal)
 	}
 
 	// Получаем фандинг ставки
-	resp, err := http.Get("https://api.hbdm.com/linear-swap-api/v1/swap_batch_funding_rate")
+	resp, err := httpGet("https://api.hbdm.com/linear-swap-api/v1/swap_batch_funding_rate")
 	if err != nil {
 		log.Printf("Ошибка запроса к HTX: %v", err)
 		return nil, err
@@ -80,13 +89,14 @@ func (h *HTX) GetFundingRates() ([]FundingRate, error) {
 	var response struct {
 		Status string `json:"status"`
 		Data   []struct {
-			Symbol      string  `json:"symbol"`
-			FundingRate float64 `json:"funding_rate,string"`
-			FundingTime string  `json:"funding_time"`
+			Symbol          string  `json:"symbol"`
+			FundingRate     Decimal `json:"funding_rate"`
+			FundingTime     string  `json:"funding_time"`
+			NextFundingTime string  `json:"next_funding_time"` // null в текущей версии API
 		} `json:"data"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+	if err := decodeResponse(h.GetName(), resp, &response); err != nil {
 		log.Printf("Ошибка декодирования ответа HTX: %v", err)
 		return nil, err
 	}
@@ -95,6 +105,7 @@ func (h *HTX) GetFundingRates() ([]FundingRate, error) {
 		return nil, fmt.Errorf("HTX API ошибка: %s", response.Status)
 	}
 
+	now := time.Now()
 	var result []FundingRate
 	for _, rate := range response.Data {
 		fundingTime, err := strconv.ParseInt(rate.FundingTime, 10, 64)
@@ -104,20 +115,55 @@ func (h *HTX) GetFundingRates() ([]FundingRate, error) {
 
 		nextFunding := time.Unix(fundingTime/1000, 0).Format(time.RFC3339)
 
+		// Интервал известен, если биржа прислала следующую выплату или funding_time уже сдвигался
+		// между опросами. Иначе поле остается нулевым и Rate8h, APR и APY предполагают
+		// DefaultFundingInterval (8 часов)
+		interval := h.observeFundingTime(rate.Symbol, fundingTime, now)
+		if next, err := strconv.ParseInt(rate.NextFundingTime, 10, 64); err == nil && next > fundingTime {
+			interval = time.Duration(next-fundingTime) * time.Millisecond
+		}
+
 		// Получаем объемы для данного символа, добавляем -USDT если нужно
 		symbolKey := rate.Symbol + "-USDT"
 		volume24h := volumes[symbolKey]
 		volumeUSDT24h := volumesUSDT[symbolKey]
 
 		result = append(result, FundingRate{
-			Symbol:        rate.Symbol,
-			Rate:          rate.FundingRate,
-			NextFunding:   nextFunding,
-			Volume24h:     volume24h,
-			VolumeUSDT24h: volumeUSDT24h,
+			Symbol:          rate.Symbol,
+			Rate:            rate.FundingRate.Float64(),
+			NextFunding:     nextFunding,
+			Volume24h:       volume24h.Float64(),
+			VolumeUSDT24h:   volumeUSDT24h.Float64(),
+			FundingInterval: interval,
+			Precise:         newPrecise(rate.FundingRate, Decimal{}, volume24h, volumeUSDT24h),
 		})
 	}
 
 	log.Printf("Получено %d ставок фандинга с HTX", len(result))
 	return result, nil
 }
+
+// observeFundingTime запоминает время выплаты символа и возвращает интервал фандинга, вычисленный
+// по сдвигу funding_time, или 0, если сдвиг еще не наблюдался
+func (h *HTX) observeFundingTime(symbol string, fundingTime int64, now time.Time) time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.observed == nil {
+		h.observed = make(map[string]htxObservation)
+		h.intervals = make(map[string]time.Duration)
+	}
+
+	prev, ok := h.observed[symbol]
+	if ok && fundingTime > prev.fundingTime {
+		spacing := time.Duration(fundingTime-prev.fundingTime) * time.Millisecond
+		// Если с прошлого опроса прошло больше половины сдвига, могла быть пропущена выплата,
+		// и сдвиг кратен интервалу: такое наблюдение не используется
+		if now.Sub(prev.seenAt) <= spacing/2 {
+			h.intervals[symbol] = spacing
+		}
+	}
+	h.observed[symbol] = htxObservation{fundingTime: fundingTime, seenAt: now}
+
+	return h.intervals[symbol]
+}
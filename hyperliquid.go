@@ -5,20 +5,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"strconv"
 	"time"
 )
 
 type Hyperliquid struct {
-	client *http.Client
+	Wallet string // wallet address for GetPositions, no API key needed
 }
 
+// hyperliquidTimeout request timeout unless the shared client sets its own
+const hyperliquidTimeout = 15 * time.Second
+
+// hyperliquidMetaEndpoint endpoint name for schema checks: every info request shares the /info path
+const hyperliquidMetaEndpoint = "/info metaAndAssetCtxs"
+
 func NewHyperliquid() *Hyperliquid {
-	return &Hyperliquid{
-		client: &http.Client{
-			Timeout: 15 * time.Second,
-		},
-	}
+	return &Hyperliquid{}
 }
 
 func (h *Hyperliquid) GetName() string {
@@ -68,7 +69,7 @@ func (h *Hyperliquid) GetFundingRates() ([]FundingRate, error) {
 
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := h.client.Do(req)
+	resp, err := httpClientWithTimeout(hyperliquidTimeout).Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request: %v", err)
 	}
@@ -96,7 +97,7 @@ func (h *Hyperliquid) GetFundingRates() ([]FundingRate, error) {
 	var meta struct {
 		Universe []HyperliquidUniverse `json:"universe"`
 	}
-	if err := json.Unmarshal(metaBytes, &meta); err != nil {
+	if err := decodeChecked(h.GetName(), hyperliquidMetaEndpoint, "[0]", metaBytes, &meta); err != nil {
 		return nil, fmt.Errorf("failed to decode metadata: %v", err)
 	}
 
@@ -107,7 +108,7 @@ func (h *Hyperliquid) GetFundingRates() ([]FundingRate, error) {
 	}
 
 	var assetCtxs []HyperliquidAssetContext
-	if err := json.Unmarshal(assetCtxsBytes, &assetCtxs); err != nil {
+	if err := decodeChecked(h.GetName(), hyperliquidMetaEndpoint, "[1]", assetCtxsBytes, &assetCtxs); err != nil {
 		return nil, fmt.Errorf("failed to decode asset contexts: %v", err)
 	}
 
@@ -126,22 +127,28 @@ func (h *Hyperliquid) GetFundingRates() ([]FundingRate, error) {
 		}
 
 		// Parse funding rate
-		fundingRate, err := strconv.ParseFloat(ctx.Funding, 64)
+		fundingRate, err := ParseDecimal(ctx.Funding)
 		if err != nil {
 			continue
 		}
 
-		// Parse 24h volume
-		volume24h, err := strconv.ParseFloat(ctx.DayNtlVlm, 64)
+		// Parse 24h notional volume, it is already in USDC
+		volumeUSD, err := ParseDecimal(ctx.DayNtlVlm)
 		if err != nil {
-			volume24h = 0
+			volumeUSD = Decimal{}
 		}
 
 		// Filter out low volume symbols (less than $1000)
-		if volume24h < 1000 {
+		if volumeUSD.Float64() < 1000 {
 			continue
 		}
 
+		// Base volume is derived from the notional volume and the mark price
+		var volume24h Decimal
+		if markPrice := parseDecimal(ctx.MarkPx); markPrice.Value.IsPositive() {
+			volume24h = derived(volumeUSD.Value.Div(markPrice.Value))
+		}
+
 		// Calculate next funding time (Hyperliquid funding is every hour)
 		now := time.Now()
 		location := GetLocationFromEnv()
@@ -149,13 +156,83 @@ func (h *Hyperliquid) GetFundingRates() ([]FundingRate, error) {
 		nextFundingString := nextHour.In(location).Format(time.RFC3339)
 
 		fundingRates = append(fundingRates, FundingRate{
-			Symbol:        asset.Name,
-			Rate:          fundingRate,
-			NextFunding:   nextFundingString,
-			Volume24h:     volume24h,
-			VolumeUSDT24h: volume24h, // On Hyperliquid, volume is already in USDT/USD
+			Symbol:          asset.Name,
+			Rate:            fundingRate.Float64(),
+			NextFunding:     nextFundingString,
+			Volume24h:       volume24h.Float64(),
+			VolumeUSDT24h:   volumeUSD.Float64(),
+			FundingInterval: time.Hour,
+			SettleAsset:     "USDC",
+			Precise:         newPrecise(fundingRate, Decimal{}, volume24h, volumeUSD),
 		})
 	}
 
 	return fundingRates, nil
 }
+
+type HyperliquidUserStateRequest struct {
+	Type string `json:"type"`
+	User string `json:"user"`
+}
+
+type HyperliquidAssetPosition struct {
+	Position struct {
+		Coin          string `json:"coin"`
+		Szi           string `json:"szi"` // signed size: negative for short
+		EntryPx       string `json:"entryPx"`
+		PositionValue string `json:"positionValue"`
+		UnrealizedPnl string `json:"unrealizedPnl"`
+		Leverage      struct {
+			Type  string  `json:"type"`
+			Value float64 `json:"value"`
+		} `json:"leverage"`
+	} `json:"position"`
+}
+
+// GetPositions returns open positions of the configured wallet
+func (h *Hyperliquid) GetPositions() ([]Position, error) {
+	if h.Wallet == "" {
+		return nil, fmt.Errorf("Hyperliquid: wallet address is not set")
+	}
+
+	reqBytes, err := json.Marshal(HyperliquidUserStateRequest{Type: "clearinghouseState", User: h.Wallet})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %v", err)
+	}
+
+	resp, err := httpClientWithTimeout(hyperliquidTimeout).Post("https://api.hyperliquid.xyz/info", "application/json", bytes.NewBuffer(reqBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned status code: %d", resp.StatusCode)
+	}
+
+	var state struct {
+		AssetPositions []HyperliquidAssetPosition `json:"assetPositions"`
+	}
+	if err := decodeResponse(h.GetName(), resp, &state); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+
+	var positions []Position
+	for _, item := range state.AssetPositions {
+		size := parseFloatFromString(item.Position.Szi)
+		if size == 0 {
+			continue
+		}
+
+		// Mark price is not returned, derive it from position value
+		value := parseFloatFromString(item.Position.PositionValue)
+		position := newPosition(h.GetName(), item.Position.Coin, size, value)
+		position.EntryPrice = parseFloatFromString(item.Position.EntryPx)
+		position.MarkPrice = value / position.Size
+		position.Leverage = item.Position.Leverage.Value
+		position.UnrealizedPnL = parseFloatFromString(item.Position.UnrealizedPnl)
+		positions = append(positions, position)
+	}
+
+	return positions, nil
+}
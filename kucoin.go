@@ -1,10 +1,8 @@
 package exchanges
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
-	"net/http"
 	"time"
 )
 
@@ -26,19 +24,19 @@ type kuCoinStatsResponse struct {
 		Symbol   string  `json:"symbol"`
 		Vol      float64 `json:"vol"`
 		Turnover float64 `json:"turnover"`
-	} `json:"data"`
+	} `json:"data,omitempty"` // в ответе с ошибкой отсутствует
 }
 
 // Получаем объемы для всех пар
 func (k *KuCoin) getVolumes() (map[string]float64, map[string]float64, error) {
-	resp, err := http.Get("https://api-futures.kucoin.com/api/v1/contracts/stats")
+	resp, err := httpGet("https://api-futures.kucoin.com/api/v1/contracts/stats")
 	if err != nil {
 		return nil, nil, err
 	}
 	defer resp.Body.Close()
 
 	var statsData kuCoinStatsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&statsData); err != nil {
+	if err := decodeResponse(k.GetName(), resp, &statsData); err != nil {
 		return nil, nil, err
 	}
 
@@ -63,7 +61,7 @@ func (k *KuCoin) GetFundingRates() ([]FundingRate, error) {
 	// Объемы теперь получаем из API контрактов напрямую
 
 	// Получаем контракты
-	contractsResp, err := http.Get("https://api-futures.kucoin.com/api/v1/contracts/active")
+	contractsResp, err := httpGet("https://api-futures.kucoin.com/api/v1/contracts/active")
 	if err != nil {
 		log.Printf("Ошибка запроса контрактов KuCoin: %v", err)
 		return nil, err
@@ -73,14 +71,16 @@ func (k *KuCoin) GetFundingRates() ([]FundingRate, error) {
 	var contractsResponse struct {
 		Code string `json:"code"`
 		Data []struct {
-			Symbol        string  `json:"symbol"`
-			FundingRate   float64 `json:"fundingFeeRate"`
-			VolumeOf24h   float64 `json:"volumeOf24h"`
-			TurnoverOf24h float64 `json:"turnoverOf24h"`
-		} `json:"data"`
+			Symbol                 string  `json:"symbol"`
+			FundingRate            Decimal `json:"fundingFeeRate"`
+			FundingRateGranularity int64   `json:"fundingRateGranularity"` // интервал фандинга в мс
+			NextFundingRateTime    int64   `json:"nextFundingRateTime"`    // мс до следующей выплаты
+			VolumeOf24h            Decimal `json:"volumeOf24h"`
+			TurnoverOf24h          Decimal `json:"turnoverOf24h"`
+		} `json:"data,omitempty"` // в ответе с ошибкой отсутствует
 	}
 
-	if err := json.NewDecoder(contractsResp.Body).Decode(&contractsResponse); err != nil {
+	if err := decodeResponse(k.GetName(), contractsResp, &contractsResponse); err != nil {
 		log.Printf("Ошибка декодирования контрактов KuCoin: %v", err)
 		return nil, err
 	}
@@ -93,18 +93,26 @@ func (k *KuCoin) GetFundingRates() ([]FundingRate, error) {
 	for _, contract := range contractsResponse.Data {
 		fundingRate := contract.FundingRate
 
-		nextFunding := time.Now().Add(time.Hour).Format(time.RFC3339)
+		// KuCoin сообщает время до выплаты; выплаты приходятся на начало минуты,
+		// поэтому округление убирает задержку ответа. Без времени поле остается пустым
+		var nextFunding string
+		if contract.NextFundingRateTime > 0 {
+			nextFunding = time.Now().Add(time.Duration(contract.NextFundingRateTime) * time.Millisecond).
+				Round(time.Minute).Format(time.RFC3339)
+		}
 
 		// Используем объемы из API контрактов
 		volume24h := contract.VolumeOf24h
 		volumeUSDT24h := contract.TurnoverOf24h
 
 		result = append(result, FundingRate{
-			Symbol:        contract.Symbol,
-			Rate:          fundingRate,
-			NextFunding:   nextFunding,
-			Volume24h:     volume24h,
-			VolumeUSDT24h: volumeUSDT24h,
+			Symbol:          contract.Symbol,
+			Rate:            fundingRate.Float64(),
+			NextFunding:     nextFunding,
+			Volume24h:       volume24h.Float64(),
+			VolumeUSDT24h:   volumeUSDT24h.Float64(),
+			FundingInterval: time.Duration(contract.FundingRateGranularity) * time.Millisecond,
+			Precise:         newPrecise(fundingRate, Decimal{}, volume24h, volumeUSDT24h),
 		})
 	}
 
@@ -0,0 +1,174 @@
+package exchanges
+
+import (
+	"sort"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MetricsOptions настройки экспорта метрик
+type MetricsOptions struct {
+	Namespace             string  // префикс имен метрик
+	MinVolumeUSDT         float64 // символы с меньшим объемом не экспортируются
+	MaxSymbolsPerExchange int     // максимум символов биржи (по убыванию объема), 0 — без ограничения
+}
+
+// MetricsCollector экспортирует в Prometheus ставки из кэша и состояние их обновлений.
+// Число серий по символам ограничивается порогом объема и лимитом на биржу
+type MetricsCollector struct {
+	cache   *RatesCache
+	options MetricsOptions
+
+	rate          *prometheus.Desc
+	volume        *prometheus.Desc
+	nextFunding   *prometheus.Desc
+	fetchDuration *prometheus.Desc
+	fetchErrors   *prometheus.Desc
+	fetchUpdates  *prometheus.Desc
+	rows          *prometheus.Desc
+	cacheAge      *prometheus.Desc
+	skipped       *prometheus.Desc
+
+	schemaResponses *prometheus.Desc
+	schemaDrifts    *prometheus.Desc
+	schemaNotSeen   *prometheus.Desc
+
+	validationIssues  *prometheus.Desc
+	validationDropped *prometheus.Desc
+}
+
+// NewMetricsCollector создает коллектор; его нужно зарегистрировать через prometheus.MustRegister
+func NewMetricsCollector(cache *RatesCache, options MetricsOptions) *MetricsCollector {
+	name := func(metric string) string {
+		return prometheus.BuildFQName(options.Namespace, "", metric)
+	}
+	symbolLabels := []string{"exchange", "symbol"}
+	exchangeLabels := []string{"exchange"}
+
+	return &MetricsCollector{
+		cache:   cache,
+		options: options,
+
+		rate: prometheus.NewDesc(name("funding_rate"),
+			"Текущая ставка фандинга", symbolLabels, nil),
+		volume: prometheus.NewDesc(name("funding_volume_usdt_24h"),
+			"Объем торгов за 24 часа в USDT", symbolLabels, nil),
+		nextFunding: prometheus.NewDesc(name("funding_next_seconds"),
+			"Секунд до следующей выплаты фандинга", symbolLabels, nil),
+		fetchDuration: prometheus.NewDesc(name("funding_fetch_duration_seconds"),
+			"Длительность последнего запроса ставок биржи", exchangeLabels, nil),
+		fetchErrors: prometheus.NewDesc(name("funding_fetch_errors_total"),
+			"Число неудачных запросов ставок биржи", exchangeLabels, nil),
+		fetchUpdates: prometheus.NewDesc(name("funding_fetch_updates_total"),
+			"Число успешных обновлений ставок биржи", exchangeLabels, nil),
+		rows: prometheus.NewDesc(name("funding_rows"),
+			"Число ставок в последнем снимке биржи", exchangeLabels, nil),
+		cacheAge: prometheus.NewDesc(name("funding_cache_age_seconds"),
+			"Секунд с последнего успешного обновления биржи", exchangeLabels, nil),
+		skipped: prometheus.NewDesc(name("funding_series_skipped"),
+			"Число символов, не экспортированных из-за ограничений кардинальности", exchangeLabels, nil),
+
+		schemaResponses: prometheus.NewDesc(name("funding_schema_responses_total"),
+			"Число ответов биржи, проверенных на соответствие схеме", exchangeLabels, nil),
+		schemaDrifts: prometheus.NewDesc(name("funding_schema_drifts"),
+			"Число различных расхождений ответов биржи со схемой", []string{"exchange", "kind"}, nil),
+		schemaNotSeen: prometheus.NewDesc(name("funding_schema_fields_not_seen"),
+			"Число ожидаемых полей, не встреченных ни в одном ответе биржи", exchangeLabels, nil),
+
+		validationIssues: prometheus.NewDesc(name("funding_validation_issues"),
+			"Число проблем, найденных проверкой последнего снимка биржи", []string{"exchange", "check"}, nil),
+		validationDropped: prometheus.NewDesc(name("funding_validation_dropped"),
+			"Число строк, удаленных проверкой из последнего снимка биржи", exchangeLabels, nil),
+	}
+}
+
+func (m *MetricsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- m.rate
+	ch <- m.volume
+	ch <- m.nextFunding
+	ch <- m.fetchDuration
+	ch <- m.fetchErrors
+	ch <- m.fetchUpdates
+	ch <- m.rows
+	ch <- m.cacheAge
+	ch <- m.skipped
+	ch <- m.schemaResponses
+	ch <- m.schemaDrifts
+	ch <- m.schemaNotSeen
+	ch <- m.validationIssues
+	ch <- m.validationDropped
+}
+
+func (m *MetricsCollector) Collect(ch chan<- prometheus.Metric) {
+	now := time.Now()
+
+	for _, status := range m.cache.GetStatus() {
+		ch <- prometheus.MustNewConstMetric(m.fetchDuration, prometheus.GaugeValue, status.LastDuration.Seconds(), status.Name)
+		ch <- prometheus.MustNewConstMetric(m.fetchErrors, prometheus.CounterValue, float64(status.Errors), status.Name)
+		ch <- prometheus.MustNewConstMetric(m.fetchUpdates, prometheus.CounterValue, float64(status.Updates), status.Name)
+		ch <- prometheus.MustNewConstMetric(m.rows, prometheus.GaugeValue, float64(status.Rows), status.Name)
+		if !status.LastUpdate.IsZero() {
+			ch <- prometheus.MustNewConstMetric(m.cacheAge, prometheus.GaugeValue, now.Sub(status.LastUpdate).Seconds(), status.Name)
+		}
+	}
+
+	for exchange, rates := range m.cache.GetAllRates() {
+		selected := m.selectRates(rates)
+		ch <- prometheus.MustNewConstMetric(m.skipped, prometheus.GaugeValue, float64(len(rates)-len(selected)), exchange)
+
+		// Одинаковые символы дали бы дубликаты серий, которые реестр отвергает
+		seen := make(map[string]bool, len(selected))
+		for _, rate := range selected {
+			if seen[rate.Symbol] {
+				continue
+			}
+			seen[rate.Symbol] = true
+
+			ch <- prometheus.MustNewConstMetric(m.rate, prometheus.GaugeValue, rate.Rate, exchange, rate.Symbol)
+			ch <- prometheus.MustNewConstMetric(m.volume, prometheus.GaugeValue, rate.VolumeUSDT24h, exchange, rate.Symbol)
+			if nextFunding, err := time.Parse(time.RFC3339, rate.NextFunding); err == nil {
+				ch <- prometheus.MustNewConstMetric(m.nextFunding, prometheus.GaugeValue, nextFunding.Sub(now).Seconds(), exchange, rate.Symbol)
+			}
+		}
+	}
+
+	// Отчеты о схеме есть только у бирж, для которых включена проверка
+	for exchange, report := range GetSchemaReports() {
+		ch <- prometheus.MustNewConstMetric(m.schemaResponses, prometheus.CounterValue, float64(report.Responses), exchange)
+		ch <- prometheus.MustNewConstMetric(m.schemaNotSeen, prometheus.GaugeValue, float64(len(report.NotSeen)), exchange)
+
+		kinds := map[DriftKind]int{DriftUnknownField: 0, DriftMissingField: 0, DriftTypeChange: 0}
+		for _, drift := range report.Drifts {
+			kinds[drift.Kind]++
+		}
+		for kind, count := range kinds {
+			ch <- prometheus.MustNewConstMetric(m.schemaDrifts, prometheus.GaugeValue, float64(count), exchange, string(kind))
+		}
+	}
+
+	for exchange, report := range m.cache.GetValidationReports() {
+		ch <- prometheus.MustNewConstMetric(m.validationDropped, prometheus.GaugeValue, float64(report.Dropped), exchange)
+		for _, check := range ValidationChecks {
+			ch <- prometheus.MustNewConstMetric(m.validationIssues, prometheus.GaugeValue, float64(report.Counts[check]), exchange, string(check))
+		}
+	}
+}
+
+// selectRates применяет ограничения кардинальности к ставкам биржи
+func (m *MetricsCollector) selectRates(rates []FundingRate) []FundingRate {
+	selected := make([]FundingRate, 0, len(rates))
+	for _, rate := range rates {
+		if rate.VolumeUSDT24h >= m.options.MinVolumeUSDT {
+			selected = append(selected, rate)
+		}
+	}
+
+	if limit := m.options.MaxSymbolsPerExchange; limit > 0 && len(selected) > limit {
+		sort.Slice(selected, func(i, j int) bool {
+			return selected[i].VolumeUSDT24h > selected[j].VolumeUSDT24h
+		})
+		selected = selected[:limit]
+	}
+	return selected
+}
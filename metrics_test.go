@@ -0,0 +1,130 @@
+package exchanges_test
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+	"github.com/petrixs/cr-exchanges/exchangetest"
+)
+
+// metricSeries метки и значение серии
+type metricSeries struct {
+	labels map[string]string
+	value  float64
+}
+
+// gatherSeries возвращает серии метрики name
+func gatherSeries(t *testing.T, collector prometheus.Collector, name string) []metricSeries {
+	t.Helper()
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector)
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var result []metricSeries
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			result = append(result, metricSeries{labels: labels, value: metric.GetGauge().GetValue()})
+		}
+	}
+	return result
+}
+
+// exportedSymbols возвращает символы серий funding_rate по биржам
+func exportedSymbols(t *testing.T, collector prometheus.Collector) map[string][]string {
+	t.Helper()
+
+	result := make(map[string][]string)
+	for _, series := range gatherSeries(t, collector, "funding_rate") {
+		exchange := series.labels["exchange"]
+		result[exchange] = append(result[exchange], series.labels["symbol"])
+	}
+	for _, symbols := range result {
+		sort.Strings(symbols)
+	}
+	return result
+}
+
+func TestMetricsCollectorVolumeFilter(t *testing.T) {
+	cache := newTestCache()
+	next := time.Now().Add(time.Hour).Format(time.RFC3339)
+	binance := &staticExchange{name: "Binance", rates: []exchanges.FundingRate{
+		{Symbol: "BTCUSDT", Rate: 0.0001, NextFunding: next, Volume24h: 100, VolumeUSDT24h: 5e6},
+		{Symbol: "ETHUSDT", Rate: 0.0001, NextFunding: next, Volume24h: 100, VolumeUSDT24h: 3e6},
+		{Symbol: "DOGEUSDT", Rate: 0.0001, NextFunding: next, Volume24h: 100, VolumeUSDT24h: 1000},
+	}}
+	if err := cache.UpdateRates(binance); err != nil {
+		t.Fatal(err)
+	}
+
+	// Объем Gate.io в USDT берется из тикеров контрактов
+	server := newMockServer(t)
+	server.SetRate(exchangetest.VenueGate, "BTC", exchangetest.MockRate{Rate: 0.0001, Volume: 100, Price: 50000})
+	server.SetRate(exchangetest.VenueGate, "DOGE", exchangetest.MockRate{Rate: 0.0001, Volume: 100, Price: 0.1})
+	if err := cache.UpdateRates(&exchanges.Gate{}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		options exchanges.MetricsOptions
+		want    map[string][]string
+	}{
+		{
+			name:    "Порог объема",
+			options: exchanges.MetricsOptions{MinVolumeUSDT: 1e6},
+			want:    map[string][]string{"Binance": {"BTCUSDT", "ETHUSDT"}, "Gate.io": {"BTC_USDT"}},
+		},
+		{
+			name:    "Лимит символов по убыванию объема",
+			options: exchanges.MetricsOptions{MaxSymbolsPerExchange: 1},
+			want:    map[string][]string{"Binance": {"BTCUSDT"}, "Gate.io": {"BTC_USDT"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := exchanges.NewMetricsCollector(cache, tt.options)
+			got := exportedSymbols(t, collector)
+			if len(got) != len(tt.want) {
+				t.Fatalf("серии %v, ожидалось %v", got, tt.want)
+			}
+			for exchange, want := range tt.want {
+				if len(got[exchange]) != len(want) {
+					t.Fatalf("%s: серии %v, ожидалось %v", exchange, got[exchange], want)
+				}
+				for i := range want {
+					if got[exchange][i] != want[i] {
+						t.Errorf("%s: серии %v, ожидалось %v", exchange, got[exchange], want)
+					}
+				}
+			}
+		})
+	}
+
+	// Число пропущенных символов экспортируется по каждой бирже
+	collector := exchanges.NewMetricsCollector(cache, exchanges.MetricsOptions{MinVolumeUSDT: 1e6})
+	if got := len(gatherSeries(t, collector, "funding_series_skipped")); got != 2 {
+		t.Errorf("серий пропущенных символов %d, ожидалось 2", got)
+	}
+	// Экспортируется тот же объем, по которому применяется порог
+	for _, series := range gatherSeries(t, collector, "funding_volume_usdt_24h") {
+		if series.value < 1e6 {
+			t.Errorf("%v: объем %v ниже порога", series.labels, series.value)
+		}
+	}
+}
@@ -1,10 +1,8 @@
 package exchanges
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
-	"net/http"
 	"time"
 )
 
@@ -23,9 +21,7 @@ func (m *MEXC) GetFundingRates() ([]FundingRate, error) {
 	log.Println("Запрос ставок фандинга с MEXC")
 
 	// Получаем тикеры для всех контрактов
-	client := &http.Client{
-		Timeout: 15 * time.Second,
-	}
+	client := httpClientWithTimeout(15 * time.Second)
 
 	tickerResp, err := client.Get("https://contract.mexc.com/api/v1/contract/ticker")
 	if err != nil {
@@ -38,13 +34,13 @@ func (m *MEXC) GetFundingRates() ([]FundingRate, error) {
 		Success bool `json:"success"`
 		Data    []struct {
 			Symbol      string  `json:"symbol"`
-			Volume24    float64 `json:"volume24"`
-			Amount24    float64 `json:"amount24"`
+			Volume24    Decimal `json:"volume24"`
+			Amount24    Decimal `json:"amount24"`
 			FundingRate float64 `json:"fundingRate"`
-		} `json:"data"`
+		} `json:"data,omitempty"` // в ответе с ошибкой отсутствует
 	}
 
-	if err := json.NewDecoder(tickerResp.Body).Decode(&tickerData); err != nil {
+	if err := decodeResponse(m.GetName(), tickerResp, &tickerData); err != nil {
 		log.Printf("Ошибка декодирования тикеров от MEXC: %v", err)
 		return nil, err
 	}
@@ -67,7 +63,7 @@ func (m *MEXC) GetFundingRates() ([]FundingRate, error) {
 		}
 
 		// Пропускаем символы с нулевым объемом
-		if ticker.Amount24 <= 1000 { // минимальный объем $1000
+		if ticker.Amount24.Float64() <= 1000 { // минимальный объем $1000
 			continue
 		}
 
@@ -84,12 +80,13 @@ func (m *MEXC) GetFundingRates() ([]FundingRate, error) {
 			Success bool `json:"success"`
 			Data    struct {
 				Symbol         string  `json:"symbol"`
-				FundingRate    float64 `json:"fundingRate"`
+				FundingRate    Decimal `json:"fundingRate"`
 				NextSettleTime int64   `json:"nextSettleTime"`
-			} `json:"data"`
+				CollectCycle   int64   `json:"collectCycle"` // интервал фандинга в часах
+			} `json:"data,omitempty"` // в ответе с ошибкой отсутствует
 		}
 
-		if err := json.NewDecoder(fundingResp.Body).Decode(&fundingData); err != nil {
+		if err := decodeResponse(m.GetName(), fundingResp, &fundingData); err != nil {
 			log.Printf("Ошибка декодирования фандинга для %s от MEXC: %v", ticker.Symbol, err)
 			fundingResp.Body.Close()
 			continue
@@ -102,11 +99,13 @@ func (m *MEXC) GetFundingRates() ([]FundingRate, error) {
 		}
 
 		result = append(result, FundingRate{
-			Symbol:        fundingData.Data.Symbol,
-			Rate:          fundingData.Data.FundingRate,
-			NextFunding:   time.Unix(fundingData.Data.NextSettleTime/1000, 0).In(location).Format(time.RFC3339),
-			Volume24h:     ticker.Volume24,
-			VolumeUSDT24h: ticker.Amount24,
+			Symbol:          fundingData.Data.Symbol,
+			Rate:            fundingData.Data.FundingRate.Float64(),
+			NextFunding:     time.Unix(fundingData.Data.NextSettleTime/1000, 0).In(location).Format(time.RFC3339),
+			Volume24h:       ticker.Volume24.Float64(),
+			VolumeUSDT24h:   ticker.Amount24.Float64(),
+			FundingInterval: time.Duration(fundingData.Data.CollectCycle) * time.Hour,
+			Precise:         newPrecise(fundingData.Data.FundingRate, Decimal{}, ticker.Volume24, ticker.Amount24),
 		})
 
 		count++
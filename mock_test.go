@@ -0,0 +1,213 @@
+package exchanges_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+	"github.com/petrixs/cr-exchanges/exchangetest"
+)
+
+// mockExchanges клиенты бирж, которые эмулирует exchangetest.MockServer
+var mockExchanges = map[exchangetest.Venue]func() exchanges.Exchange{
+	exchangetest.VenueBinance:     func() exchanges.Exchange { return &exchanges.Binance{} },
+	exchangetest.VenueBybit:       func() exchanges.Exchange { return &exchanges.Bybit{} },
+	exchangetest.VenueOKX:         func() exchanges.Exchange { return &exchanges.OKX{} },
+	exchangetest.VenueGate:        func() exchanges.Exchange { return &exchanges.Gate{} },
+	exchangetest.VenueHTX:         func() exchanges.Exchange { return &exchanges.HTX{} },
+	exchangetest.VenueKuCoin:      func() exchanges.Exchange { return &exchanges.KuCoin{} },
+	exchangetest.VenueBingX:       func() exchanges.Exchange { return &exchanges.BingX{} },
+	exchangetest.VenueMEXC:        func() exchanges.Exchange { return &exchanges.MEXC{} },
+	exchangetest.VenueHyperliquid: func() exchanges.Exchange { return &exchanges.Hyperliquid{} },
+}
+
+// ownNextFunding биржи, для которых время фандинга вычисляется клиентом (Hyperliquid)
+// или берется следующее за ближайшей выплатой (OKX nextFundingTime)
+var ownNextFunding = map[exchangetest.Venue]bool{
+	exchangetest.VenueOKX:         true,
+	exchangetest.VenueHyperliquid: true,
+}
+
+func newMockServer(t *testing.T) *exchangetest.MockServer {
+	t.Helper()
+
+	server := exchangetest.NewMockServer()
+	exchanges.SetHTTPClient(server.Client())
+	t.Cleanup(func() {
+		exchanges.SetHTTPClient(nil)
+		server.Close()
+	})
+	return server
+}
+
+func newTestCache() *exchanges.RatesCache {
+	return &exchanges.RatesCache{Rates: make(map[string][]exchanges.FundingRate)}
+}
+
+// findRate ищет ставку символа в кэше биржи
+func findRate(cache *exchanges.RatesCache, exchange, symbol string) (exchanges.FundingRate, bool) {
+	for _, rate := range cache.GetRates(exchange) {
+		if rate.Symbol == symbol {
+			return rate, true
+		}
+	}
+	return exchanges.FundingRate{}, false
+}
+
+func TestMockServerAllVenues(t *testing.T) {
+	server := newMockServer(t)
+	next := time.Now().Truncate(time.Hour).Add(2 * time.Hour)
+
+	for _, venue := range exchangetest.Venues() {
+		server.SetRate(venue, "BTC", exchangetest.MockRate{Rate: 0.0001, NextFunding: next, Volume: 1000, Price: 50000})
+		server.SetRate(venue, "ETH", exchangetest.MockRate{Rate: -0.00025, Interval: 4 * time.Hour, Volume: 20000, Price: 2500})
+	}
+
+	cache := newTestCache()
+	for _, venue := range exchangetest.Venues() {
+		exchange := mockExchanges[venue]()
+		if err := cache.UpdateRates(exchange); err != nil {
+			t.Fatalf("%s: %v", venue, err)
+		}
+
+		btc, ok := findRate(cache, exchange.GetName(), exchangetest.Symbol(venue, "BTC"))
+		if !ok {
+			t.Fatalf("%s: нет ставки BTC среди %+v", venue, cache.GetRates(exchange.GetName()))
+		}
+		if btc.Rate != 0.0001 || btc.Volume24h == 0 {
+			t.Errorf("%s: ставка BTC %+v", venue, btc)
+		}
+		if !ownNextFunding[venue] && btc.NextFunding != next.Format(time.RFC3339) {
+			t.Errorf("%s: время фандинга %s, ожидалось %s", venue, btc.NextFunding, next.Format(time.RFC3339))
+		}
+
+		eth, ok := findRate(cache, exchange.GetName(), exchangetest.Symbol(venue, "ETH"))
+		if !ok || eth.Rate != -0.00025 {
+			t.Errorf("%s: ставка ETH %+v", venue, eth)
+		}
+	}
+
+	// Ставка меняется между обновлениями, контракт снимается с торгов
+	server.SetRate(exchangetest.VenueGate, "BTC", exchangetest.MockRate{Rate: 0.0005, Volume: 1000, Price: 50000})
+	server.RemoveRate(exchangetest.VenueGate, "ETH")
+	if err := cache.UpdateRates(&exchanges.Gate{}); err != nil {
+		t.Fatal(err)
+	}
+	rates := cache.GetRates("Gate.io")
+	if len(rates) != 1 || rates[0].Rate != 0.0005 {
+		t.Errorf("после изменения состояния Gate.io: %+v", rates)
+	}
+}
+
+func TestMockServerFaults(t *testing.T) {
+	server := newMockServer(t)
+	for _, venue := range exchangetest.Venues() {
+		server.SetRate(venue, "BTC", exchangetest.MockRate{Rate: 0.0001, Volume: 1000, Price: 50000})
+	}
+
+	t.Run("429", func(t *testing.T) {
+		cache := newTestCache()
+		server.AddFault(exchangetest.VenueBinance, exchangetest.Fault{Path: "/fapi/v1/premiumIndex", Status: 429, Times: 1})
+
+		if err := cache.UpdateRates(&exchanges.Binance{}); err == nil {
+			t.Fatal("ожидалась ошибка при ответе 429")
+		}
+		if status := cache.GetStatus()["Binance"]; status.Errors != 1 || status.Rows != 0 {
+			t.Errorf("состояние после ошибки: %+v", status)
+		}
+
+		// Сбой исчерпан, следующее обновление проходит
+		if err := cache.UpdateRates(&exchanges.Binance{}); err != nil {
+			t.Fatal(err)
+		}
+		if status := cache.GetStatus()["Binance"]; status.Updates != 1 || status.Rows != 1 {
+			t.Errorf("состояние после восстановления: %+v", status)
+		}
+	})
+
+	t.Run("поврежденный ответ", func(t *testing.T) {
+		server.AddFault(exchangetest.VenueBybit, exchangetest.Fault{Path: "/v5/market/tickers", Body: `{"retCode":0,"result":{"list":[`})
+		defer server.ClearFaults(exchangetest.VenueBybit)
+
+		if err := newTestCache().UpdateRates(&exchanges.Bybit{}); err == nil {
+			t.Fatal("ожидалась ошибка декодирования")
+		}
+	})
+
+	t.Run("частичный отказ", func(t *testing.T) {
+		// Недоступны только объемы: ставки приходят без них
+		server.AddFault(exchangetest.VenueHTX, exchangetest.Fault{Path: "/linear-swap-ex/", Drop: true})
+		defer server.ClearFaults(exchangetest.VenueHTX)
+
+		cache := newTestCache()
+		if err := cache.UpdateRates(&exchanges.HTX{}); err != nil {
+			t.Fatal(err)
+		}
+		rates := cache.GetRates("HTX")
+		if len(rates) != 1 || rates[0].Rate != 0.0001 || rates[0].VolumeUSDT24h != 0 {
+			t.Errorf("ставки при недоступных объемах: %+v", rates)
+		}
+	})
+
+	t.Run("задержка", func(t *testing.T) {
+		client := server.Client()
+		client.Timeout = 100 * time.Millisecond
+		exchanges.SetHTTPClient(client)
+		defer exchanges.SetHTTPClient(server.Client())
+
+		server.AddFault(exchangetest.VenueGate, exchangetest.Fault{Latency: time.Second, Times: 1})
+		err := newTestCache().UpdateRates(&exchanges.Gate{})
+		if err == nil || !strings.Contains(err.Error(), "Timeout") {
+			t.Fatalf("ожидался таймаут, получено %v", err)
+		}
+	})
+}
+
+// notifierFunc адаптер функции к exchanges.Notifier
+type notifierFunc func(exchanges.Alert) error
+
+func (f notifierFunc) Notify(alert exchanges.Alert) error {
+	return f(alert)
+}
+
+func TestMockServerAlerts(t *testing.T) {
+	server := newMockServer(t)
+	server.SetRate(exchangetest.VenueBybit, "BTC", exchangetest.MockRate{Rate: 0.0001, Volume: 1000, Price: 50000})
+
+	alerts := make(chan exchanges.Alert, 10)
+	engine := exchanges.NewAlertEngine(time.Hour)
+	engine.AddRule(exchanges.ThresholdRule{RuleName: "high", Symbol: "BTC", Threshold: 0.001, Direction: exchanges.ThresholdAbove})
+	engine.AddNotifier(notifierFunc(func(alert exchanges.Alert) error {
+		alerts <- alert
+		return nil
+	}))
+
+	cache := newTestCache()
+	stop := engine.Attach(cache)
+	defer stop()
+
+	if err := cache.UpdateRates(&exchanges.Bybit{}); err != nil {
+		t.Fatal(err)
+	}
+
+	server.SetRate(exchangetest.VenueBybit, "BTC", exchangetest.MockRate{Rate: 0.002, Volume: 1000, Price: 50000})
+	if err := cache.UpdateRates(&exchanges.Bybit{}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case alert := <-alerts:
+		if alert.Exchange != "Bybit" || alert.Symbol != "BTCUSDT" || alert.Value != 0.002 {
+			t.Errorf("оповещение %+v", alert)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("оповещение не получено")
+	}
+
+	select {
+	case alert := <-alerts:
+		t.Errorf("лишнее оповещение %+v", alert)
+	default:
+	}
+}
@@ -0,0 +1,78 @@
+package exchanges
+
+import (
+	"math"
+	"time"
+)
+
+// DefaultFundingInterval интервал фандинга, который предполагается, если биржа его не сообщает
+const DefaultFundingInterval = 8 * time.Hour
+
+// DaysPerYear число дней в году при пересчете ставок в годовые (крипторынок торгуется без выходных)
+const DaysPerYear = 365
+
+// Compounding способ пересчета ставки фандинга в годовую
+type Compounding int
+
+const (
+	CompoundSimple     Compounding = iota // APR: без реинвестирования
+	CompoundPerFunding                    // APY: реинвестирование на каждой выплате фандинга
+	CompoundDaily                         // APY: реинвестирование раз в сутки
+	CompoundContinuous                    // APY: непрерывное начисление
+)
+
+// Interval возвращает интервал фандинга ставки или DefaultFundingInterval, если он неизвестен
+func (r FundingRate) Interval() time.Duration {
+	if r.FundingInterval > 0 {
+		return r.FundingInterval
+	}
+	return DefaultFundingInterval
+}
+
+// RateFor пересчитывает ставку за интервал биржи в ставку за период без реинвестирования
+func (r FundingRate) RateFor(period time.Duration) float64 {
+	return r.Rate * float64(period) / float64(r.Interval())
+}
+
+// HourlyRate ставка, приведенная к одному часу
+func (r FundingRate) HourlyRate() float64 {
+	return r.RateFor(time.Hour)
+}
+
+// Rate8h ставка, приведенная к 8 часам — самому распространенному интервалу фандинга
+func (r FundingRate) Rate8h() float64 {
+	return r.RateFor(8 * time.Hour)
+}
+
+// DailyRate ставка, приведенная к суткам
+func (r FundingRate) DailyRate() float64 {
+	return r.RateFor(24 * time.Hour)
+}
+
+// APR годовая ставка без реинвестирования
+func (r FundingRate) APR() float64 {
+	return r.Annualized(CompoundSimple)
+}
+
+// APY годовая доходность с реинвестированием на каждой выплате фандинга
+func (r FundingRate) APY() float64 {
+	return r.Annualized(CompoundPerFunding)
+}
+
+// Annualized пересчитывает ставку в годовую по выбранному способу начисления
+func (r FundingRate) Annualized(compounding Compounding) float64 {
+	year := DaysPerYear * 24 * time.Hour
+	apr := r.RateFor(year)
+
+	switch compounding {
+	case CompoundPerFunding:
+		periods := float64(year) / float64(r.Interval())
+		return math.Pow(1+r.Rate, periods) - 1
+	case CompoundDaily:
+		return math.Pow(1+r.DailyRate(), DaysPerYear) - 1
+	case CompoundContinuous:
+		return math.Exp(apr) - 1
+	default:
+		return apr
+	}
+}
@@ -0,0 +1,92 @@
+package exchanges
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Notifier доставляет оповещения
+type Notifier interface {
+	Notify(alert Alert) error
+}
+
+// postJSON отправляет JSON методом POST и проверяет код ответа
+func postJSON(client *http.Client, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 300))
+		return fmt.Errorf("сервер вернул код ошибки %d: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// WebhookNotifier отправляет оповещение в виде JSON на произвольный URL
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		URL:    url,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (w *WebhookNotifier) Notify(alert Alert) error {
+	return postJSON(w.Client, w.URL, alert)
+}
+
+// TelegramNotifier отправляет оповещение через метод sendMessage Telegram Bot API.
+// BaseURL можно заменить на совместимый сервер, например для локальных тестов
+type TelegramNotifier struct {
+	BaseURL string
+	Token   string
+	ChatID  string
+	Client  *http.Client
+}
+
+func NewTelegramNotifier(token, chatID string) *TelegramNotifier {
+	return &TelegramNotifier{
+		BaseURL: "https://api.telegram.org",
+		Token:   token,
+		ChatID:  chatID,
+		Client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (t *TelegramNotifier) Notify(alert Alert) error {
+	url := strings.TrimSuffix(t.BaseURL, "/") + "/bot" + t.Token + "/sendMessage"
+	payload := map[string]string{
+		"chat_id": t.ChatID,
+		"text":    alert.Message,
+	}
+	if err := postJSON(t.Client, url, payload); err != nil {
+		// Токен входит в URL, поэтому не возвращаем исходную ошибку клиента с адресом
+		return fmt.Errorf("ошибка отправки в Telegram: %v", redactToken(err, t.Token))
+	}
+	return nil
+}
+
+// redactToken убирает токен бота из текста ошибки
+func redactToken(err error, token string) string {
+	if token == "" {
+		return err.Error()
+	}
+	return strings.ReplaceAll(err.Error(), token, "***")
+}
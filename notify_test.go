@@ -0,0 +1,82 @@
+package exchanges_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	var got exchanges.Alert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("запрос %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		if got.Rule == "fail" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	notifier := exchanges.NewWebhookNotifier(server.URL)
+	alert := exchanges.Alert{Rule: "high", Key: "high|Binance|BTCUSDT", Exchange: "Binance", Symbol: "BTCUSDT", Value: 0.002, Time: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)}
+	if err := notifier.Notify(alert); err != nil {
+		t.Fatal(err)
+	}
+	if got != alert {
+		t.Errorf("получено %+v, ожидалось %+v", got, alert)
+	}
+
+	if err := notifier.Notify(exchanges.Alert{Rule: "fail"}); err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("ожидалась ошибка с кодом 400, получено %v", err)
+	}
+}
+
+func TestTelegramNotifier(t *testing.T) {
+	const token = "123:secret-token"
+
+	var path string
+	var payload map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Error(err)
+		}
+		if payload["text"] == "fail" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	notifier := exchanges.NewTelegramNotifier(token, "42")
+	notifier.BaseURL = server.URL + "/"
+	if err := notifier.Notify(exchanges.Alert{Message: "BTC выше порога"}); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/bot"+token+"/sendMessage" {
+		t.Errorf("путь %s", path)
+	}
+	if payload["chat_id"] != "42" || payload["text"] != "BTC выше порога" {
+		t.Errorf("тело %v", payload)
+	}
+
+	err := notifier.Notify(exchanges.Alert{Message: "fail"})
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("ожидалась ошибка с кодом 401, получено %v", err)
+	}
+
+	// Токен не попадает в текст ошибки соединения
+	server.Close()
+	err = notifier.Notify(exchanges.Alert{Message: "offline"})
+	if err == nil || strings.Contains(err.Error(), token) {
+		t.Errorf("ошибка %v", err)
+	}
+}
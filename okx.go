@@ -6,52 +6,66 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"net/http"
-	"os"
+	"net/url"
 	"strconv"
 	"time"
 )
 
 type OKX struct {
-	ApiKey     string
-	SecretKey  string
+	Credentials CredentialProvider // Ключи для приватных запросов, по умолчанию из переменных окружения OKX_*
+
+	// Deprecated: используйте Credentials (например, NewStaticCredentials).
+	// Если поля заданы, они имеют приоритет над Credentials
+	ApiKey string
+	// Deprecated: используйте Credentials
+	SecretKey string
+	// Deprecated: используйте Credentials
 	Passphrase string
+
+	IncludeInverse bool // Включать бессрочные контракты с расчетами в монете (USD-маржинальные SWAP)
 }
 
 func NewOKX() *OKX {
 	log.Println("Инициализация OKX")
 
-	apiKey := os.Getenv("OKX_API_KEY")
-	secretKey := os.Getenv("OKX_SECRET_KEY")
-	passphrase := os.Getenv("OKX_PASSPHRASE")
-
-	if apiKey == "" || secretKey == "" || passphrase == "" {
-		log.Println("Предупреждение: Ключи API для OKX не настроены, используются тестовые данные")
-		return &OKX{}
+	credentials := EnvCredentials{Prefix: "OKX"}
+	if _, err := credentials.Credentials(); err != nil {
+		log.Println("Предупреждение: Ключи API для OKX не настроены, приватные запросы недоступны")
 	}
 
-	log.Printf("OKX API ключи настроены: ApiKey: %s... SecretKey: %s... Passphrase: %s...",
-		apiKey[:5]+"...",
-		secretKey[:5]+"...",
-		passphrase[:2]+"...")
-
-	return &OKX{
-		ApiKey:     apiKey,
-		SecretKey:  secretKey,
-		Passphrase: passphrase,
-	}
+	return &OKX{Credentials: credentials}
 }
 
 func (o *OKX) GetName() string {
 	return "OKX"
 }
 
-func (o *OKX) signRequest(timestamp, method, requestPath string, body string) string {
+// String скрывает ключи устаревших полей при выводе клиента через fmt
+func (o OKX) String() string {
+	return fmt.Sprintf("OKX{Credentials: %v, ApiKey: %s, SecretKey: %s, Passphrase: %s, IncludeInverse: %t}",
+		o.Credentials, redactKey(o.ApiKey), redactSecret(o.SecretKey), redactSecret(o.Passphrase), o.IncludeInverse)
+}
+
+// GoString скрывает ключи и при выводе через %#v
+func (o OKX) GoString() string {
+	return o.String()
+}
+
+// credentialProvider возвращает ключи из устаревших полей ApiKey, SecretKey и Passphrase, если они заданы,
+// иначе Credentials
+func (o *OKX) credentialProvider() CredentialProvider {
+	if o.ApiKey != "" || o.SecretKey != "" || o.Passphrase != "" {
+		return NewStaticCredentials(o.ApiKey, o.SecretKey, o.Passphrase)
+	}
+	return o.Credentials
+}
+
+func (o *OKX) signRequest(secretKey, timestamp, method, requestPath string, body string) string {
 	message := timestamp + method + requestPath + body
 
-	h := hmac.New(sha256.New, []byte(o.SecretKey))
+	h := hmac.New(sha256.New, []byte(secretKey))
 	h.Write([]byte(message))
 	return base64.StdEncoding.EncodeToString(h.Sum(nil))
 }
@@ -60,16 +74,22 @@ func (o *OKX) GetFundingRates() ([]FundingRate, error) {
 	log.Println("Запрос ставок фандинга с OKX")
 
 	// Получаем объемы
-	volumes, volumesUSDT, err := o.getVolumes()
+	tickers, err := o.getVolumes()
 	if err != nil {
 		log.Printf("Ошибка получения объемов OKX: %v", err)
 		// Продолжаем работу без объемов
-		volumes = make(map[string]float64)
-		volumesUSDT = make(map[string]float64)
+		tickers = make(map[string]okxTicker)
+	}
+
+	// Маркировочные цены нужны для базиса, без них ставки остаются полезны
+	markPrices, err := o.getMarkPrices()
+	if err != nil {
+		log.Printf("Ошибка получения маркировочных цен OKX: %v", err)
+		markPrices = make(map[string]float64)
 	}
 
 	// Получаем список всех инструментов
-	resp, err := http.Get("https://www.okx.com/api/v5/public/instruments?instType=SWAP")
+	resp, err := httpGet("https://www.okx.com/api/v5/public/instruments?instType=SWAP")
 	if err != nil {
 		log.Printf("Ошибка запроса инструментов OKX: %v", err)
 		return nil, err
@@ -80,11 +100,14 @@ func (o *OKX) GetFundingRates() ([]FundingRate, error) {
 		Code string `json:"code"`
 		Msg  string `json:"msg"`
 		Data []struct {
-			InstId string `json:"instId"`
+			InstId    string `json:"instId"`
+			CtType    string `json:"ctType"`
+			CtVal     string `json:"ctVal"`
+			SettleCcy string `json:"settleCcy"`
 		} `json:"data"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&instrumentsResponse); err != nil {
+	if err := decodeResponse(o.GetName(), resp, &instrumentsResponse); err != nil {
 		log.Printf("Ошибка декодирования инструментов OKX: %v", err)
 		return nil, err
 	}
@@ -95,28 +118,52 @@ func (o *OKX) GetFundingRates() ([]FundingRate, error) {
 
 	log.Printf("Получено %d инструментов OKX", len(instrumentsResponse.Data))
 
+	// Инверсные контракты учитываем только по запросу. Ставка запрашивается по каждому инструменту,
+	// поэтому обрабатываем первые 50 инструментов каждого типа маржи: общий предел
+	// отбрасывал бы инверсные контракты, стоящие в списке после линейных
+	const maxInstruments = 50
+	perType := make(map[string]int)
+	instruments := instrumentsResponse.Data[:0]
+	for _, instrument := range instrumentsResponse.Data {
+		if instrument.CtType == "inverse" && !o.IncludeInverse {
+			continue
+		}
+		if perType[instrument.CtType] >= maxInstruments {
+			continue
+		}
+		perType[instrument.CtType]++
+		instruments = append(instruments, instrument)
+	}
+	instrumentsResponse.Data = instruments
+
 	var result []FundingRate
 	var nonZeroRates []string
 
-	// Обрабатываем только первые 50 инструментов для быстрого тестирования
-	maxInstruments := 50
-	if len(instrumentsResponse.Data) > maxInstruments {
-		instrumentsResponse.Data = instrumentsResponse.Data[:maxInstruments]
-	}
-
 	processed := 0
 	for _, instrument := range instrumentsResponse.Data {
 		instId := instrument.InstId
 
-		// Получаем объемы для данного инструмента
-		volume24h := volumes[instId]
-		volumeUSDT24h := volumesUSDT[instId]
+		// Получаем объемы для данного инструмента: volCcy24h указан в монете,
+		// vol24h — в контрактах номиналом ctVal USD для инверсных контрактов.
+		// До поддержки инверсных контрактов Volume24h содержал vol24h (контракты),
+		// а VolumeUSDT24h — volCcy24h (монету); теперь это объем в монете и оборот в USDT
+		ticker := tickers[instId]
+		volume24h := ticker.VolCcy24h
+		volumeUSDT24h := derived(ticker.VolCcy24h.Value.Mul(ticker.Last.Value))
+		marginType := MarginLinear
+		if instrument.CtType == "inverse" {
+			volumeUSDT24h = derived(ticker.Vol24h.Value.Mul(parseDecimal(instrument.CtVal).Value))
+			marginType = MarginInverse
+		}
 
 		fundingRate, err := o.getFundingRateForInstrument(instId, volume24h, volumeUSDT24h)
 		if err != nil {
 			log.Printf("Ошибка получения фандинг ставки для %s: %v", instId, err)
 			continue
 		}
+		fundingRate.SettleAsset = instrument.SettleCcy
+		fundingRate.MarginType = marginType
+		fundingRate.MarkPrice = markPrices[instId]
 
 		if fundingRate.Rate != 0 {
 			nonZeroRates = append(nonZeroRates, fmt.Sprintf("%s: %f", instId, fundingRate.Rate))
@@ -139,84 +186,239 @@ func (o *OKX) GetFundingRates() ([]FundingRate, error) {
 	return result, nil
 }
 
-// getInstruments получает список инструментов с OKX
-func (o *OKX) getInstruments() ([]string, error) {
-	endpoint := "/api/v5/public/instruments"
-	queryParams := "?instType=SWAP"
-	timestamp := time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
-	signature := o.signRequest(timestamp, "GET", endpoint+queryParams, "")
+// getFundingRate получает ставку фандинга для конкретного инструмента
+func (o *OKX) getFundingRateForInstrument(instId string, volume24h, volumeUSDT24h Decimal) (FundingRate, error) {
+	// Получаем информацию о фандинг ставке для конкретного инструмента
+	url := fmt.Sprintf("https://www.okx.com/api/v5/public/funding-rate?instId=%s", instId)
 
-	client := &http.Client{}
-	req, err := http.NewRequest("GET", "https://www.okx.com"+endpoint+queryParams, nil)
+	resp, err := httpGet(url)
 	if err != nil {
-		log.Printf("Ошибка создания запроса к OKX (instruments): %v", err)
-		return nil, err
+		return FundingRate{}, err
 	}
+	defer resp.Body.Close()
 
-	// Добавляем заголовки для аутентификации
-	req.Header.Add("OK-ACCESS-KEY", o.ApiKey)
-	req.Header.Add("OK-ACCESS-SIGN", signature)
-	req.Header.Add("OK-ACCESS-TIMESTAMP", timestamp)
-	req.Header.Add("OK-ACCESS-PASSPHRASE", o.Passphrase)
-	req.Header.Add("Content-Type", "application/json")
+	var response struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			InstId          string `json:"instId"`
+			FundingRate     string `json:"fundingRate"`
+			FundingTime     string `json:"fundingTime"`
+			NextFundingTime string `json:"nextFundingTime"`
+		} `json:"data"`
+	}
 
-	// Логирование для отладки
-	log.Printf("OKX запрос списка инструментов: %s", req.URL.String())
+	if err := decodeResponse(o.GetName(), resp, &response); err != nil {
+		return FundingRate{}, err
+	}
+
+	if response.Code != "0" {
+		return FundingRate{}, fmt.Errorf("OKX API ошибка для %s: %s - %s", instId, response.Code, response.Msg)
+	}
+
+	if len(response.Data) == 0 {
+		return FundingRate{}, fmt.Errorf("нет данных о фандинг ставке для %s", instId)
+	}
 
-	resp, err := client.Do(req)
+	data := response.Data[0]
+
+	// Парсим ставку фандинга
+	fundingRate, err := ParseDecimal(data.FundingRate)
 	if err != nil {
-		log.Printf("Ошибка запроса инструментов к OKX: %v", err)
-		return nil, err
+		return FundingRate{}, fmt.Errorf("ошибка парсинга ставки фандинга %s: %v", data.FundingRate, err)
 	}
-	defer resp.Body.Close()
 
-	log.Printf("Код ответа от OKX (instruments): %d", resp.StatusCode)
+	// Парсим время следующего фандинга
+	nextFundingTimeMs, err := strconv.ParseInt(data.NextFundingTime, 10, 64)
+	if err != nil {
+		return FundingRate{}, fmt.Errorf("ошибка парсинга времени фандинга %s: %v", data.NextFundingTime, err)
+	}
 
-	// Чтение и логирование ответа
-	respBody, _ := ioutil.ReadAll(resp.Body)
+	nextFundingTime := time.Unix(nextFundingTimeMs/1000, 0).Format(time.RFC3339)
 
-	// Для экономии места в логах выводим только первые 300 символов ответа
-	if len(respBody) > 300 {
-		log.Printf("OKX ответ (первые 300 символов): %s...", string(respBody)[:300])
-	} else {
-		log.Printf("OKX ответ: %s", string(respBody))
+	// Интервал фандинга — разница между ближайшей и следующей за ней выплатой
+	var interval time.Duration
+	if fundingTimeMs, err := strconv.ParseInt(data.FundingTime, 10, 64); err == nil && nextFundingTimeMs > fundingTimeMs {
+		interval = time.Duration(nextFundingTimeMs-fundingTimeMs) * time.Millisecond
 	}
 
-	// Проверяем корректность ответа
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("OKX API вернул код ошибки %d: %s", resp.StatusCode, string(respBody))
+	return FundingRate{
+		Symbol:          instId,
+		Rate:            fundingRate.Float64(),
+		NextFunding:     nextFundingTime,
+		Volume24h:       volume24h.Float64(),
+		VolumeUSDT24h:   volumeUSDT24h.Float64(),
+		FundingInterval: interval,
+		Precise:         newPrecise(fundingRate, Decimal{}, volume24h, volumeUSDT24h),
+	}, nil
+}
+
+// okxBillsRecentRange период, за который OKX отдает счета через /account/bills, более старые — через архив
+const okxBillsRecentRange = 7 * 24 * time.Hour
+
+// GetFundingPayments возвращает начисления фандинга по SWAP-контрактам (счета type=8).
+// Записи старше 7 дней запрашиваются из архива, который хранит 3 месяца
+func (o *OKX) GetFundingPayments(query FundingPaymentsQuery) ([]FundingPayment, error) {
+	credentials, err := resolveCredentials(o.GetName(), o.credentialProvider(), true)
+	if err != nil {
+		return nil, err
 	}
 
-	type Instrument struct {
-		InstID string `json:"instId"`
+	endpoint := "/api/v5/account/bills"
+	if !query.From.IsZero() && time.Since(query.From) > okxBillsRecentRange {
+		endpoint = "/api/v5/account/bills-archive"
 	}
 
-	var response struct {
-		Code string       `json:"code"`
-		Data []Instrument `json:"data"`
+	var result []FundingPayment
+	after := ""
+
+	for {
+		params := url.Values{}
+		params.Set("instType", "SWAP")
+		params.Set("type", "8")
+		params.Set("limit", "100")
+		if query.Symbol != "" {
+			params.Set("instId", query.Symbol)
+		}
+		if !query.From.IsZero() {
+			params.Set("begin", strconv.FormatInt(query.From.UnixMilli(), 10))
+		}
+		if !query.To.IsZero() {
+			params.Set("end", strconv.FormatInt(query.To.UnixMilli(), 10))
+		}
+		// Счета отдаются от новых к старым, after — идентификатор последней полученной записи
+		if after != "" {
+			params.Set("after", after)
+		}
+
+		var bills []struct {
+			BillID string `json:"billId"`
+			InstID string `json:"instId"`
+			Ccy    string `json:"ccy"`
+			BalChg string `json:"balChg"`
+			Ts     string `json:"ts"`
+		}
+		if err := o.signedGet(credentials, endpoint, params, &bills); err != nil {
+			return nil, err
+		}
+
+		for _, bill := range bills {
+			ts, _ := strconv.ParseInt(bill.Ts, 10, 64)
+			result = append(result, FundingPayment{
+				Exchange: o.GetName(),
+				Symbol:   bill.InstID,
+				Amount:   parseFloatFromString(bill.BalChg),
+				Asset:    bill.Ccy,
+				Time:     time.UnixMilli(ts),
+				ID:       bill.BillID,
+			})
+		}
+
+		if len(bills) < 100 || query.reachedLimit(len(result)) {
+			break
+		}
+		after = bills[len(bills)-1].BillID
+	}
+
+	log.Printf("Получено %d начислений фандинга с OKX", len(result))
+	return query.truncate(result), nil
+}
+
+// GetPositions возвращает открытые позиции по SWAP-контрактам
+func (o *OKX) GetPositions() ([]Position, error) {
+	credentials, err := resolveCredentials(o.GetName(), o.credentialProvider(), true)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := json.Unmarshal(respBody, &response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования списка инструментов от OKX: %v", err)
+	params := url.Values{}
+	params.Set("instType", "SWAP")
+
+	var positions []struct {
+		InstID      string `json:"instId"`
+		Pos         string `json:"pos"`     // в контрактах, в режиме net со знаком
+		PosSide     string `json:"posSide"` // long, short или net
+		AvgPx       string `json:"avgPx"`
+		MarkPx      string `json:"markPx"`
+		Lever       string `json:"lever"`
+		NotionalUsd string `json:"notionalUsd"`
+		Upl         string `json:"upl"`
+	}
+	if err := o.signedGet(credentials, "/api/v5/account/positions", params, &positions); err != nil {
+		return nil, err
 	}
 
-	// Извлекаем идентификаторы инструментов
-	var instruments []string
-	for _, instrument := range response.Data {
-		instruments = append(instruments, instrument.InstID)
+	var result []Position
+	for _, item := range positions {
+		contracts := parseFloatFromString(item.Pos)
+		markPrice := parseFloatFromString(item.MarkPx)
+		notional := parseFloatFromString(item.NotionalUsd)
+		if contracts == 0 || markPrice == 0 {
+			continue
+		}
+
+		// Размер в монете получаем из стоимости, т.к. номинал контракта зависит от инструмента
+		size := notional / markPrice
+		if item.PosSide == "short" || (item.PosSide == "net" && contracts < 0) {
+			size = -size
+		}
+
+		position := newPosition(o.GetName(), item.InstID, size, notional)
+		position.EntryPrice = parseFloatFromString(item.AvgPx)
+		position.MarkPrice = markPrice
+		position.Leverage = parseFloatFromString(item.Lever)
+		position.UnrealizedPnL = parseFloatFromString(item.Upl)
+		result = append(result, position)
 	}
 
-	return instruments, nil
+	log.Printf("Получено %d позиций с OKX", len(result))
+	return result, nil
 }
 
-// getFundingRate получает ставку фандинга для конкретного инструмента
-func (o *OKX) getFundingRateForInstrument(instId string, volume24h, volumeUSDT24h float64) (FundingRate, error) {
-	// Получаем информацию о фандинг ставке для конкретного инструмента
-	url := fmt.Sprintf("https://www.okx.com/api/v5/public/funding-rate?instId=%s", instId)
+// signedGet выполняет подписанный GET-запрос к API v5 и декодирует поле data в out
+func (o *OKX) signedGet(credentials Credentials, endpoint string, params url.Values, out interface{}) error {
+	requestPath := endpoint
+	if len(params) > 0 {
+		requestPath += "?" + params.Encode()
+	}
+	timestamp := time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
 
-	resp, err := http.Get(url)
+	req, err := http.NewRequest("GET", "https://www.okx.com"+requestPath, nil)
 	if err != nil {
-		return FundingRate{}, err
+		return err
+	}
+	req.Header.Set("OK-ACCESS-KEY", credentials.APIKey)
+	req.Header.Set("OK-ACCESS-SIGN", o.signRequest(credentials.SecretKey, timestamp, "GET", requestPath, ""))
+	req.Header.Set("OK-ACCESS-TIMESTAMP", timestamp)
+	req.Header.Set("OK-ACCESS-PASSPHRASE", credentials.Passphrase)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var response struct {
+		Code string          `json:"code"`
+		Msg  string          `json:"msg"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := decodeResponse(o.GetName(), resp, &response); err != nil {
+		return fmt.Errorf("OKX API ошибка: HTTP %d, %v", resp.StatusCode, err)
+	}
+	if response.Code != "0" {
+		return fmt.Errorf("OKX API ошибка: %s - %s", response.Code, response.Msg)
+	}
+
+	return decodeChecked(o.GetName(), schemaEndpoint(resp.Request.URL.Path), "data", response.Data, out)
+}
+
+// getMarkPrices получает маркировочные цены SWAP-контрактов
+func (o *OKX) getMarkPrices() (map[string]float64, error) {
+	resp, err := httpGet("https://www.okx.com/api/v5/public/mark-price?instType=SWAP")
+	if err != nil {
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -224,47 +426,59 @@ func (o *OKX) getFundingRateForInstrument(instId string, volume24h, volumeUSDT24
 		Code string `json:"code"`
 		Msg  string `json:"msg"`
 		Data []struct {
-			InstId          string `json:"instId"`
-			FundingRate     string `json:"fundingRate"`
-			NextFundingTime string `json:"nextFundingTime"`
+			InstId string `json:"instId"`
+			MarkPx string `json:"markPx"`
 		} `json:"data"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return FundingRate{}, err
+	if err := decodeResponse(o.GetName(), resp, &response); err != nil {
+		return nil, err
 	}
 
 	if response.Code != "0" {
-		return FundingRate{}, fmt.Errorf("OKX API ошибка для %s: %s - %s", instId, response.Code, response.Msg)
+		return nil, fmt.Errorf("OKX API ошибка: %s - %s", response.Code, response.Msg)
 	}
 
-	if len(response.Data) == 0 {
-		return FundingRate{}, fmt.Errorf("нет данных о фандинг ставке для %s", instId)
+	prices := make(map[string]float64, len(response.Data))
+	for _, item := range response.Data {
+		prices[item.InstId] = parseFloatFromString(item.MarkPx)
 	}
+	return prices, nil
+}
 
-	data := response.Data[0]
-
-	// Парсим ставку фандинга
-	fundingRate, err := strconv.ParseFloat(data.FundingRate, 64)
+// GetSpotPrices возвращает последние цены спотовых пар OKX по символам бессрочных контрактов:
+// цена пары BTC-USDT записывается под BTC-USDT-SWAP
+func (o *OKX) GetSpotPrices() (map[string]float64, error) {
+	resp, err := httpGet("https://www.okx.com/api/v5/market/tickers?instType=SPOT")
 	if err != nil {
-		return FundingRate{}, fmt.Errorf("ошибка парсинга ставки фандинга %s: %v", data.FundingRate, err)
+		log.Printf("Ошибка запроса спотовых цен OKX: %v", err)
+		return nil, err
 	}
+	defer resp.Body.Close()
 
-	// Парсим время следующего фандинга
-	nextFundingTimeMs, err := strconv.ParseInt(data.NextFundingTime, 10, 64)
-	if err != nil {
-		return FundingRate{}, fmt.Errorf("ошибка парсинга времени фандинга %s: %v", data.NextFundingTime, err)
+	var response struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			InstId string `json:"instId"`
+			Last   string `json:"last"`
+		} `json:"data"`
+	}
+	if err := decodeResponse(o.GetName(), resp, &response); err != nil {
+		log.Printf("Ошибка декодирования спотовых цен OKX: %v", err)
+		return nil, err
+	}
+	if response.Code != "0" {
+		return nil, fmt.Errorf("OKX API ошибка: %s - %s", response.Code, response.Msg)
 	}
 
-	nextFundingTime := time.Unix(nextFundingTimeMs/1000, 0).Format(time.RFC3339)
-
-	return FundingRate{
-		Symbol:        instId,
-		Rate:          fundingRate,
-		NextFunding:   nextFundingTime,
-		Volume24h:     volume24h,
-		VolumeUSDT24h: volumeUSDT24h,
-	}, nil
+	prices := make(map[string]float64, len(response.Data))
+	for _, ticker := range response.Data {
+		if price := parseFloatFromString(ticker.Last); price > 0 {
+			prices[ticker.InstId+"-SWAP"] = price
+		}
+	}
+	return prices, nil
 }
 
 // Структура для 24h статистики OKX
@@ -272,38 +486,45 @@ type okxTickerResponse struct {
 	Code string `json:"code"`
 	Data []struct {
 		InstId    string `json:"instId"`
+		Last      string `json:"last"`
 		Vol24h    string `json:"vol24h"`
 		VolCcy24h string `json:"volCcy24h"`
 	} `json:"data"`
 }
 
+// okxTicker объемы инструмента OKX за 24 часа
+type okxTicker struct {
+	Last      Decimal // Последняя цена
+	Vol24h    Decimal // Объем в контрактах
+	VolCcy24h Decimal // Объем в монете
+}
+
 // Получаем объемы для всех пар
-func (o *OKX) getVolumes() (map[string]float64, map[string]float64, error) {
-	resp, err := http.Get("https://www.okx.com/api/v5/market/tickers?instType=SWAP")
+func (o *OKX) getVolumes() (map[string]okxTicker, error) {
+	resp, err := httpGet("https://www.okx.com/api/v5/market/tickers?instType=SWAP")
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	var tickerData okxTickerResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tickerData); err != nil {
-		return nil, nil, err
+	if err := decodeResponse(o.GetName(), resp, &tickerData); err != nil {
+		return nil, err
 	}
 
 	if tickerData.Code != "0" {
-		return nil, nil, fmt.Errorf("OKX API error: %s", tickerData.Code)
+		return nil, fmt.Errorf("OKX API error: %s", tickerData.Code)
 	}
 
-	volumes := make(map[string]float64)
-	volumesUSDT := make(map[string]float64)
+	tickers := make(map[string]okxTicker)
 
 	for _, item := range tickerData.Data {
-		vol24h, _ := strconv.ParseFloat(item.Vol24h, 64)
-		volCcy24h, _ := strconv.ParseFloat(item.VolCcy24h, 64)
-
-		volumes[item.InstId] = vol24h
-		volumesUSDT[item.InstId] = volCcy24h
+		tickers[item.InstId] = okxTicker{
+			Last:      parseDecimal(item.Last),
+			Vol24h:    parseDecimal(item.Vol24h),
+			VolCcy24h: parseDecimal(item.VolCcy24h),
+		}
 	}
 
-	return volumes, volumesUSDT, nil
+	return tickers, nil
 }
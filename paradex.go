@@ -0,0 +1,151 @@
+package exchanges
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+type Paradex struct{}
+
+func NewParadex() *Paradex {
+	log.Println("Инициализация Paradex")
+	return &Paradex{}
+}
+
+func (p *Paradex) GetName() string {
+	return "Paradex"
+}
+
+// Структура описания рынков Paradex
+type paradexMarketsResponse struct {
+	Results []struct {
+		Symbol             string `json:"symbol"`
+		AssetKind          string `json:"asset_kind"`
+		SettlementCurrency string `json:"settlement_currency"`
+		FundingPeriodHours int    `json:"funding_period_hours"`
+	} `json:"results"`
+}
+
+// Получаем описание всех рынков
+func (p *Paradex) getMarkets() (*paradexMarketsResponse, error) {
+	resp, err := httpGet("https://api.prod.paradex.trade/v1/markets")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Paradex API вернул код ошибки %d", resp.StatusCode)
+	}
+
+	var markets paradexMarketsResponse
+	if err := decodeResponse(p.GetName(), resp, &markets); err != nil {
+		return nil, err
+	}
+
+	return &markets, nil
+}
+
+func (p *Paradex) GetFundingRates() ([]FundingRate, error) {
+	log.Println("Запрос ставок фандинга с Paradex")
+
+	markets, err := p.getMarkets()
+	if err != nil {
+		log.Printf("Ошибка получения рынков Paradex: %v", err)
+		return nil, err
+	}
+
+	type marketInfo struct {
+		settleAsset   string
+		fundingPeriod int
+	}
+
+	perps := make(map[string]marketInfo)
+	for _, market := range markets.Results {
+		if market.AssetKind != "PERP" {
+			continue
+		}
+		perps[market.Symbol] = marketInfo{
+			settleAsset:   market.SettlementCurrency,
+			fundingPeriod: market.FundingPeriodHours,
+		}
+	}
+
+	resp, err := httpGet("https://api.prod.paradex.trade/v1/markets/summary?market=ALL")
+	if err != nil {
+		log.Printf("Ошибка запроса к Paradex: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Paradex API вернул код ошибки %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Results []struct {
+			Symbol          string `json:"symbol"`
+			MarkPrice       string `json:"mark_price"`
+			UnderlyingPrice string `json:"underlying_price"`
+			Volume24h       string `json:"volume_24h"` // в USD
+			OpenInterest    string `json:"open_interest"`
+			FundingRate     string `json:"funding_rate"`
+		} `json:"results"`
+	}
+
+	if err := decodeResponse(p.GetName(), resp, &response); err != nil {
+		log.Printf("Ошибка декодирования ответа Paradex: %v", err)
+		return nil, err
+	}
+
+	location := GetLocationFromEnv()
+	// Фандинг Paradex начисляется непрерывно, временем выплаты считается начало следующего часа
+	nextFunding := time.Now().Truncate(time.Hour).Add(time.Hour).In(location).Format(time.RFC3339)
+	result := make([]FundingRate, 0)
+
+	for _, summary := range response.Results {
+		market, ok := perps[summary.Symbol]
+		if !ok || summary.FundingRate == "" {
+			continue
+		}
+
+		// Биржа публикует ставку за период funding_period_hours (обычно 8 часов); ставка сохраняется
+		// как есть, а период становится интервалом фандинга
+		period := market.fundingPeriod
+		if period <= 0 {
+			period = 8
+		}
+
+		settleAsset := market.settleAsset
+		if settleAsset == "" {
+			settleAsset = "USDC"
+		}
+
+		markPrice := parseDecimal(summary.MarkPrice)
+		volumeUSD := parseDecimal(summary.Volume24h)
+		var volume24h Decimal
+		if markPrice.Value.IsPositive() {
+			volume24h = derived(volumeUSD.Value.Div(markPrice.Value))
+		}
+		rate := parseDecimal(summary.FundingRate)
+
+		result = append(result, FundingRate{
+			Symbol:          summary.Symbol,
+			Rate:            rate.Float64(),
+			NextFunding:     nextFunding,
+			Volume24h:       volume24h.Float64(),
+			VolumeUSDT24h:   volumeUSD.Float64(),
+			MarkPrice:       markPrice.Float64(),
+			IndexPrice:      parseFloatFromString(summary.UnderlyingPrice),
+			FundingInterval: time.Duration(period) * time.Hour,
+			OpenInterest:    parseFloatFromString(summary.OpenInterest),
+			SettleAsset:     settleAsset,
+			Precise:         newPrecise(rate, Decimal{}, volume24h, volumeUSD),
+		})
+	}
+
+	log.Printf("Получено %d ставок фандинга с Paradex", len(result))
+	return result, nil
+}
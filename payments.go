@@ -0,0 +1,59 @@
+package exchanges
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// FundingPayment фактическое начисление фандинга по счету
+type FundingPayment struct {
+	Exchange string
+	Symbol   string
+	Amount   float64 // больше нуля — получено, меньше нуля — уплачено
+	Asset    string  // валюта начисления
+	Time     time.Time
+	ID       string // идентификатор записи на бирже
+}
+
+// FundingPaymentsQuery параметры выборки начислений фандинга
+type FundingPaymentsQuery struct {
+	Symbol string    // символ биржи, пусто — все
+	From   time.Time // нулевое значение — по умолчанию биржи
+	To     time.Time // нулевое значение — до текущего момента
+	Limit  int       // максимум записей, 0 — все доступные
+}
+
+// FundingPaymentsProvider биржа, отдающая историю начислений фандинга по счету.
+// Используются API-ключи экземпляра биржи, поэтому для нескольких счетов создаются отдельные экземпляры
+type FundingPaymentsProvider interface {
+	GetName() string
+	GetFundingPayments(query FundingPaymentsQuery) ([]FundingPayment, error)
+}
+
+// signHMACSHA256Hex подписывает сообщение HMAC-SHA256 и возвращает подпись в hex
+func signHMACSHA256Hex(secret, message string) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(message))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// errNoCredentials ошибка запроса приватных данных без API-ключей
+func errNoCredentials(exchange string) error {
+	return fmt.Errorf("%s: API-ключи не настроены", exchange)
+}
+
+// reachedLimit сообщает, набрано ли запрошенное число записей
+func (q FundingPaymentsQuery) reachedLimit(count int) bool {
+	return q.Limit > 0 && count >= q.Limit
+}
+
+// truncate обрезает результат до лимита запроса
+func (q FundingPaymentsQuery) truncate(payments []FundingPayment) []FundingPayment {
+	if q.Limit > 0 && len(payments) > q.Limit {
+		return payments[:q.Limit]
+	}
+	return payments
+}
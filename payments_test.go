@@ -0,0 +1,232 @@
+package exchanges_test
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+	"github.com/petrixs/cr-exchanges/exchangetest"
+)
+
+// jsonFixture фикстура GET-запроса с параметрами params и телом ответа body
+func jsonFixture(t *testing.T, endpoint string, params url.Values, body interface{}) exchangetest.Fixture {
+	t.Helper()
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return exchangetest.Fixture{Method: "GET", URL: endpoint + "?" + params.Encode(), Status: 200, Body: data}
+}
+
+// replayPayments запрашивает начисления у provider через сервер с фикстурами
+func replayPayments(t *testing.T, provider exchanges.FundingPaymentsProvider, query exchanges.FundingPaymentsQuery, fixtures ...exchangetest.Fixture) []exchanges.FundingPayment {
+	t.Helper()
+
+	server := exchangetest.NewReplayServer(fixtures...)
+	exchanges.SetHTTPClient(server.Client())
+	t.Cleanup(func() {
+		exchanges.SetHTTPClient(nil)
+		server.Close()
+	})
+
+	payments, err := provider.GetFundingPayments(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unmatched := server.Unmatched(); len(unmatched) > 0 {
+		t.Errorf("запросы без фикстур: %v", unmatched)
+	}
+	return payments
+}
+
+// checkUniqueIDs проверяет, что идентификаторы начислений не повторяются
+func checkUniqueIDs(t *testing.T, payments []exchanges.FundingPayment) {
+	t.Helper()
+
+	seen := make(map[string]bool, len(payments))
+	for _, payment := range payments {
+		if seen[payment.ID] {
+			t.Fatalf("повтор начисления %s", payment.ID)
+		}
+		seen[payment.ID] = true
+	}
+}
+
+func millis(t time.Time) string {
+	return strconv.FormatInt(t.UnixMilli(), 10)
+}
+
+var (
+	paymentsFrom = time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	paymentsTo   = paymentsFrom.Add(48 * time.Hour)
+	paymentsKeys = exchanges.NewStaticCredentials("key", "secret", "passphrase")
+)
+
+// incomePages страницы истории доходов Binance и BingX: больше страницы записей в одну
+// миллисекунду, затем две записи следующего фандинга
+func incomePages(settled time.Time, id func(int) interface{}) (full, tail []map[string]interface{}) {
+	for i := 1; i <= 1000; i++ {
+		full = append(full, map[string]interface{}{
+			"symbol": "COIN" + strconv.Itoa(i) + "USDT", "income": "-0.01", "asset": "USDT",
+			"time": settled.UnixMilli(), "tranId": id(i),
+		})
+	}
+	for i := 1001; i <= 1002; i++ {
+		tail = append(tail, map[string]interface{}{
+			"symbol": "BTCUSDT", "income": "0.5", "asset": "USDT",
+			"time": settled.Add(8 * time.Hour).UnixMilli(), "tranId": id(i),
+		})
+	}
+	return full, tail
+}
+
+func incomeParams(start time.Time) url.Values {
+	return url.Values{
+		"incomeType": {"FUNDING_FEE"},
+		"limit":      {"1000"},
+		"startTime":  {millis(start)},
+		"endTime":    {millis(paymentsTo)},
+	}
+}
+
+func TestBinanceFundingPaymentsPagination(t *testing.T) {
+	const endpoint = "https://fapi.binance.com/fapi/v1/income"
+	settled := paymentsFrom.Add(8 * time.Hour)
+	full, tail := incomePages(settled, func(i int) interface{} { return i })
+
+	payments := replayPayments(t, &exchanges.Binance{Credentials: paymentsKeys},
+		exchanges.FundingPaymentsQuery{From: paymentsFrom, To: paymentsTo},
+		jsonFixture(t, endpoint, incomeParams(paymentsFrom), full),
+		// Страница с той же миллисекунды не дает новых записей
+		jsonFixture(t, endpoint, incomeParams(settled), full),
+		jsonFixture(t, endpoint, incomeParams(settled.Add(time.Millisecond)), tail),
+	)
+
+	if len(payments) != 1002 {
+		t.Fatalf("начислений %d, ожидалось 1002", len(payments))
+	}
+	checkUniqueIDs(t, payments)
+	if last := payments[1001]; last.ID != "1002" || last.Amount != 0.5 || last.Asset != "USDT" {
+		t.Errorf("последнее начисление %+v", last)
+	}
+}
+
+func TestBingXFundingPaymentsPagination(t *testing.T) {
+	const endpoint = "https://open-api.bingx.com/openApi/swap/v2/user/income"
+	settled := paymentsFrom.Add(8 * time.Hour)
+	full, tail := incomePages(settled, func(i int) interface{} { return strconv.Itoa(i) })
+	wrap := func(data interface{}) map[string]interface{} {
+		return map[string]interface{}{"code": 0, "msg": "", "data": data}
+	}
+
+	payments := replayPayments(t, &exchanges.BingX{Credentials: paymentsKeys},
+		exchanges.FundingPaymentsQuery{From: paymentsFrom, To: paymentsTo},
+		jsonFixture(t, endpoint, incomeParams(paymentsFrom), wrap(full)),
+		jsonFixture(t, endpoint, incomeParams(settled), wrap(full)),
+		jsonFixture(t, endpoint, incomeParams(settled.Add(time.Millisecond)), wrap(tail)),
+	)
+
+	if len(payments) != 1002 {
+		t.Fatalf("начислений %d, ожидалось 1002", len(payments))
+	}
+	checkUniqueIDs(t, payments)
+}
+
+func TestBybitFundingPaymentsCategories(t *testing.T) {
+	const endpoint = "https://api.bybit.com/v5/account/transaction-log"
+	params := func(category, cursor string) url.Values {
+		values := url.Values{
+			"accountType": {"UNIFIED"},
+			"category":    {category},
+			"type":        {"SETTLEMENT"},
+			"limit":       {"50"},
+			"endTime":     {millis(paymentsTo)},
+		}
+		if cursor != "" {
+			values.Set("cursor", cursor)
+		}
+		return values
+	}
+	entry := func(id, symbol, currency, funding string, at time.Time) map[string]string {
+		return map[string]string{"id": id, "symbol": symbol, "currency": currency, "funding": funding, "transactionTime": millis(at)}
+	}
+	page := func(cursor string, list ...map[string]string) map[string]interface{} {
+		return map[string]interface{}{"retCode": 0, "retMsg": "OK", "result": map[string]interface{}{"list": list, "nextPageCursor": cursor}}
+	}
+
+	// Без From передается только endTime: биржа отдает сутки до него
+	payments := replayPayments(t, &exchanges.Bybit{Credentials: paymentsKeys},
+		exchanges.FundingPaymentsQuery{To: paymentsTo},
+		jsonFixture(t, endpoint, params("linear", ""), page("c1",
+			entry("l1", "BTCUSDT", "USDT", "0.2", paymentsTo.Add(-time.Hour)),
+			entry("l2", "ETHUSDT", "USDT", "-0.1", paymentsTo.Add(-time.Hour)))),
+		jsonFixture(t, endpoint, params("linear", "c1"), page("",
+			entry("l3", "BTCUSDT", "USDT", "0.3", paymentsTo.Add(-9*time.Hour)))),
+		jsonFixture(t, endpoint, params("inverse", ""), page("",
+			entry("i1", "BTCUSD", "BTC", "0.00001", paymentsTo.Add(-5*time.Hour)))),
+	)
+
+	want := []struct {
+		id     string
+		amount float64
+		asset  string
+	}{{"l1", -0.2, "USDT"}, {"l2", 0.1, "USDT"}, {"i1", -0.00001, "BTC"}, {"l3", -0.3, "USDT"}}
+	if len(payments) != len(want) {
+		t.Fatalf("начисления %+v", payments)
+	}
+	for i, w := range want {
+		if payments[i].ID != w.id || !approxEqual(payments[i].Amount, w.amount) || payments[i].Asset != w.asset {
+			t.Errorf("позиция %d: %+v, ожидалось %+v", i, payments[i], w)
+		}
+	}
+}
+
+func TestOKXFundingPaymentsPagination(t *testing.T) {
+	const endpoint = "https://www.okx.com/api/v5/account/bills-archive"
+	from := paymentsTo.Add(-30 * 24 * time.Hour)
+	params := func(after string) url.Values {
+		values := url.Values{
+			"instType": {"SWAP"},
+			"type":     {"8"},
+			"limit":    {"100"},
+			"begin":    {millis(from)},
+			"end":      {millis(paymentsTo)},
+		}
+		if after != "" {
+			values.Set("after", after)
+		}
+		return values
+	}
+	bill := func(id int) map[string]string {
+		return map[string]string{
+			"billId": strconv.Itoa(id), "instId": "BTC-USDT-SWAP", "ccy": "USDT", "balChg": "-0.05",
+			"ts": millis(paymentsTo.Add(-time.Duration(id) * time.Hour)),
+		}
+	}
+	var first []map[string]string
+	for id := 1; id <= 100; id++ {
+		first = append(first, bill(id))
+	}
+	wrap := func(data interface{}) map[string]interface{} {
+		return map[string]interface{}{"code": "0", "msg": "", "data": data}
+	}
+
+	// Записи старше 7 дней запрашиваются из архива, страницы — по идентификатору последней записи
+	payments := replayPayments(t, &exchanges.OKX{Credentials: paymentsKeys},
+		exchanges.FundingPaymentsQuery{From: from, To: paymentsTo},
+		jsonFixture(t, endpoint, params(""), wrap(first)),
+		jsonFixture(t, endpoint, params("100"), wrap([]map[string]string{bill(101)})),
+	)
+
+	if len(payments) != 101 {
+		t.Fatalf("начислений %d, ожидалось 101", len(payments))
+	}
+	checkUniqueIDs(t, payments)
+	if last := payments[100]; last.ID != "101" || last.Symbol != "BTC-USDT-SWAP" || last.Amount != -0.05 {
+		t.Errorf("последнее начисление %+v", last)
+	}
+}
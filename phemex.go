@@ -0,0 +1,263 @@
+package exchanges
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type Phemex struct{}
+
+func NewPhemex() *Phemex {
+	log.Println("Инициализация Phemex")
+	return &Phemex{}
+}
+
+func (p *Phemex) GetName() string {
+	return "Phemex"
+}
+
+// Описание продукта Phemex со шкалами целочисленных полей.
+// Структура общая для products и perpProductsV2, часть полей есть только в одном из списков
+type phemexProduct struct {
+	Symbol          string  `json:"symbol"`
+	Type            string  `json:"type"`
+	SettleCurrency  string  `json:"settleCurrency"`
+	QuoteCurrency   string  `json:"quoteCurrency,omitempty"`
+	Status          string  `json:"status"`
+	ContractSize    Decimal `json:"contractSize"` // номинал контракта в валюте котировки
+	PriceScale      int     `json:"priceScale,omitempty"`
+	RatioScale      int     `json:"ratioScale,omitempty"`
+	FundingInterval int64   `json:"fundingInterval,omitempty"` // в perpProductsV2 в секундах, в products в часах
+}
+
+type phemexProductsResponse struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data struct {
+		Currencies []struct {
+			Currency   string `json:"currency"`
+			ValueScale int    `json:"valueScale"`
+		} `json:"currencies"`
+		Products       []phemexProduct `json:"products"`
+		PerpProductsV2 []phemexProduct `json:"perpProductsV2"`
+	} `json:"data"`
+}
+
+// phemexUnscale переводит масштабированное целое значение (поля *Ep/*Er/*Ev) в число
+func phemexUnscale(value int64, scale int) float64 {
+	return phemexUnscaleDecimal(value, scale).Float64()
+}
+
+// phemexUnscaleDecimal переводит масштабированное целое значение в точное десятичное
+func phemexUnscaleDecimal(value int64, scale int) Decimal {
+	return derived(decimal.New(value, -int32(scale)))
+}
+
+// phemexFundingInterval возвращает интервал фандинга продукта perpProductsV2, по умолчанию 8 часов
+func phemexFundingInterval(product phemexProduct) time.Duration {
+	if product.FundingInterval > 0 {
+		return time.Duration(product.FundingInterval) * time.Second
+	}
+	return 8 * time.Hour
+}
+
+// Получаем описание всех продуктов
+func (p *Phemex) getProducts() (*phemexProductsResponse, error) {
+	resp, err := httpGet("https://api.phemex.com/public/products")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var products phemexProductsResponse
+	if err := decodeResponse(p.GetName(), resp, &products); err != nil {
+		return nil, err
+	}
+
+	if products.Code != 0 {
+		return nil, fmt.Errorf("Phemex API ошибка: %d - %s", products.Code, products.Msg)
+	}
+
+	return &products, nil
+}
+
+func (p *Phemex) GetFundingRates() ([]FundingRate, error) {
+	log.Println("Запрос ставок фандинга с Phemex")
+
+	products, err := p.getProducts()
+	if err != nil {
+		log.Printf("Ошибка получения продуктов Phemex: %v", err)
+		return nil, err
+	}
+
+	valueScales := make(map[string]int)
+	for _, currency := range products.Data.Currencies {
+		valueScales[currency.Currency] = currency.ValueScale
+	}
+
+	location := GetLocationFromEnv()
+	now := time.Now()
+	result := make([]FundingRate, 0)
+
+	// USDT-контракты нового типа публикуют вещественные значения (поля *Rp/*Rr/*Rv)
+	hedged := make(map[string]phemexProduct)
+	for _, product := range products.Data.PerpProductsV2 {
+		if product.SettleCurrency == "USDT" && product.Status == "Listed" {
+			hedged[product.Symbol] = product
+		}
+	}
+
+	hedgedResp, err := httpGet("https://api.phemex.com/md/v3/ticker/24hr/all")
+	if err != nil {
+		log.Printf("Ошибка запроса тикеров USDT-контрактов к Phemex: %v", err)
+		return nil, err
+	}
+	defer hedgedResp.Body.Close()
+
+	var hedgedTickers struct {
+		Error  interface{} `json:"error"`
+		Result []struct {
+			Symbol            string `json:"symbol"`
+			FundingRateRr     string `json:"fundingRateRr"`
+			PredFundingRateRr string `json:"predFundingRateRr"`
+			MarkPriceRp       string `json:"markPriceRp"`
+			IndexPriceRp      string `json:"indexPriceRp"`
+			VolumeRq          string `json:"volumeRq"`
+			TurnoverRv        string `json:"turnoverRv"`
+		} `json:"result"`
+	}
+
+	if err := decodeResponse(p.GetName(), hedgedResp, &hedgedTickers); err != nil {
+		log.Printf("Ошибка декодирования тикеров USDT-контрактов от Phemex: %v", err)
+		return nil, err
+	}
+
+	if hedgedTickers.Error != nil {
+		return nil, fmt.Errorf("Phemex API ошибка: %v", hedgedTickers.Error)
+	}
+
+	for _, ticker := range hedgedTickers.Result {
+		product, ok := hedged[ticker.Symbol]
+		if !ok || ticker.FundingRateRr == "" {
+			continue
+		}
+
+		interval := phemexFundingInterval(product)
+		nextFunding := now.Truncate(interval).Add(interval)
+		rate := parseDecimal(ticker.FundingRateRr)
+		predicted := parseDecimal(ticker.PredFundingRateRr)
+		volume := parseDecimal(ticker.VolumeRq)
+		volumeUSDT := parseDecimal(ticker.TurnoverRv)
+
+		result = append(result, FundingRate{
+			Symbol:          ticker.Symbol,
+			Rate:            rate.Float64(),
+			NextFunding:     nextFunding.In(location).Format(time.RFC3339),
+			Volume24h:       volume.Float64(),
+			VolumeUSDT24h:   volumeUSDT.Float64(),
+			PredictedRate:   predicted.Float64(),
+			MarkPrice:       parseFloatFromString(ticker.MarkPriceRp),
+			IndexPrice:      parseFloatFromString(ticker.IndexPriceRp),
+			FundingInterval: interval,
+			Precise:         newPrecise(rate, predicted, volume, volumeUSDT),
+		})
+	}
+
+	// Контракты старого типа публикуют масштабированные целые значения (поля *Ep/*Er/*Ev).
+	// Из них берутся инверсные, например BTCUSD с расчетами в BTC: USD-контракты старого типа
+	// переведены в perpProductsV2
+	legacy := make(map[string]phemexProduct)
+	for _, product := range products.Data.Products {
+		if product.Type == "Perpetual" && product.Status == "Listed" &&
+			product.SettleCurrency != "" && product.SettleCurrency != product.QuoteCurrency {
+			legacy[product.Symbol] = product
+		}
+	}
+
+	if len(legacy) > 0 {
+		legacyRates, err := p.getLegacyRates(legacy, valueScales, now, location)
+		if err != nil {
+			log.Printf("Ошибка получения ставок контрактов старого типа Phemex: %v", err)
+		} else {
+			result = append(result, legacyRates...)
+		}
+	}
+
+	log.Printf("Получено %d ставок фандинга с Phemex", len(result))
+	return result, nil
+}
+
+// getLegacyRates получает ставки инверсных контрактов, значения которых передаются целыми числами
+// со шкалой
+func (p *Phemex) getLegacyRates(products map[string]phemexProduct, valueScales map[string]int, now time.Time, location *time.Location) ([]FundingRate, error) {
+	resp, err := httpGet("https://api.phemex.com/md/ticker/24hr/all")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var tickers struct {
+		Error  interface{} `json:"error"`
+		Result []struct {
+			Symbol            string `json:"symbol"`
+			FundingRateEr     int64  `json:"fundingRateEr"`
+			PredFundingRateEr int64  `json:"predFundingRateEr"`
+			MarkEp            int64  `json:"markEp"`
+			IndexEp           int64  `json:"indexEp"`
+			Volume            int64  `json:"volume"`
+			TurnoverEv        int64  `json:"turnoverEv"`
+		} `json:"result"`
+	}
+
+	if err := decodeResponse(p.GetName(), resp, &tickers); err != nil {
+		return nil, err
+	}
+
+	if tickers.Error != nil {
+		return nil, fmt.Errorf("Phemex API ошибка: %v", tickers.Error)
+	}
+
+	var result []FundingRate
+	for _, ticker := range tickers.Result {
+		product, ok := products[ticker.Symbol]
+		if !ok {
+			continue
+		}
+
+		interval := 8 * time.Hour
+		if product.FundingInterval > 0 {
+			interval = time.Duration(product.FundingInterval) * time.Hour
+		}
+		nextFunding := now.Truncate(interval).Add(interval)
+		rate := phemexUnscaleDecimal(ticker.FundingRateEr, product.RatioScale)
+		predicted := phemexUnscaleDecimal(ticker.PredFundingRateEr, product.RatioScale)
+
+		// Оборот передается в монете расчетов, объем — в контрактах с номиналом в USD
+		volume := phemexUnscaleDecimal(ticker.TurnoverEv, valueScales[product.SettleCurrency])
+		contractSize := product.ContractSize.Value
+		if contractSize.IsZero() {
+			contractSize = decimal.NewFromInt(1)
+		}
+		volumeUSDT := derived(decimal.NewFromInt(ticker.Volume).Mul(contractSize))
+
+		result = append(result, FundingRate{
+			Symbol:          ticker.Symbol,
+			Rate:            rate.Float64(),
+			NextFunding:     nextFunding.In(location).Format(time.RFC3339),
+			Volume24h:       volume.Float64(),
+			VolumeUSDT24h:   volumeUSDT.Float64(),
+			PredictedRate:   predicted.Float64(),
+			MarkPrice:       phemexUnscale(ticker.MarkEp, product.PriceScale),
+			IndexPrice:      phemexUnscale(ticker.IndexEp, product.PriceScale),
+			FundingInterval: interval,
+			SettleAsset:     product.SettleCurrency,
+			MarginType:      MarginInverse,
+			Precise:         newPrecise(rate, predicted, volume, volumeUSDT),
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,107 @@
+package exchanges
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"math"
+)
+
+// PositionSide направление позиции
+type PositionSide string
+
+const (
+	PositionLong  PositionSide = "long"
+	PositionShort PositionSide = "short"
+)
+
+// Position открытая позиция по бессрочному контракту
+type Position struct {
+	Exchange      string
+	Symbol        string // символ в формате биржи, как в GetFundingRates
+	Side          PositionSide
+	Size          float64 // размер в базовой валюте, всегда положительный
+	Notional      float64 // стоимость по маркировочной цене в USD, всегда положительная
+	EntryPrice    float64
+	MarkPrice     float64
+	Leverage      float64
+	UnrealizedPnL float64
+
+	// Заполняются EstimateFunding
+	FundingRate           float64 // ставка ближайшей выплаты
+	NextFunding           string
+	EstimatedFunding      float64 // ожидаемое начисление в USD: больше нуля — получим, меньше нуля — заплатим
+	FundingAsset          string  // актив, в котором начисляется фандинг: монета для инверсных контрактов
+	EstimatedFundingAsset float64 // ожидаемое начисление в FundingAsset; 0, если нет цены для пересчета
+}
+
+// PositionsProvider биржа, отдающая открытые позиции счета
+type PositionsProvider interface {
+	GetName() string
+	GetPositions() ([]Position, error)
+}
+
+// EstimateFunding оценивает начисление фандинга по позициям на ближайшей выплате.
+// rates — ставки той же биржи, полученные через GetFundingRates (например, из кэша).
+// Лонг платит при положительной ставке, шорт получает. По инверсным контрактам фандинг
+// начисляется в монете: сумма в USD пересчитывается по маркировочной цене позиции или ставки
+func EstimateFunding(positions []Position, rates []FundingRate) {
+	bySymbol := make(map[string]FundingRate, len(rates))
+	for _, rate := range rates {
+		bySymbol[rate.Symbol] = rate
+	}
+
+	for i := range positions {
+		rate, ok := bySymbol[positions[i].Symbol]
+		if !ok {
+			continue
+		}
+
+		payment := positions[i].Notional * rate.Rate
+		if positions[i].Side == PositionLong {
+			payment = -payment
+		}
+
+		positions[i].FundingRate = rate.Rate
+		positions[i].NextFunding = rate.NextFunding
+		positions[i].EstimatedFunding = payment
+		positions[i].FundingAsset = rate.SettleAsset
+		positions[i].EstimatedFundingAsset = payment
+
+		if rate.MarginType == MarginInverse {
+			if positions[i].FundingAsset == "" {
+				positions[i].FundingAsset = CanonicalSymbol(rate.Symbol)
+			}
+			markPrice := positions[i].MarkPrice
+			if markPrice <= 0 {
+				markPrice = rate.MarkPrice
+			}
+			positions[i].EstimatedFundingAsset = 0
+			if markPrice > 0 {
+				positions[i].EstimatedFundingAsset = payment / markPrice
+			}
+		}
+	}
+}
+
+// newPosition формирует позицию из размера со знаком; нулевые позиции отбрасываются вызывающим
+func newPosition(exchange, symbol string, signedSize, notional float64) Position {
+	side := PositionLong
+	if signedSize < 0 {
+		side = PositionShort
+	}
+	return Position{
+		Exchange: exchange,
+		Symbol:   symbol,
+		Side:     side,
+		Size:     math.Abs(signedSize),
+		Notional: math.Abs(notional),
+	}
+}
+
+// signHMACSHA512Hex подписывает сообщение HMAC-SHA512 и возвращает подпись в hex
+func signHMACSHA512Hex(secret, message string) string {
+	h := hmac.New(sha512.New, []byte(secret))
+	h.Write([]byte(message))
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,41 @@
+package exchanges_test
+
+import (
+	"testing"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+)
+
+func TestEstimateFunding(t *testing.T) {
+	rates := []exchanges.FundingRate{
+		{Symbol: "BTCUSDT", Rate: 0.0001, SettleAsset: "USDT", MarginType: exchanges.MarginLinear},
+		{Symbol: "BTCUSD_PERP", Rate: 0.0002, SettleAsset: "BTC", MarginType: exchanges.MarginInverse},
+		{Symbol: "ETHUSD", Rate: -0.0001, MarkPrice: 2500, MarginType: exchanges.MarginInverse},
+	}
+	positions := []exchanges.Position{
+		{Symbol: "BTCUSDT", Side: exchanges.PositionLong, Notional: 10000},
+		{Symbol: "BTCUSD_PERP", Side: exchanges.PositionShort, Notional: 50000, MarkPrice: 50000},
+		{Symbol: "ETHUSD", Side: exchanges.PositionShort, Notional: 5000},
+		{Symbol: "SOLUSDT", Side: exchanges.PositionLong, Notional: 1000},
+	}
+
+	exchanges.EstimateFunding(positions, rates)
+
+	tests := []struct {
+		asset        string
+		usd, inAsset float64
+	}{
+		{asset: "USDT", usd: -1, inAsset: -1},
+		// Инверсные контракты: начисление в монете по маркировочной цене позиции или ставки
+		{asset: "BTC", usd: 10, inAsset: 0.0002},
+		{asset: "ETH", usd: -0.5, inAsset: -0.0002},
+		{},
+	}
+	for i, tt := range tests {
+		p := positions[i]
+		if p.FundingAsset != tt.asset || !approxEqual(p.EstimatedFunding, tt.usd) || !approxEqual(p.EstimatedFundingAsset, tt.inAsset) {
+			t.Errorf("%s: начисление %v USD, %v %s; ожидалось %v USD, %v %s", p.Symbol,
+				p.EstimatedFunding, p.EstimatedFundingAsset, p.FundingAsset, tt.usd, tt.inAsset, tt.asset)
+		}
+	}
+}
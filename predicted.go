@@ -0,0 +1,148 @@
+package exchanges
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// PremiumIndexPoint значение индекса премии контракта на закрытии минутной свечи
+type PremiumIndexPoint struct {
+	Time    time.Time
+	Premium float64 // (цена контракта - индекс) / индекс
+}
+
+// PremiumIndexProvider биржа, отдающая минутную историю индекса премии контракта
+type PremiumIndexProvider interface {
+	GetName() string
+	GetPremiumIndex(symbol string, from, to time.Time) ([]PremiumIndexPoint, error)
+}
+
+// PremiumAveraging способ усреднения индекса премии за интервал фандинга
+type PremiumAveraging int
+
+const (
+	AverageTimeWeighted PremiumAveraging = iota // вес точки растет со временем: 1, 2, ..., n (Binance, Bybit)
+	AverageSimple                               // среднее арифметическое
+)
+
+// FundingFormula параметры формулы ставки биржи:
+// F = P + clamp(I - P, -ClampBound, ClampBound), затем F ограничивается диапазоном [Floor, Cap].
+// P — усредненный индекс премии, I — процентная составляющая за интервал фандинга
+type FundingFormula struct {
+	Interval     time.Duration // интервал фандинга, 0 — DefaultFundingInterval
+	InterestRate float64       // процентная составляющая за 8 часов, пересчитывается на Interval
+	ClampBound   float64       // предел |I - P| за 8 часов, пересчитывается на Interval
+	Cap          float64       // максимальная ставка
+	HasCap       bool          // ограничивать ставку сверху значением Cap
+	Floor        float64       // минимальная ставка, в том числе 0
+	HasFloor     bool          // ограничивать ставку снизу значением Floor
+	Averaging    PremiumAveraging
+}
+
+// DefaultFundingFormulas формулы ставок бирж по имени (GetName).
+// Cap и Floor — типичные значения; для отдельных контрактов биржи публикуют свои пределы
+var DefaultFundingFormulas = map[string]FundingFormula{
+	"Binance": {InterestRate: 0.0001, ClampBound: 0.0005, Cap: 0.02, HasCap: true, Floor: -0.02, HasFloor: true, Averaging: AverageTimeWeighted},
+	"Bybit":   {InterestRate: 0.0001, ClampBound: 0.0005, Cap: 0.0075, HasCap: true, Floor: -0.0075, HasFloor: true, Averaging: AverageTimeWeighted},
+	"OKX":     {InterestRate: 0.0001, ClampBound: 0.0005, Cap: 0.0075, HasCap: true, Floor: -0.0075, HasFloor: true, Averaging: AverageSimple},
+	"Gate.io": {InterestRate: 0.0001, ClampBound: 0.0005, Cap: 0.0075, HasCap: true, Floor: -0.0075, HasFloor: true, Averaging: AverageSimple},
+}
+
+// interval возвращает интервал фандинга формулы
+func (f FundingFormula) interval() time.Duration {
+	if f.Interval <= 0 {
+		return DefaultFundingInterval
+	}
+	return f.Interval
+}
+
+// AveragePremium усредняет индекс премии способом формулы
+func (f FundingFormula) AveragePremium(points []PremiumIndexPoint) float64 {
+	var sum, weights float64
+	for i, point := range points {
+		weight := 1.0
+		if f.Averaging == AverageTimeWeighted {
+			weight = float64(i + 1)
+		}
+		sum += point.Premium * weight
+		weights += weight
+	}
+	if weights == 0 {
+		return 0
+	}
+	return sum / weights
+}
+
+// Rate рассчитывает ставку по усредненному индексу премии
+func (f FundingFormula) Rate(premium float64) float64 {
+	scale := float64(f.interval()) / float64(8*time.Hour)
+	interest := f.InterestRate * scale
+	bound := f.ClampBound * scale
+	rate := premium + math.Max(-bound, math.Min(bound, interest-premium))
+	if f.HasCap && rate > f.Cap {
+		rate = f.Cap
+	}
+	if f.HasFloor && rate < f.Floor {
+		rate = f.Floor
+	}
+	return rate
+}
+
+// PredictFundingRate рассчитывает прогнозную ставку ближайшей выплаты по индексу премии текущего интервала.
+// points упорядочены по времени
+func PredictFundingRate(formula FundingFormula, points []PremiumIndexPoint) (float64, error) {
+	if len(points) == 0 {
+		return 0, fmt.Errorf("нет значений индекса премии")
+	}
+	return formula.Rate(formula.AveragePremium(points)), nil
+}
+
+// EstimatePredictedRates заполняет PredictedRate (и Precise.PredictedRate, если точные значения включены)
+// у ставок, для которых биржа его не публикует.
+// Индекс премии запрашивается по каждому символу с начала текущего интервала фандинга до now,
+// интервал берется из FundingInterval ставки. Ошибки отдельных символов записываются в лог;
+// ошибка возвращается, только если не удалось рассчитать ни одной ставки
+func EstimatePredictedRates(provider PremiumIndexProvider, formula FundingFormula, rates []FundingRate, now time.Time) error {
+	var lastErr error
+	estimated := 0
+	for i := range rates {
+		if rates[i].PredictedRate != 0 {
+			continue
+		}
+
+		symbolFormula := formula
+		if rates[i].FundingInterval > 0 {
+			symbolFormula.Interval = rates[i].FundingInterval
+		}
+		from := now.Truncate(symbolFormula.interval())
+		if next, err := time.Parse(time.RFC3339, rates[i].NextFunding); err == nil && next.After(now) {
+			from = next.Add(-symbolFormula.interval())
+		}
+
+		points, err := provider.GetPremiumIndex(rates[i].Symbol, from, now)
+		if err == nil {
+			rates[i].PredictedRate, err = PredictFundingRate(symbolFormula, points)
+		}
+		if err != nil {
+			log.Printf("Ошибка прогноза ставки %s %s: %v", provider.GetName(), rates[i].Symbol, err)
+			lastErr = err
+			continue
+		}
+		// Точные значения строки могут быть общими с копиями ставки, поэтому заменяются копией
+		if rates[i].Precise != nil {
+			precise := *rates[i].Precise
+			precise.PredictedRate = derived(decimal.NewFromFloat(rates[i].PredictedRate))
+			rates[i].Precise = &precise
+		}
+		estimated++
+	}
+
+	if estimated == 0 && lastErr != nil {
+		return lastErr
+	}
+	return nil
+}
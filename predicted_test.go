@@ -0,0 +1,100 @@
+package exchanges_test
+
+import (
+	"testing"
+	"time"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+	"github.com/petrixs/cr-exchanges/exchangetest"
+)
+
+func TestPredictFundingRate(t *testing.T) {
+	binance := exchanges.DefaultFundingFormulas["Binance"]
+	points := func(premiums ...float64) []exchanges.PremiumIndexPoint {
+		start := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+		result := make([]exchanges.PremiumIndexPoint, len(premiums))
+		for i, premium := range premiums {
+			result[i] = exchanges.PremiumIndexPoint{Time: start.Add(time.Duration(i) * time.Minute), Premium: premium}
+		}
+		return result
+	}
+
+	tests := []struct {
+		name    string
+		formula exchanges.FundingFormula
+		points  []exchanges.PremiumIndexPoint
+		want    float64
+	}{
+		{name: "Премия в пределах, ставка равна процентной составляющей", formula: binance, points: points(0.0002, 0.0002), want: 0.0001},
+		{name: "Премия больше предела", formula: binance, points: points(0.002), want: 0.0015},
+		{name: "Отрицательная премия", formula: binance, points: points(-0.001), want: -0.0005},
+		// (0.0001*1 + 0.0016*2) / 3 = 0.0011; простое среднее дало бы 0.00085
+		{name: "Взвешивание по времени", formula: binance, points: points(0.0001, 0.0016), want: 0.0006},
+		{name: "Простое среднее", formula: exchanges.DefaultFundingFormulas["OKX"], points: points(0.0001, 0.0016), want: 0.00035},
+		{name: "Процентная составляющая за 4 часа", formula: exchanges.FundingFormula{Interval: 4 * time.Hour, InterestRate: 0.0001, ClampBound: 0.0005},
+			points: points(0), want: 0.00005},
+		// Предел |I - P| за час — 0.0005 / 8
+		{name: "Предел за 1 час", formula: exchanges.FundingFormula{Interval: time.Hour, InterestRate: 0.0001, ClampBound: 0.0005},
+			points: points(0.001), want: 0.001 - 0.0005/8},
+		{name: "Нулевой нижний предел", formula: exchanges.FundingFormula{InterestRate: 0.0001, ClampBound: 0.0005, HasFloor: true},
+			points: points(-0.001), want: 0},
+		{name: "Без пределов", formula: exchanges.FundingFormula{InterestRate: 0.0001, ClampBound: 0.0005},
+			points: points(-0.5), want: -0.4995},
+		{name: "Ограничение сверху", formula: binance, points: points(0.5), want: 0.02},
+		{name: "Ограничение снизу", formula: binance, points: points(-0.5), want: -0.02},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := exchanges.PredictFundingRate(tt.formula, tt.points)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !approxEqual(got, tt.want) {
+				t.Errorf("ставка %v, ожидалось %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := exchanges.PredictFundingRate(binance, nil); err == nil {
+		t.Error("ожидалась ошибка без значений индекса премии")
+	}
+}
+
+func TestEstimatePredictedRates(t *testing.T) {
+	server := newMockServer(t)
+	for _, venue := range []exchangetest.Venue{exchangetest.VenueBinance, exchangetest.VenueBybit} {
+		server.SetRate(venue, "BTC", exchangetest.MockRate{Rate: 0.0001, Volume: 1000, Price: 50000, Premium: 0.002})
+		server.SetRate(venue, "ETH", exchangetest.MockRate{Rate: 0.0001, Volume: 1000, Price: 2500, Premium: -0.0001})
+	}
+
+	binance := &exchanges.Binance{PredictSymbols: []string{"BTCUSDT"}}
+	rates, err := binance.GetFundingRates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rate := range rates {
+		want := 0.0
+		if rate.Symbol == "BTCUSDT" {
+			want = 0.0015
+		}
+		if !approxEqual(rate.PredictedRate, want) {
+			t.Errorf("Binance %s: прогнозная ставка %v, ожидалось %v", rate.Symbol, rate.PredictedRate, want)
+		}
+	}
+
+	bybit := &exchanges.Bybit{}
+	rates, err = bybit.GetFundingRates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := exchanges.EstimatePredictedRates(bybit, exchanges.DefaultFundingFormulas["Bybit"], rates, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]float64{"BTCUSDT": 0.0015, "ETHUSDT": 0.0001}
+	for _, rate := range rates {
+		if !approxEqual(rate.PredictedRate, want[rate.Symbol]) {
+			t.Errorf("Bybit %s: прогнозная ставка %v, ожидалось %v", rate.Symbol, rate.PredictedRate, want[rate.Symbol])
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package exchanges_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+	"github.com/petrixs/cr-exchanges/exchangetest"
+)
+
+// fetchAll запрашивает ставки всех эмулируемых бирж; время фандинга, вычисляемое клиентом, обнуляется
+func fetchAll(t *testing.T) map[exchangetest.Venue][]exchanges.FundingRate {
+	t.Helper()
+
+	result := make(map[exchangetest.Venue][]exchanges.FundingRate)
+	for _, venue := range exchangetest.Venues() {
+		rates, err := mockExchanges[venue]().GetFundingRates()
+		if err != nil {
+			t.Fatalf("%s: %v", venue, err)
+		}
+		if venue == exchangetest.VenueKuCoin || venue == exchangetest.VenueHyperliquid {
+			for i := range rates {
+				rates[i].NextFunding = ""
+			}
+		}
+		result[venue] = rates
+	}
+	return result
+}
+
+func TestRecordReplayRoundTrip(t *testing.T) {
+	server := newMockServer(t)
+	next := time.Now().Truncate(time.Hour).Add(time.Hour)
+	for _, venue := range exchangetest.Venues() {
+		server.SetRate(venue, "BTC", exchangetest.MockRate{Rate: 0.0001, NextFunding: next, Volume: 1000, Price: 50000})
+		server.SetRate(venue, "SOL", exchangetest.MockRate{Rate: -0.0003, Interval: 4 * time.Hour, Volume: 300000, Price: 150})
+	}
+
+	dir := t.TempDir()
+	recorder, err := exchangetest.NewRecordTransport(dir, server.Client().Transport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exchanges.SetHTTPClient(&http.Client{Transport: recorder})
+	recorded := fetchAll(t)
+
+	fixtures, err := exchangetest.LoadFixtures(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay := exchangetest.NewReplayServer(fixtures...)
+	defer replay.Close()
+	exchanges.SetHTTPClient(replay.Client())
+
+	replayed := fetchAll(t)
+	if unmatched := replay.Unmatched(); len(unmatched) > 0 {
+		t.Errorf("запросы без записанных фикстур: %v", unmatched)
+	}
+	if !reflect.DeepEqual(recorded, replayed) {
+		t.Errorf("воспроизведение отличается от записи\nзаписано: %+v\nвоспроизведено: %+v", recorded, replayed)
+	}
+}
+
+func TestRecordScrubsSecrets(t *testing.T) {
+	server := newMockServer(t)
+
+	dir := t.TempDir()
+	recorder, err := exchangetest.NewRecordTransport(dir, server.Client().Transport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exchanges.SetHTTPClient(&http.Client{Transport: recorder})
+
+	credentials := exchanges.NewStaticCredentials("api-key-0123456789", "secret-0123456789", "passphrase-0123")
+	query := exchanges.FundingPaymentsQuery{From: time.Now().Add(-time.Hour)}
+
+	// Приватные методы мок-сервер не эмулирует: важен только записанный запрос
+	(&exchanges.Binance{Credentials: credentials}).GetFundingPayments(query)
+	(&exchanges.OKX{Credentials: credentials}).GetFundingPayments(query)
+	(&exchanges.Bybit{Credentials: credentials}).GetPositions()
+	(&exchanges.Gate{Credentials: credentials}).GetPositions()
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) < 4 {
+		t.Fatalf("записано %d фикстур, ожидалось не меньше 4", len(paths))
+	}
+
+	var all strings.Builder
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		all.Write(data)
+	}
+	text := all.String()
+
+	for _, secret := range []string{"api-key-0123456789", "secret-0123456789", "passphrase-0123"} {
+		if strings.Contains(text, secret) {
+			t.Errorf("секрет %q попал в фикстуры", secret)
+		}
+	}
+	for _, want := range []string{"signature=REDACTED", `"X-Mbx-Apikey"`, `"Ok-Access-Sign"`, `"X-Bapi-Sign"`, `"Sign"`} {
+		if !strings.Contains(text, want) {
+			t.Errorf("в фикстурах нет %s", want)
+		}
+	}
+	if strings.Contains(text, exchangetest.OriginalHostHeader) {
+		t.Errorf("служебный заголовок %s попал в фикстуры", exchangetest.OriginalHostHeader)
+	}
+}
@@ -0,0 +1,47 @@
+package exchanges
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// exchangeConstructors конструкторы поддерживаемых бирж по короткому имени
+var exchangeConstructors = map[string]func() Exchange{
+	"aevo":         func() Exchange { return NewAevo() },
+	"binance":      func() Exchange { return NewBinance() },
+	"bingx":        func() Exchange { return NewBingX() },
+	"bitfinex":     func() Exchange { return NewBitfinex() },
+	"bybit":        func() Exchange { return NewBybit() },
+	"coinbaseintl": func() Exchange { return NewCoinbaseIntl() },
+	"gate":         func() Exchange { return NewGate() },
+	"htx":          func() Exchange { return NewHTX() },
+	"hyperliquid":  func() Exchange { return NewHyperliquid() },
+	"kucoin":       func() Exchange { return NewKuCoin() },
+	"mexc":         func() Exchange { return NewMEXC() },
+	"okx":          func() Exchange { return NewOKX() },
+	"paradex":      func() Exchange { return NewParadex() },
+	"phemex":       func() Exchange { return NewPhemex() },
+	"woox":         func() Exchange { return NewWOOX() },
+}
+
+// ExchangeNames возвращает отсортированные короткие имена поддерживаемых бирж
+func ExchangeNames() []string {
+	names := make([]string, 0, len(exchangeConstructors))
+	for name := range exchangeConstructors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewExchangeByName создает биржу по короткому имени (binance, gate, ...) или по имени,
+// которое возвращает GetName (Gate.io, ...), без учета регистра
+func NewExchangeByName(name string) (Exchange, error) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	key = strings.TrimSuffix(key, ".io")
+	if constructor, ok := exchangeConstructors[key]; ok {
+		return constructor(), nil
+	}
+	return nil, fmt.Errorf("неизвестная биржа %s, доступны: %s", name, strings.Join(ExchangeNames(), ", "))
+}
@@ -0,0 +1,579 @@
+package exchanges
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SchemaMode режим проверки схемы ответов биржи
+type SchemaMode int
+
+const (
+	SchemaOff    SchemaMode = iota // без проверки (по умолчанию)
+	SchemaWarn                     // расхождения пишутся в лог и в отчет
+	SchemaStrict                   // пропавшие поля и смена типа дополнительно возвращаются ошибкой SchemaError
+)
+
+func (m SchemaMode) String() string {
+	switch m {
+	case SchemaWarn:
+		return "warn"
+	case SchemaStrict:
+		return "strict"
+	default:
+		return "off"
+	}
+}
+
+// DriftKind вид расхождения ответа с ожидаемой схемой
+type DriftKind string
+
+const (
+	DriftUnknownField DriftKind = "unknown_field" // поле ответа, которое клиент не разбирает
+	DriftMissingField DriftKind = "missing_field" // ожидаемое поле не пришло ни в одном объекте
+	DriftTypeChange   DriftKind = "type_change"   // тип значения не совпадает с ожидаемым
+)
+
+// Breaking сообщает, ломает ли расхождение разбор: пропавшее поле молча дает 0,
+// смена типа — ошибку или 0. Новые поля разбору не мешают
+func (k DriftKind) Breaking() bool {
+	return k == DriftMissingField || k == DriftTypeChange
+}
+
+// SchemaDrift расхождение ответа биржи с ожидаемой схемой
+type SchemaDrift struct {
+	Exchange  string
+	Endpoint  string // путь запроса, сегменты с символами заменены на {symbol}
+	Field     string // путь поля, например result.list[].turnover24h
+	Kind      DriftKind
+	Expected  string // ожидаемый тип JSON для type_change
+	Got       string // полученный тип JSON для type_change
+	Count     uint64 // в скольких ответах встречено
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+func (d SchemaDrift) String() string {
+	switch d.Kind {
+	case DriftTypeChange:
+		return fmt.Sprintf("%s %s: тип %s вместо %s", d.Endpoint, d.Field, d.Got, d.Expected)
+	case DriftMissingField:
+		return fmt.Sprintf("%s %s: поле не пришло", d.Endpoint, d.Field)
+	default:
+		return fmt.Sprintf("%s %s: новое поле", d.Endpoint, d.Field)
+	}
+}
+
+// SchemaError ошибка строгой проверки: ответ расходится с ожидаемой схемой
+type SchemaError struct {
+	Exchange string
+	Endpoint string
+	Drifts   []SchemaDrift
+}
+
+func (e *SchemaError) Error() string {
+	parts := make([]string, len(e.Drifts))
+	for i, drift := range e.Drifts {
+		parts[i] = drift.Field + " (" + string(drift.Kind) + ")"
+	}
+	return fmt.Sprintf("%s: ответ %s не соответствует схеме: %s", e.Exchange, e.Endpoint, strings.Join(parts, ", "))
+}
+
+// SchemaReport накопленные результаты проверки схемы ответов биржи
+type SchemaReport struct {
+	Exchange  string
+	Mode      SchemaMode
+	Responses uint64        // число проверенных ответов
+	Drifts    []SchemaDrift // различные расхождения, по эндпоинту и полю
+	NotSeen   []string      // ожидаемые поля, не встреченные ни в одном ответе, в виде "эндпоинт поле"
+}
+
+// Breaking возвращает расхождения, ломающие разбор
+func (r SchemaReport) Breaking() []SchemaDrift {
+	var result []SchemaDrift
+	for _, drift := range r.Drifts {
+		if drift.Kind.Breaking() {
+			result = append(result, drift)
+		}
+	}
+	return result
+}
+
+// schemaEndpointFields ожидаемые и встреченные поля одного эндпоинта
+type schemaEndpointFields struct {
+	expected map[string]bool
+	seen     map[string]bool
+}
+
+type schemaExchangeState struct {
+	responses uint64
+	drifts    map[string]*SchemaDrift // ключ: эндпоинт|поле|вид
+	endpoints map[string]*schemaEndpointFields
+}
+
+type schemaMonitor struct {
+	mu          sync.Mutex
+	defaultMode SchemaMode
+	modes       map[string]SchemaMode
+	exchanges   map[string]*schemaExchangeState
+}
+
+var globalSchema = &schemaMonitor{
+	modes:     make(map[string]SchemaMode),
+	exchanges: make(map[string]*schemaExchangeState),
+}
+
+// SetSchemaMode задает режим проверки схемы для биржи (имя как в GetName)
+func SetSchemaMode(exchange string, mode SchemaMode) {
+	globalSchema.mu.Lock()
+	globalSchema.modes[exchange] = mode
+	globalSchema.mu.Unlock()
+}
+
+// SetDefaultSchemaMode задает режим проверки схемы для бирж без отдельной настройки
+func SetDefaultSchemaMode(mode SchemaMode) {
+	globalSchema.mu.Lock()
+	globalSchema.defaultMode = mode
+	globalSchema.mu.Unlock()
+}
+
+// GetSchemaReport возвращает накопленный отчет о схеме ответов биржи
+func GetSchemaReport(exchange string) SchemaReport {
+	globalSchema.mu.Lock()
+	defer globalSchema.mu.Unlock()
+	return globalSchema.report(exchange)
+}
+
+// GetSchemaReports возвращает отчеты всех проверявшихся бирж
+func GetSchemaReports() map[string]SchemaReport {
+	globalSchema.mu.Lock()
+	defer globalSchema.mu.Unlock()
+
+	reports := make(map[string]SchemaReport, len(globalSchema.exchanges))
+	for exchange := range globalSchema.exchanges {
+		reports[exchange] = globalSchema.report(exchange)
+	}
+	return reports
+}
+
+// ResetSchemaReports очищает накопленные отчеты, режимы проверки сохраняются
+func ResetSchemaReports() {
+	globalSchema.mu.Lock()
+	globalSchema.exchanges = make(map[string]*schemaExchangeState)
+	globalSchema.mu.Unlock()
+}
+
+// mode возвращает режим биржи; вызывается под m.mu
+func (m *schemaMonitor) mode(exchange string) SchemaMode {
+	if mode, ok := m.modes[exchange]; ok {
+		return mode
+	}
+	return m.defaultMode
+}
+
+// report собирает отчет биржи; вызывается под m.mu
+func (m *schemaMonitor) report(exchange string) SchemaReport {
+	report := SchemaReport{Exchange: exchange, Mode: m.mode(exchange)}
+	state, ok := m.exchanges[exchange]
+	if !ok {
+		return report
+	}
+
+	report.Responses = state.responses
+	for _, drift := range state.drifts {
+		report.Drifts = append(report.Drifts, *drift)
+	}
+	sort.Slice(report.Drifts, func(i, j int) bool {
+		a, b := report.Drifts[i], report.Drifts[j]
+		if a.Endpoint != b.Endpoint {
+			return a.Endpoint < b.Endpoint
+		}
+		if a.Field != b.Field {
+			return a.Field < b.Field
+		}
+		return a.Kind < b.Kind
+	})
+
+	for endpoint, fields := range state.endpoints {
+		for field := range fields.expected {
+			if !fields.seen[field] {
+				report.NotSeen = append(report.NotSeen, endpoint+" "+field)
+			}
+		}
+	}
+	sort.Strings(report.NotSeen)
+	return report
+}
+
+// record добавляет результат проверки одного ответа и возвращает впервые встреченные расхождения
+func (m *schemaMonitor) record(exchange, endpoint string, check *schemaCheck, at time.Time) []SchemaDrift {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.exchanges[exchange]
+	if !ok {
+		state = &schemaExchangeState{
+			drifts:    make(map[string]*SchemaDrift),
+			endpoints: make(map[string]*schemaEndpointFields),
+		}
+		m.exchanges[exchange] = state
+	}
+	state.responses++
+
+	fields, ok := state.endpoints[endpoint]
+	if !ok {
+		fields = &schemaEndpointFields{expected: make(map[string]bool), seen: make(map[string]bool)}
+		state.endpoints[endpoint] = fields
+	}
+	for field := range check.expected {
+		fields.expected[field] = true
+	}
+	for field := range check.seen {
+		fields.seen[field] = true
+	}
+
+	var fresh []SchemaDrift
+	for _, drift := range check.drifts() {
+		key := endpoint + "|" + drift.Field + "|" + string(drift.Kind)
+		known, ok := state.drifts[key]
+		if !ok {
+			drift.Exchange = exchange
+			drift.Endpoint = endpoint
+			drift.FirstSeen = at
+			known = &drift
+			state.drifts[key] = known
+			fresh = append(fresh, drift)
+		}
+		known.Count++
+		known.LastSeen = at
+		known.Expected, known.Got = drift.Expected, drift.Got
+	}
+	return fresh
+}
+
+// decodeResponse декодирует JSON-ответ биржи в out и, если для биржи включена проверка схемы,
+// сравнивает ответ со структурой out
+func decodeResponse(exchange string, resp *http.Response, out interface{}) error {
+	if schemaMode(exchange) == SchemaOff {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	endpoint := ""
+	if resp.Request != nil && resp.Request.URL != nil {
+		endpoint = schemaEndpoint(resp.Request.URL.Path)
+	}
+	return decodeChecked(exchange, endpoint, "", data, out)
+}
+
+// decodeChecked декодирует data в out с проверкой схемы. field — путь data внутри ответа,
+// например data для конверта {"code":0,"data":...}
+func decodeChecked(exchange, endpoint, field string, data []byte, out interface{}) error {
+	mode := schemaMode(exchange)
+	if mode == SchemaOff {
+		return json.Unmarshal(data, out)
+	}
+
+	var breaking []SchemaDrift
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if decoder.Decode(&value) == nil {
+		check := newSchemaCheck()
+		check.walk(reflect.TypeOf(out), value, field)
+		check.finish()
+
+		for _, drift := range globalSchema.record(exchange, endpoint, check, time.Now()) {
+			log.Printf("Схема ответа %s изменилась: %s", exchange, drift)
+		}
+		for _, drift := range check.drifts() {
+			if drift.Kind.Breaking() {
+				drift.Exchange, drift.Endpoint = exchange, endpoint
+				breaking = append(breaking, drift)
+			}
+		}
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return err
+	}
+	if mode == SchemaStrict && len(breaking) > 0 {
+		return &SchemaError{Exchange: exchange, Endpoint: endpoint, Drifts: breaking}
+	}
+	return nil
+}
+
+func schemaMode(exchange string) SchemaMode {
+	globalSchema.mu.Lock()
+	defer globalSchema.mu.Unlock()
+	return globalSchema.mode(exchange)
+}
+
+// schemaEndpoint заменяет сегменты пути без строчных букв (символы инструментов, например
+// BTC_USDT) на {symbol}, чтобы ответы по разным символам считались одним эндпоинтом
+func schemaEndpoint(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if segment != "" && strings.ToLower(segment) != segment && strings.ToUpper(segment) == segment {
+			segments[i] = "{symbol}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// schemaCheck результат сравнения одного ответа со структурой разбора
+type schemaCheck struct {
+	expected map[string]bool   // обязательные поля структуры
+	parents  map[string]string // путь поля -> путь объекта, в котором оно ожидается
+	objects  map[string]bool   // пути объектов, встреченных в ответе
+	seen     map[string]bool   // встреченные ожидаемые поля
+	found    map[string]SchemaDrift
+}
+
+func newSchemaCheck() *schemaCheck {
+	return &schemaCheck{
+		expected: make(map[string]bool),
+		parents:  make(map[string]string),
+		objects:  make(map[string]bool),
+		seen:     make(map[string]bool),
+		found:    make(map[string]SchemaDrift),
+	}
+}
+
+func (c *schemaCheck) add(drift SchemaDrift) {
+	key := drift.Field + "|" + string(drift.Kind)
+	if _, ok := c.found[key]; !ok {
+		c.found[key] = drift
+	}
+}
+
+// finish отмечает пропавшие поля: ожидаемые в объекте, который пришел, но ни разу не встреченные
+func (c *schemaCheck) finish() {
+	for field := range c.expected {
+		if !c.seen[field] && c.objects[c.parents[field]] {
+			c.add(SchemaDrift{Field: field, Kind: DriftMissingField})
+		}
+	}
+}
+
+func (c *schemaCheck) drifts() []SchemaDrift {
+	result := make([]SchemaDrift, 0, len(c.found))
+	for _, drift := range c.found {
+		result = append(result, drift)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Field != result[j].Field {
+			return result[i].Field < result[j].Field
+		}
+		return result[i].Kind < result[j].Kind
+	})
+	return result
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	jsonNumberType      = reflect.TypeOf(json.Number(""))
+	decimalType         = reflect.TypeOf(Decimal{})
+)
+
+// schemaField поле структуры так, как его видит encoding/json
+type schemaField struct {
+	name     string
+	typ      reflect.Type
+	quoted   bool // опция ,string: число передается строкой
+	optional bool // опция omitempty: поле может отсутствовать
+}
+
+// schemaFields возвращает поля структуры с учетом тегов json и встроенных структур
+func schemaFields(t reflect.Type) []schemaField {
+	var fields []schemaField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			embedded := f.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				fields = append(fields, schemaFields(embedded)...)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, schemaField{
+			name:     name,
+			typ:      f.Type,
+			quoted:   strings.Contains(","+options+",", ",string,"),
+			optional: strings.Contains(","+options+",", ",omitempty,"),
+		})
+	}
+	return fields
+}
+
+// walk сравнивает значение JSON с типом Go, в который оно декодируется
+func (c *schemaCheck) walk(t reflect.Type, value interface{}, path string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if value == nil {
+		return
+	}
+	// Decimal, как и json.Number, принимает и число, и строку
+	if t == decimalType {
+		if kind := jsonKind(value); kind != "number" && kind != "string" {
+			c.typeChange(path, "number", value)
+		}
+		return
+	}
+	if t.Kind() == reflect.Interface || reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			c.typeChange(path, "object", value)
+			return
+		}
+		c.objects[path] = true
+
+		fields := schemaFields(t)
+		matched := make(map[string]bool, len(object))
+		for _, field := range fields {
+			fieldPath := joinSchemaPath(path, field.name)
+			if !field.optional {
+				c.expected[fieldPath] = true
+				c.parents[fieldPath] = path
+			}
+
+			key, ok := lookupJSONKey(object, field.name)
+			if !ok {
+				continue
+			}
+			matched[key] = true
+			c.seen[fieldPath] = true
+
+			if field.quoted {
+				if _, isString := object[key].(string); !isString && object[key] != nil {
+					c.typeChange(fieldPath, "string", object[key])
+				}
+				continue
+			}
+			c.walk(field.typ, object[key], fieldPath)
+		}
+
+		for key := range object {
+			if !matched[key] {
+				c.add(SchemaDrift{Field: joinSchemaPath(path, key), Kind: DriftUnknownField})
+			}
+		}
+	case reflect.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			c.typeChange(path, "object", value)
+			return
+		}
+		for _, item := range object {
+			c.walk(t.Elem(), item, path+".*")
+		}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			c.expectKind(path, "string", value)
+			return
+		}
+		list, ok := value.([]interface{})
+		if !ok {
+			c.typeChange(path, "array", value)
+			return
+		}
+		for _, item := range list {
+			c.walk(t.Elem(), item, path+"[]")
+		}
+	case reflect.String:
+		if t == jsonNumberType {
+			if _, isNumber := value.(json.Number); !isNumber {
+				c.expectKind(path, "string", value)
+			}
+			return
+		}
+		c.expectKind(path, "string", value)
+	case reflect.Bool:
+		c.expectKind(path, "bool", value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		c.expectKind(path, "number", value)
+	}
+}
+
+func (c *schemaCheck) expectKind(path, expected string, value interface{}) {
+	if jsonKind(value) != expected {
+		c.typeChange(path, expected, value)
+	}
+}
+
+func (c *schemaCheck) typeChange(path, expected string, value interface{}) {
+	c.add(SchemaDrift{Field: path, Kind: DriftTypeChange, Expected: expected, Got: jsonKind(value)})
+}
+
+// jsonKind тип значения JSON, декодированного с UseNumber
+func jsonKind(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "bool"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// lookupJSONKey ищет ключ объекта так же, как encoding/json: точное совпадение, затем без учета регистра
+func lookupJSONKey(object map[string]interface{}, name string) (string, bool) {
+	if _, ok := object[name]; ok {
+		return name, true
+	}
+	for key := range object {
+		if strings.EqualFold(key, name) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+func joinSchemaPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
@@ -0,0 +1,118 @@
+package exchanges_test
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+	"github.com/petrixs/cr-exchanges/exchangetest"
+)
+
+// replayFixtures воспроизводит фикстуры сценария, заменяя в телах ответов old на new
+func replayFixtures(t *testing.T, scenario, old, new string) {
+	t.Helper()
+
+	fixtures, err := exchangetest.LoadFixtures(filepath.Join("testdata", "fixtures", scenario))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range fixtures {
+		if old == "" {
+			break
+		}
+		fixtures[i].Body = bytes.ReplaceAll(fixtures[i].Body, []byte(old), []byte(new))
+	}
+
+	server := exchangetest.NewReplayServer(fixtures...)
+	exchanges.SetHTTPClient(server.Client())
+	t.Cleanup(func() {
+		exchanges.SetHTTPClient(nil)
+		server.Close()
+	})
+}
+
+func withSchemaMode(t *testing.T, exchange string, mode exchanges.SchemaMode) {
+	t.Helper()
+
+	exchanges.ResetSchemaReports()
+	exchanges.SetSchemaMode(exchange, mode)
+	t.Cleanup(func() {
+		exchanges.SetSchemaMode(exchange, exchanges.SchemaOff)
+		exchanges.ResetSchemaReports()
+	})
+}
+
+func findDrift(report exchanges.SchemaReport, field string, kind exchanges.DriftKind) (exchanges.SchemaDrift, bool) {
+	for _, drift := range report.Drifts {
+		if drift.Field == field && drift.Kind == kind {
+			return drift, true
+		}
+	}
+	return exchanges.SchemaDrift{}, false
+}
+
+func TestSchemaUnchangedResponse(t *testing.T) {
+	withSchemaMode(t, "Bybit", exchanges.SchemaStrict)
+	replayFixtures(t, "bybit/ok", "", "")
+
+	if _, err := (&exchanges.Bybit{}).GetFundingRates(); err != nil {
+		t.Fatal(err)
+	}
+
+	report := exchanges.GetSchemaReport("Bybit")
+	if report.Responses != 2 {
+		t.Errorf("проверено ответов %d, ожидалось 2", report.Responses)
+	}
+	if breaking := report.Breaking(); len(breaking) > 0 {
+		t.Errorf("неожиданные расхождения: %v", breaking)
+	}
+	// Поля, которые клиент не разбирает, попадают в отчет как новые
+	if _, ok := findDrift(report, "result.list[].lastPrice", exchanges.DriftUnknownField); !ok {
+		t.Errorf("нет нового поля lastPrice в отчете: %v", report.Drifts)
+	}
+	if len(report.NotSeen) > 0 {
+		t.Errorf("не встречены поля: %v", report.NotSeen)
+	}
+}
+
+func TestSchemaRenamedField(t *testing.T) {
+	withSchemaMode(t, "Bybit", exchanges.SchemaStrict)
+	replayFixtures(t, "bybit/ok", `"turnover24h"`, `"turnover_24h"`)
+
+	_, err := (&exchanges.Bybit{}).GetFundingRates()
+	var schemaErr *exchanges.SchemaError
+	if !errors.As(err, &schemaErr) {
+		t.Fatalf("ожидалась ошибка схемы, получено %v", err)
+	}
+	if schemaErr.Endpoint != "/v5/market/tickers" || len(schemaErr.Drifts) != 1 || schemaErr.Drifts[0].Field != "result.list[].turnover24h" {
+		t.Errorf("ошибка схемы %+v", schemaErr)
+	}
+
+	report := exchanges.GetSchemaReport("Bybit")
+	if _, ok := findDrift(report, "result.list[].turnover_24h", exchanges.DriftUnknownField); !ok {
+		t.Errorf("нет нового поля turnover_24h в отчете: %v", report.Drifts)
+	}
+	want := "/v5/market/tickers result.list[].turnover24h"
+	if strings.Join(report.NotSeen, "\n") != want {
+		t.Errorf("не встречены поля %v, ожидалось %s", report.NotSeen, want)
+	}
+}
+
+func TestSchemaTypeChangeWarn(t *testing.T) {
+	withSchemaMode(t, "MEXC", exchanges.SchemaWarn)
+	replayFixtures(t, "mexc/ok", `"collectCycle": 8`, `"collectCycle": "8h"`)
+
+	// В режиме предупреждений ошибки разбора остаются прежними, расхождение попадает в отчет
+	(&exchanges.MEXC{}).GetFundingRates()
+
+	drift, ok := findDrift(exchanges.GetSchemaReport("MEXC"), "data.collectCycle", exchanges.DriftTypeChange)
+	if !ok {
+		t.Fatalf("нет смены типа collectCycle в отчете: %v", exchanges.GetSchemaReport("MEXC").Drifts)
+	}
+	if drift.Endpoint != "/api/v1/contract/funding_rate/{symbol}" || drift.Expected != "number" || drift.Got != "string" || drift.Count != 1 {
+		t.Errorf("расхождение %+v", drift)
+	}
+}
@@ -0,0 +1,289 @@
+package exchanges
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// FundingPoint ставка одного периода фандинга
+type FundingPoint struct {
+	Time time.Time // время выплаты
+	Rate float64   // ставка, приведенная к 8 часам
+}
+
+// FundingSeries ряд ставок контракта биржи по периодам фандинга в порядке времени
+type FundingSeries struct {
+	Exchange string
+	Symbol   string
+	Points   []FundingPoint
+}
+
+// BuildFundingSeries строит ряды ставок по записям истории (см. SQLiteStore.Query).
+// Ставка периода — последний снимок, сделанный до выплаты; периоды, выплата по которым
+// еще не наступила к now, не включаются. Если биржа не сообщает время выплаты,
+// оно вычисляется как ближайшая граница интервала после снимка
+func BuildFundingSeries(records []HistoryRecord, now time.Time) []FundingSeries {
+	type key struct{ exchange, symbol string }
+	periods := make(map[key]map[time.Time]FundingPoint)
+
+	for _, record := range records {
+		paidAt, ok := settlementTime(record)
+		if !ok {
+			continue
+		}
+		if paidAt.After(now) || record.CapturedAt.After(paidAt) {
+			continue
+		}
+
+		k := key{record.Exchange, record.Symbol}
+		if periods[k] == nil {
+			periods[k] = make(map[time.Time]FundingPoint)
+		}
+		// Записи упорядочены по времени снимка, поэтому более поздний снимок заменяет ранний
+		periods[k][paidAt] = FundingPoint{Time: paidAt, Rate: record.Rate8h()}
+	}
+
+	result := make([]FundingSeries, 0, len(periods))
+	for k, points := range periods {
+		series := FundingSeries{Exchange: k.exchange, Symbol: k.symbol, Points: make([]FundingPoint, 0, len(points))}
+		for _, point := range points {
+			series.Points = append(series.Points, point)
+		}
+		sort.Slice(series.Points, func(i, j int) bool { return series.Points[i].Time.Before(series.Points[j].Time) })
+		result = append(result, series)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Exchange != result[j].Exchange {
+			return result[i].Exchange < result[j].Exchange
+		}
+		return result[i].Symbol < result[j].Symbol
+	})
+	return result
+}
+
+// settlementTime возвращает время выплаты, к которой относится снимок.
+// Выплаты бирж приходятся на начало минуты; время не на границе минуты вычислено от момента
+// запроса (так KuCoin и BingX заполняли NextFunding в ранних версиях) и заменяется границей интервала,
+// иначе каждый снимок считался бы отдельным периодом
+func settlementTime(record HistoryRecord) (time.Time, bool) {
+	boundary := record.CapturedAt.Truncate(record.Interval()).Add(record.Interval())
+	if record.NextFunding == "" {
+		return boundary, true
+	}
+	next, err := time.Parse(time.RFC3339, record.NextFunding)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if !next.Truncate(time.Minute).Equal(next) {
+		return boundary, true
+	}
+	return next, true
+}
+
+// Rates возвращает ставки ряда
+func (s FundingSeries) Rates() []float64 {
+	rates := make([]float64, len(s.Points))
+	for i, point := range s.Points {
+		rates[i] = point.Rate
+	}
+	return rates
+}
+
+// SMA простое скользящее среднее последних n значений, n <= 0 — всех
+func SMA(values []float64, n int) float64 {
+	values = lastValues(values, n)
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// EMA экспоненциальное скользящее среднее с периодом n (коэффициент 2 / (n + 1)),
+// начальное значение — первое значение ряда
+func EMA(values []float64, n int) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	if n <= 0 {
+		n = len(values)
+	}
+	alpha := 2 / float64(n+1)
+	ema := values[0]
+	for _, v := range values[1:] {
+		ema = alpha*v + (1-alpha)*ema
+	}
+	return ema
+}
+
+// StdDev выборочное стандартное отклонение последних n значений, n <= 0 — всех
+func StdDev(values []float64, n int) float64 {
+	values = lastValues(values, n)
+	if len(values) < 2 {
+		return 0
+	}
+	mean := SMA(values, 0)
+	var sum float64
+	for _, v := range values {
+		sum += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(sum / float64(len(values)-1))
+}
+
+// ZScore отклонение value от среднего values в стандартных отклонениях; 0, если разброса нет
+func ZScore(value float64, values []float64) float64 {
+	std := StdDev(values, 0)
+	if std == 0 {
+		return 0
+	}
+	return (value - SMA(values, 0)) / std
+}
+
+// SignPersistence число последних значений подряд с тем же знаком, что у последнего
+func SignPersistence(values []float64) int {
+	if len(values) == 0 {
+		return 0
+	}
+	sign := signOf(values[len(values)-1])
+	count := 0
+	for i := len(values) - 1; i >= 0 && signOf(values[i]) == sign; i-- {
+		count++
+	}
+	return count
+}
+
+func signOf(v float64) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+	return 0
+}
+
+// lastValues возвращает последние n значений, n <= 0 — все
+func lastValues(values []float64, n int) []float64 {
+	if n > 0 && len(values) > n {
+		return values[len(values)-n:]
+	}
+	return values
+}
+
+// FundingStatsOptions параметры расчета статистики ставок
+type FundingStatsOptions struct {
+	Lookback time.Duration // окно истории для z-score, 0 — 30 дней
+	Periods  int           // число периодов для SMA, EMA и волатильности, 0 — 21 (неделя при интервале 8 часов)
+}
+
+func (o FundingStatsOptions) withDefaults() FundingStatsOptions {
+	if o.Lookback <= 0 {
+		o.Lookback = 30 * 24 * time.Hour
+	}
+	if o.Periods <= 0 {
+		o.Periods = 21
+	}
+	return o
+}
+
+// FundingStats статистика ставки контракта биржи. Все ставки приведены к 8 часам
+type FundingStats struct {
+	Exchange    string
+	Symbol      string
+	Current     float64 // текущая ставка
+	Periods     int     // значений в окне истории, включая текущее
+	SMA         float64 // простое среднее последних Periods значений, включая текущее
+	EMA         float64 // экспоненциальное среднее с периодом Periods
+	Volatility  float64 // стандартное отклонение последних Periods значений
+	ZScore      float64 // отклонение текущей ставки от ставок окна Lookback
+	Percentile  float64 // процентильный ранг текущей ставки среди всех контрактов рынка, от 0 до 1
+	Persistence int     // периодов подряд, включая текущий, с тем же знаком ставки
+}
+
+// CalculateFundingStats рассчитывает статистику по рядам ставок (см. BuildFundingSeries).
+// current — текущие ставки бирж (например, RatesCache.GetAllRates); для контрактов без текущей
+// ставки текущей считается последняя выплата. Результат отсортирован по бирже и символу
+func CalculateFundingStats(series []FundingSeries, current map[string][]FundingRate, options FundingStatsOptions, now time.Time) []FundingStats {
+	options = options.withDefaults()
+	from := now.Add(-options.Lookback)
+
+	type key struct{ exchange, symbol string }
+	currentRates := make(map[key]float64)
+	for exchange, rates := range current {
+		for _, rate := range rates {
+			currentRates[key{exchange, rate.Symbol}] = rate.Rate8h()
+		}
+	}
+
+	var result []FundingStats
+	for _, s := range series {
+		var history []float64
+		for _, point := range s.Points {
+			if !point.Time.Before(from) {
+				history = append(history, point.Rate)
+			}
+		}
+
+		values := history
+		value, ok := currentRates[key{s.Exchange, s.Symbol}]
+		if ok {
+			values = append(append([]float64(nil), history...), value)
+		} else if len(history) > 0 {
+			value = history[len(history)-1]
+			history = history[:len(history)-1]
+		} else {
+			continue
+		}
+
+		result = append(result, FundingStats{
+			Exchange:    s.Exchange,
+			Symbol:      s.Symbol,
+			Current:     value,
+			Periods:     len(values),
+			SMA:         SMA(values, options.Periods),
+			EMA:         EMA(lastValues(values, options.Periods), options.Periods),
+			Volatility:  StdDev(values, options.Periods),
+			ZScore:      ZScore(value, history),
+			Persistence: SignPersistence(values),
+		})
+	}
+
+	rankPercentiles(result)
+	return result
+}
+
+// rankPercentiles заполняет Percentile: доля остальных контрактов со ставкой ниже текущей
+func rankPercentiles(stats []FundingStats) {
+	if len(stats) < 2 {
+		return
+	}
+
+	sorted := make([]float64, len(stats))
+	for i, s := range stats {
+		sorted[i] = s.Current
+	}
+	sort.Float64s(sorted)
+
+	for i := range stats {
+		below := sort.SearchFloat64s(sorted, stats[i].Current)
+		stats[i].Percentile = float64(below) / float64(len(stats)-1)
+	}
+}
+
+// LoadFundingStats рассчитывает статистику по истории хранилища за окно options.Lookback
+// и текущим ставкам current (например, RatesCache.GetAllRates)
+func LoadFundingStats(store *SQLiteStore, current map[string][]FundingRate, options FundingStatsOptions, now time.Time) ([]FundingStats, error) {
+	options = options.withDefaults()
+	// Неизменившиеся ставки не записываются повторно, поэтому ставка первой выплаты окна
+	// может быть сохранена раньше его начала
+	records, err := store.Query(HistoryQuery{From: now.Add(-options.Lookback - 24*time.Hour), To: now})
+	if err != nil {
+		return nil, err
+	}
+	return CalculateFundingStats(BuildFundingSeries(records, now), current, options, now), nil
+}
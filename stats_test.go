@@ -0,0 +1,108 @@
+package exchanges_test
+
+import (
+	"math"
+	"sort"
+	"testing"
+	"time"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+)
+
+func TestCalculateFundingStats(t *testing.T) {
+	now := time.Date(2026, 5, 10, 1, 0, 0, 0, time.UTC)
+	start := now.Truncate(8 * time.Hour).Add(-5 * 8 * time.Hour)
+
+	// BTC: пять выплат, ставка растет; ETH: знак сменился две выплаты назад; SOL на бирже с часовым интервалом
+	history := map[string][]float64{
+		"BTCUSDT": {0.0001, 0.0001, 0.0002, 0.0002, 0.0004},
+		"ETHUSDT": {0.0001, 0.0001, 0.0001, -0.0001, -0.0002},
+	}
+	var records []exchanges.HistoryRecord
+	for symbol, rates := range history {
+		for i, rate := range rates {
+			paidAt := start.Add(time.Duration(i+1) * 8 * time.Hour)
+			// Два снимка за период: учитывается последний
+			for _, captured := range []time.Time{paidAt.Add(-4 * time.Hour), paidAt.Add(-time.Minute)} {
+				value := rate
+				if captured.Before(paidAt.Add(-time.Hour)) {
+					value = 0.5
+				}
+				records = append(records, exchanges.HistoryRecord{Exchange: "Binance", CapturedAt: captured, FundingRate: exchanges.FundingRate{
+					Symbol: symbol, Rate: value, NextFunding: paidAt.Format(time.RFC3339), FundingInterval: 8 * time.Hour,
+				}})
+			}
+		}
+	}
+	// Снимок текущего периода не считается выплатой
+	records = append(records, exchanges.HistoryRecord{Exchange: "Binance", CapturedAt: now, FundingRate: exchanges.FundingRate{
+		Symbol: "BTCUSDT", Rate: 0.9, NextFunding: now.Truncate(8 * time.Hour).Add(8 * time.Hour).Format(time.RFC3339), FundingInterval: 8 * time.Hour,
+	}})
+	// Ставка без времени выплаты относится к ближайшей границе интервала
+	records = append(records, exchanges.HistoryRecord{Exchange: "Hyperliquid", CapturedAt: now.Add(-90 * time.Minute), FundingRate: exchanges.FundingRate{
+		Symbol: "SOL", Rate: 0.00001, FundingInterval: time.Hour,
+	}})
+	// Записи из хранилища упорядочены по времени снимка
+	sort.SliceStable(records, func(i, j int) bool { return records[i].CapturedAt.Before(records[j].CapturedAt) })
+
+	series := exchanges.BuildFundingSeries(records, now)
+	if len(series) != 3 || len(series[0].Points) != 5 || series[2].Symbol != "SOL" || len(series[2].Points) != 1 {
+		t.Fatalf("ряды %+v", series)
+	}
+	if got := series[0].Rates(); got[4] != 0.0004 {
+		t.Errorf("ставки BTC %v", got)
+	}
+	if series[2].Points[0].Time != now.Add(-time.Hour) || !approxEqual(series[2].Points[0].Rate, 0.00008) {
+		t.Errorf("выплата SOL %+v", series[2].Points[0])
+	}
+
+	current := map[string][]exchanges.FundingRate{
+		"Binance": {{Symbol: "BTCUSDT", Rate: 0.0006, FundingInterval: 8 * time.Hour}},
+	}
+	stats := exchanges.CalculateFundingStats(series, current, exchanges.FundingStatsOptions{Periods: 3}, now)
+	if len(stats) != 3 {
+		t.Fatalf("статистика %+v", stats)
+	}
+
+	btc := stats[0]
+	// Окно SMA: 0.0002, 0.0004, 0.0006; история для z-score: среднее 0.0002, отклонение 0.0001 * sqrt(1.5)
+	if btc.Current != 0.0006 || btc.Periods != 6 || !approxEqual(btc.SMA, 0.0004) || !approxEqual(btc.Volatility, 0.0002) ||
+		!approxEqual(btc.ZScore, 4/math.Sqrt(1.5)) || btc.Persistence != 6 || btc.Percentile != 1 {
+		t.Errorf("BTC %+v", btc)
+	}
+	// EMA с периодом 3 (коэффициент 0.5) от 0.0002: 0.0003, затем 0.00045
+	if !approxEqual(btc.EMA, 0.00045) {
+		t.Errorf("EMA BTC %v", btc.EMA)
+	}
+
+	// Без текущей ставки текущей считается последняя выплата
+	eth := stats[1]
+	if eth.Current != -0.0002 || eth.Persistence != 2 || eth.Percentile != 0 {
+		t.Errorf("ETH %+v", eth)
+	}
+	if sol := stats[2]; !approxEqual(sol.Percentile, 0.5) || sol.ZScore != 0 {
+		t.Errorf("SOL %+v", sol)
+	}
+}
+
+func TestBuildFundingSeriesComputedNextFunding(t *testing.T) {
+	now := time.Date(2026, 5, 10, 1, 0, 0, 0, time.UTC)
+
+	// Время выплаты, вычисленное от момента запроса, заменяется границей интервала:
+	// три снимка относятся к двум выплатам, а не к трем
+	var records []exchanges.HistoryRecord
+	for _, captured := range []time.Time{now.Add(-150 * time.Minute), now.Add(-140 * time.Minute), now.Add(-100 * time.Minute)} {
+		records = append(records, exchanges.HistoryRecord{Exchange: "KuCoin", CapturedAt: captured, FundingRate: exchanges.FundingRate{
+			Symbol: "XBTUSDTM", Rate: 0.0001, NextFunding: captured.Add(time.Hour + 7*time.Second).Format(time.RFC3339), FundingInterval: time.Hour,
+		}})
+	}
+
+	series := exchanges.BuildFundingSeries(records, now)
+	if len(series) != 1 {
+		t.Fatalf("ряды %+v", series)
+	}
+	points := series[0].Points
+	if len(points) != 2 || points[0].Time != now.Add(-2*time.Hour) || points[1].Time != now.Add(-time.Hour) {
+		t.Errorf("выплаты %+v", points)
+	}
+}
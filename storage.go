@@ -0,0 +1,364 @@
+package exchanges
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// RateStore сохраняет снимки ставок, полученные при обновлении кэша
+type RateStore interface {
+	SaveRates(exchange string, rates []FundingRate, capturedAt time.Time) error
+}
+
+// HistoryQuery задает выборку из истории ставок. Пустые поля не ограничивают выборку
+type HistoryQuery struct {
+	Exchange string
+	Symbol   string
+	From     time.Time // включительно
+	To       time.Time // не включительно
+	Limit    int       // 0 — без ограничения
+}
+
+// HistoryRecord ставка фандинга с биржей и временем снимка
+type HistoryRecord struct {
+	Exchange   string
+	CapturedAt time.Time
+	FundingRate
+}
+
+// RetentionPolicy задает срок хранения истории
+type RetentionPolicy struct {
+	MaxAge      time.Duration            // срок хранения для всех бирж, 0 — бессрочно
+	PerExchange map[string]time.Duration // срок хранения для отдельных бирж
+}
+
+// maxAge возвращает срок хранения для биржи
+func (p RetentionPolicy) maxAge(exchange string) time.Duration {
+	if age, ok := p.PerExchange[exchange]; ok {
+		return age
+	}
+	return p.MaxAge
+}
+
+// SQLiteStore хранит историю ставок во встроенной базе SQLite
+type SQLiteStore struct {
+	db        *sql.DB
+	mu        sync.Mutex
+	retention RetentionPolicy
+	last      map[string]map[string]FundingRate // последний сохраненный снимок: биржа -> символ -> ставка
+}
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS funding_rates (
+	id               INTEGER PRIMARY KEY AUTOINCREMENT,
+	exchange         TEXT    NOT NULL,
+	symbol           TEXT    NOT NULL,
+	rate             REAL    NOT NULL,
+	next_funding     TEXT    NOT NULL,
+	volume_24h       REAL    NOT NULL,
+	volume_usdt_24h  REAL    NOT NULL,
+	predicted_rate   REAL    NOT NULL DEFAULT 0,
+	mark_price       REAL    NOT NULL DEFAULT 0,
+	index_price      REAL    NOT NULL DEFAULT 0,
+	funding_interval INTEGER NOT NULL DEFAULT 0,
+	open_interest    REAL    NOT NULL DEFAULT 0,
+	settle_asset     TEXT    NOT NULL DEFAULT '',
+	margin_type      TEXT    NOT NULL DEFAULT '',
+	captured_at      INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS funding_rates_lookup ON funding_rates (exchange, symbol, captured_at);
+CREATE INDEX IF NOT EXISTS funding_rates_captured ON funding_rates (captured_at);
+`
+
+const sqliteColumns = `exchange, symbol, rate, next_funding, volume_24h, volume_usdt_24h, predicted_rate,
+	mark_price, index_price, funding_interval, open_interest, settle_asset, margin_type, captured_at`
+
+// NewSQLiteStore открывает (или создает) базу SQLite по указанному пути
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия базы %s: %v", path, err)
+	}
+
+	// SQLite допускает одного писателя, поэтому используем одно соединение
+	db.SetMaxOpenConns(1)
+
+	for _, pragma := range []string{"PRAGMA journal_mode=WAL", "PRAGMA busy_timeout=5000"} {
+		if _, err := db.Exec(pragma); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("ошибка настройки базы %s: %v", path, err)
+		}
+	}
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ошибка создания схемы базы %s: %v", path, err)
+	}
+
+	return &SQLiteStore{
+		db:   db,
+		last: make(map[string]map[string]FundingRate),
+	}, nil
+}
+
+// Close закрывает базу
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// SetRetention задает политику хранения, применяемую при каждой записи
+func (s *SQLiteStore) SetRetention(policy RetentionPolicy) {
+	s.mu.Lock()
+	s.retention = policy
+	s.mu.Unlock()
+}
+
+// SaveRates добавляет снимок ставок биржи. Ставки, не изменившиеся с предыдущего
+// сохраненного снимка (см. sameFundingRate), не записываются повторно
+func (s *SQLiteStore) SaveRates(exchange string, rates []FundingRate, capturedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	last, err := s.lastSnapshot(exchange)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Очистка выполняется до записи: если она удалила последнюю строку неизменившейся ставки,
+	// снимок перечитывается и ставка записывается заново, иначе символ пропал бы из истории
+	if age := s.retention.maxAge(exchange); age > 0 {
+		cutoff := capturedAt.Add(-age).UnixMilli()
+		res, err := tx.Exec(`DELETE FROM funding_rates WHERE exchange = ? AND captured_at < ?`, exchange, cutoff)
+		if err != nil {
+			return fmt.Errorf("ошибка очистки истории %s: %v", exchange, err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			if last, err = querySnapshot(tx, exchange); err != nil {
+				return err
+			}
+		}
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO funding_rates (` + sqliteColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	saved := make(map[string]FundingRate, len(rates))
+	for _, rate := range rates {
+		if prev, ok := last[rate.Symbol]; ok && sameFundingRate(prev, rate) {
+			saved[rate.Symbol] = prev
+			continue
+		}
+
+		_, err := stmt.Exec(exchange, rate.Symbol, rate.Rate, rate.NextFunding, rate.Volume24h, rate.VolumeUSDT24h,
+			rate.PredictedRate, rate.MarkPrice, rate.IndexPrice, int64(rate.FundingInterval), rate.OpenInterest,
+			rate.SettleAsset, string(rate.MarginType), capturedAt.UnixMilli())
+		if err != nil {
+			return fmt.Errorf("ошибка записи ставки %s %s: %v", exchange, rate.Symbol, err)
+		}
+		saved[rate.Symbol] = rate
+	}
+
+	if err := tx.Commit(); err != nil {
+		// Снимок мог быть перечитан внутри отмененной транзакции
+		delete(s.last, exchange)
+		return err
+	}
+
+	// Делистинговые символы остаются в снимке: при повторном листинге с теми же
+	// значениями строка не будет записана заново
+	for symbol, rate := range saved {
+		last[symbol] = rate
+	}
+	s.last[exchange] = last
+	return nil
+}
+
+// lastSnapshot возвращает последние сохраненные ставки биржи, при первом обращении читая их из базы
+func (s *SQLiteStore) lastSnapshot(exchange string) (map[string]FundingRate, error) {
+	if last, ok := s.last[exchange]; ok {
+		return last, nil
+	}
+
+	last, err := querySnapshot(s.db, exchange)
+	if err != nil {
+		return nil, err
+	}
+	s.last[exchange] = last
+	return last, nil
+}
+
+// querier общий метод *sql.DB и *sql.Tx
+type querier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// querySnapshot читает из базы последнюю сохраненную ставку каждого символа биржи
+func querySnapshot(q querier, exchange string) (map[string]FundingRate, error) {
+	rows, err := q.Query(`SELECT `+sqliteColumns+` FROM funding_rates f
+		WHERE exchange = ? AND captured_at = (
+			SELECT MAX(captured_at) FROM funding_rates WHERE exchange = f.exchange AND symbol = f.symbol
+		)`, exchange)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения последнего снимка %s: %v", exchange, err)
+	}
+	defer rows.Close()
+
+	last := make(map[string]FundingRate)
+	for rows.Next() {
+		record, err := scanHistoryRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		last[record.Symbol] = record.FundingRate
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return last, nil
+}
+
+// ApplyRetention удаляет записи старше сроков хранения для всех бирж
+func (s *SQLiteStore) ApplyRetention(now time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted int64
+	var except []interface{}
+	for exchange, age := range s.retention.PerExchange {
+		except = append(except, exchange)
+		if age <= 0 {
+			continue
+		}
+		res, err := s.db.Exec(`DELETE FROM funding_rates WHERE exchange = ? AND captured_at < ?`, exchange, now.Add(-age).UnixMilli())
+		if err != nil {
+			return deleted, err
+		}
+		n, _ := res.RowsAffected()
+		deleted += n
+	}
+
+	if s.retention.MaxAge > 0 {
+		query := `DELETE FROM funding_rates WHERE captured_at < ?`
+		args := []interface{}{now.Add(-s.retention.MaxAge).UnixMilli()}
+		if len(except) > 0 {
+			query += ` AND exchange NOT IN (?` + strings.Repeat(", ?", len(except)-1) + `)`
+			args = append(args, except...)
+		}
+		res, err := s.db.Exec(query, args...)
+		if err != nil {
+			return deleted, err
+		}
+		n, _ := res.RowsAffected()
+		deleted += n
+	}
+
+	// Кэш последних снимков мог ссылаться на удаленные строки
+	s.last = make(map[string]map[string]FundingRate)
+	return deleted, nil
+}
+
+// Query возвращает записи истории в порядке времени снимка
+func (s *SQLiteStore) Query(q HistoryQuery) ([]HistoryRecord, error) {
+	var result []HistoryRecord
+	err := s.Iterate(q, func(record HistoryRecord) error {
+		result = append(result, record)
+		return nil
+	})
+	return result, err
+}
+
+// Iterate последовательно передает записи истории в fn, не загружая выборку в память целиком.
+// Внутри fn нельзя вызывать другие методы хранилища
+func (s *SQLiteStore) Iterate(q HistoryQuery, fn func(HistoryRecord) error) error {
+	query := `SELECT ` + sqliteColumns + ` FROM funding_rates WHERE 1 = 1`
+	var args []interface{}
+
+	if q.Exchange != "" {
+		query += ` AND exchange = ?`
+		args = append(args, q.Exchange)
+	}
+	if q.Symbol != "" {
+		query += ` AND symbol = ?`
+		args = append(args, q.Symbol)
+	}
+	if !q.From.IsZero() {
+		query += ` AND captured_at >= ?`
+		args = append(args, q.From.UnixMilli())
+	}
+	if !q.To.IsZero() {
+		query += ` AND captured_at < ?`
+		args = append(args, q.To.UnixMilli())
+	}
+	query += ` ORDER BY captured_at, id`
+	if q.Limit > 0 {
+		query += fmt.Sprintf(` LIMIT %d`, q.Limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("ошибка чтения истории: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		record, err := scanHistoryRecord(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// scanHistoryRecord читает строку, выбранную со списком колонок sqliteColumns
+func scanHistoryRecord(rows *sql.Rows) (HistoryRecord, error) {
+	var record HistoryRecord
+	var interval, capturedAt int64
+	var marginType string
+
+	err := rows.Scan(&record.Exchange, &record.Symbol, &record.Rate, &record.NextFunding, &record.Volume24h,
+		&record.VolumeUSDT24h, &record.PredictedRate, &record.MarkPrice, &record.IndexPrice, &interval,
+		&record.OpenInterest, &record.SettleAsset, &marginType, &capturedAt)
+	if err != nil {
+		return record, fmt.Errorf("ошибка чтения записи истории: %v", err)
+	}
+
+	record.FundingInterval = time.Duration(interval)
+	record.MarginType = MarginType(marginType)
+	record.CapturedAt = time.UnixMilli(capturedAt)
+	return record, nil
+}
+
+// sameFundingRate сообщает, что строка не изменилась: сравниваются все сохраняемые поля, поэтому
+// по истории строятся и графики объема, цены и открытого интереса. Повторный опрос без новых данных
+// (например, кэшированный ответ биржи) не записывается
+func sameFundingRate(a, b FundingRate) bool {
+	return a.Symbol == b.Symbol &&
+		a.Rate == b.Rate &&
+		a.NextFunding == b.NextFunding &&
+		a.FundingInterval == b.FundingInterval &&
+		a.Volume24h == b.Volume24h &&
+		a.VolumeUSDT24h == b.VolumeUSDT24h &&
+		a.PredictedRate == b.PredictedRate &&
+		a.MarkPrice == b.MarkPrice &&
+		a.IndexPrice == b.IndexPrice &&
+		a.OpenInterest == b.OpenInterest &&
+		a.SettleAsset == b.SettleAsset &&
+		a.MarginType == b.MarginType
+}
@@ -0,0 +1,135 @@
+package exchanges_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+)
+
+func newTestStore(t *testing.T) *exchanges.SQLiteStore {
+	t.Helper()
+
+	store, err := exchanges.NewSQLiteStore(filepath.Join(t.TempDir(), "rates.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// historySymbols возвращает символы записей истории в порядке выборки
+func historySymbols(t *testing.T, store *exchanges.SQLiteStore, q exchanges.HistoryQuery) []string {
+	t.Helper()
+
+	records, err := store.Query(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	symbols := make([]string, 0, len(records))
+	for _, record := range records {
+		symbols = append(symbols, record.Symbol)
+	}
+	return symbols
+}
+
+func TestSQLiteStoreDedup(t *testing.T) {
+	store := newTestStore(t)
+	start := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	next := "2026-05-01T08:00:00Z"
+
+	snapshots := [][]exchanges.FundingRate{
+		{{Symbol: "BTCUSDT", Rate: 0.0001, NextFunding: next, Volume24h: 100}, {Symbol: "ETHUSDT", Rate: 0.0002, NextFunding: next}},
+		// Изменились объем и цена BTC: строка нужна для графиков объема и цены
+		{{Symbol: "BTCUSDT", Rate: 0.0001, NextFunding: next, Volume24h: 250, MarkPrice: 60000}, {Symbol: "ETHUSDT", Rate: 0.0002, NextFunding: next}},
+		// Повтор без изменений не записывается
+		{{Symbol: "BTCUSDT", Rate: 0.0001, NextFunding: next, Volume24h: 250, MarkPrice: 60000}, {Symbol: "ETHUSDT", Rate: 0.0002, NextFunding: next}},
+		// Изменились ставка ETH и открытый интерес BTC
+		{{Symbol: "BTCUSDT", Rate: 0.0001, NextFunding: next, Volume24h: 250, MarkPrice: 60000, OpenInterest: 10}, {Symbol: "ETHUSDT", Rate: 0.0003, NextFunding: next}},
+		// Новый период BTC
+		{{Symbol: "BTCUSDT", Rate: 0.0001, NextFunding: "2026-05-01T16:00:00Z", Volume24h: 250, MarkPrice: 60000, OpenInterest: 10}},
+	}
+	for i, rates := range snapshots {
+		if err := store.SaveRates("Binance", rates, start.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := historySymbols(t, store, exchanges.HistoryQuery{Exchange: "Binance"})
+	want := []string{"BTCUSDT", "ETHUSDT", "BTCUSDT", "BTCUSDT", "ETHUSDT", "BTCUSDT"}
+	if len(got) != len(want) {
+		t.Fatalf("записи %v, ожидалось %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("записи %v, ожидалось %v", got, want)
+		}
+	}
+}
+
+func TestSQLiteStoreRetentionKeepsUnchangedRates(t *testing.T) {
+	store := newTestStore(t)
+	store.SetRetention(exchanges.RetentionPolicy{MaxAge: time.Hour})
+	start := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	btc := exchanges.FundingRate{Symbol: "BTCUSDT", Rate: 0.0001, NextFunding: "2026-05-01T08:00:00Z"}
+
+	// Ставка не меняется дольше срока хранения: ее единственная строка удаляется очисткой
+	for _, offset := range []time.Duration{0, 30 * time.Minute, 90 * time.Minute, 3 * time.Hour} {
+		if err := store.SaveRates("Binance", []exchanges.FundingRate{btc}, start.Add(offset)); err != nil {
+			t.Fatal(err)
+		}
+		records, err := store.Query(exchanges.HistoryQuery{Exchange: "Binance"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 1 || records[0].Symbol != "BTCUSDT" {
+			t.Fatalf("через %s в истории %+v, ожидалась одна запись BTCUSDT", offset, records)
+		}
+	}
+}
+
+func TestSQLiteStoreQuery(t *testing.T) {
+	store := newTestStore(t)
+	start := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		rates := []exchanges.FundingRate{
+			{Symbol: "BTCUSDT", Rate: 0.0001 * float64(i+1), FundingInterval: 8 * time.Hour, MarginType: exchanges.MarginLinear},
+			{Symbol: "ETHUSDT", Rate: 0.0002 * float64(i+1)},
+		}
+		if err := store.SaveRates("Binance", rates, start.Add(time.Duration(i)*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.SaveRates("Bybit", rates[:1], start.Add(time.Duration(i)*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query exchanges.HistoryQuery
+		want  int
+	}{
+		{name: "Вся история", query: exchanges.HistoryQuery{}, want: 9},
+		{name: "Биржа", query: exchanges.HistoryQuery{Exchange: "Bybit"}, want: 3},
+		{name: "Символ", query: exchanges.HistoryQuery{Exchange: "Binance", Symbol: "ETHUSDT"}, want: 3},
+		{name: "Период", query: exchanges.HistoryQuery{From: start.Add(time.Hour), To: start.Add(2 * time.Hour)}, want: 3},
+		{name: "Ограничение", query: exchanges.HistoryQuery{Limit: 2}, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := historySymbols(t, store, tt.query); len(got) != tt.want {
+				t.Errorf("записей %d, ожидалось %d", len(got), tt.want)
+			}
+		})
+	}
+
+	records, err := store.Query(exchanges.HistoryQuery{Exchange: "Binance", Symbol: "BTCUSDT", Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := records[0]
+	if record.Rate != 0.0001 || record.FundingInterval != 8*time.Hour || record.MarginType != exchanges.MarginLinear || !record.CapturedAt.Equal(start) {
+		t.Errorf("запись %+v", record)
+	}
+}
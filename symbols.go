@@ -0,0 +1,44 @@
+package exchanges
+
+import "strings"
+
+// Суффиксы котируемой валюты в слитных символах (BTCUSDT, XBTUSDTM, ...)
+var quoteSuffixes = []string{"USDTM", "USDCM", "USDM", "USDT", "USDC", "USD"}
+
+// CanonicalSymbol приводит символ биржи к базовому активу, общему для всех бирж:
+// BTCUSDT, BTC_USDT, BTC-USDT-SWAP, XBTUSDTM, PERP_BTC_USDT, tBTCF0:USTF0, BTC-USD-PERP -> BTC
+func CanonicalSymbol(symbol string) string {
+	// Bitfinex: tBTCF0:USTF0
+	if strings.HasPrefix(symbol, "t") {
+		if i := strings.Index(symbol, "F0:"); i > 0 {
+			return normalizeBaseAsset(symbol[1:i])
+		}
+	}
+
+	s := strings.ToUpper(symbol)
+	s = strings.TrimPrefix(s, "PERP_")
+	for _, suffix := range []string{"-SWAP", "-PERP", "_PERP"} {
+		s = strings.TrimSuffix(s, suffix)
+	}
+
+	if i := strings.IndexAny(s, "-_"); i > 0 {
+		return normalizeBaseAsset(s[:i])
+	}
+
+	for _, suffix := range quoteSuffixes {
+		if len(s) > len(suffix) && strings.HasSuffix(s, suffix) {
+			return normalizeBaseAsset(strings.TrimSuffix(s, suffix))
+		}
+	}
+
+	return normalizeBaseAsset(s)
+}
+
+// normalizeBaseAsset заменяет биржевые обозначения актива общепринятыми
+func normalizeBaseAsset(asset string) string {
+	asset = strings.ToUpper(asset)
+	if asset == "XBT" {
+		return "BTC"
+	}
+	return asset
+}
@@ -0,0 +1,282 @@
+package exchanges
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// ValidationCheck вид проверки качества ставок
+type ValidationCheck string
+
+const (
+	CheckNaN            ValidationCheck = "nan"             // NaN или бесконечность в числовых полях
+	CheckRateBounds     ValidationCheck = "rate_bounds"     // модуль ставки больше MaxAbsRate
+	CheckNextFunding    ValidationCheck = "next_funding"    // время выплаты не разбирается, в прошлом или слишком далеко
+	CheckDuplicate      ValidationCheck = "duplicate"       // символ повторяется в снимке
+	CheckNegativeVolume ValidationCheck = "negative_volume" // отрицательный объем или открытый интерес
+)
+
+// ValidationChecks все виды проверок в порядке выполнения
+var ValidationChecks = []ValidationCheck{CheckNaN, CheckRateBounds, CheckNextFunding, CheckDuplicate, CheckNegativeVolume}
+
+// ValidationAction действие со строкой, не прошедшей проверку
+type ValidationAction int
+
+const (
+	ActionFlag   ValidationAction = iota // строка остается, проблема записывается в FundingRate.Issues
+	ActionDrop                           // строка удаляется из снимка
+	ActionReject                         // снимок отклоняется целиком, в кэше остаются прежние ставки
+	ActionIgnore                         // проверка отключена
+)
+
+func (a ValidationAction) String() string {
+	switch a {
+	case ActionDrop:
+		return "drop"
+	case ActionReject:
+		return "reject"
+	case ActionIgnore:
+		return "ignore"
+	default:
+		return "flag"
+	}
+}
+
+// ValidationRules правила проверки снимков ставок
+type ValidationRules struct {
+	MaxAbsRate      float64                              // предел модуля ставки за интервал, 0 — без ограничения
+	MaxFundingAhead time.Duration                        // насколько далеко вперед может быть время выплаты, 0 — без ограничения
+	MaxFundingLag   time.Duration                        // насколько время выплаты может быть в прошлом
+	Actions         map[ValidationCheck]ValidationAction // действие по виду проверки, по умолчанию ActionFlag
+}
+
+// DefaultValidationRules правила, которые кэш применяет, пока не заданы свои.
+// Строки с NaN и повторы символов удаляются, остальные проблемы только отмечаются
+func DefaultValidationRules() ValidationRules {
+	return ValidationRules{
+		MaxAbsRate:      0.05,
+		MaxFundingAhead: 24 * time.Hour,
+		MaxFundingLag:   10 * time.Minute,
+		Actions: map[ValidationCheck]ValidationAction{
+			CheckNaN:       ActionDrop,
+			CheckDuplicate: ActionDrop,
+		},
+	}
+}
+
+func (r ValidationRules) action(check ValidationCheck) ValidationAction {
+	return r.Actions[check]
+}
+
+// ValidationIssue проблема одной строки снимка
+type ValidationIssue struct {
+	Symbol string
+	Check  ValidationCheck
+	Detail string
+	Action ValidationAction
+}
+
+func (i ValidationIssue) String() string {
+	return fmt.Sprintf("%s %s: %s", i.Symbol, i.Check, i.Detail)
+}
+
+// ValidationReport результат проверки последнего снимка биржи
+type ValidationReport struct {
+	Exchange string
+	Time     time.Time
+	Rows     int  // строк в ответе биржи
+	Kept     int  // строк осталось в снимке
+	Dropped  int  // строк удалено
+	Flagged  int  // строк оставлено с отметкой
+	Rejected bool // снимок отклонен целиком
+	Counts   map[ValidationCheck]int
+	Issues   []ValidationIssue
+}
+
+// ValidationError снимок ставок отклонен проверкой
+type ValidationError struct {
+	Report ValidationReport
+}
+
+func (e *ValidationError) Error() string {
+	var rejected []string
+	for _, issue := range e.Report.Issues {
+		if issue.Action == ActionReject {
+			rejected = append(rejected, issue.String())
+		}
+	}
+	if len(rejected) > 3 {
+		rejected = append(rejected[:3], fmt.Sprintf("и еще %d", len(rejected)-3))
+	}
+	return fmt.Sprintf("%s: снимок ставок отклонен проверкой: %s", e.Report.Exchange, strings.Join(rejected, "; "))
+}
+
+// ValidateRates проверяет снимок ставок биржи и применяет к проблемным строкам действия из rules.
+// Возвращает оставшиеся строки и отчет; если сработало ActionReject, строки не возвращаются,
+// а ошибка имеет тип *ValidationError. GetFundingRates бирж данные не проверяет: при работе
+// без кэша снимок передается сюда явно
+func ValidateRates(exchange string, rates []FundingRate, rules ValidationRules, now time.Time) ([]FundingRate, ValidationReport, error) {
+	report := ValidationReport{
+		Exchange: exchange,
+		Time:     now,
+		Rows:     len(rates),
+		Counts:   make(map[ValidationCheck]int),
+	}
+
+	kept := make([]FundingRate, 0, len(rates))
+	seen := make(map[string]bool, len(rates))
+	for _, rate := range rates {
+		issues := rules.checkRate(rate, now)
+		if seen[rate.Symbol] {
+			issues = append(issues, ValidationIssue{Check: CheckDuplicate, Detail: "символ уже есть в снимке"})
+		}
+		seen[rate.Symbol] = true
+
+		action := ActionIgnore
+		for _, issue := range issues {
+			issue.Symbol = rate.Symbol
+			issue.Action = rules.action(issue.Check)
+			if issue.Action == ActionIgnore {
+				continue
+			}
+			report.Counts[issue.Check]++
+			report.Issues = append(report.Issues, issue)
+			if action == ActionIgnore || issue.Action > action {
+				action = issue.Action
+			}
+			if issue.Action == ActionFlag {
+				rate.Issues = append(rate.Issues, issue.Check)
+			}
+		}
+
+		switch action {
+		case ActionReject:
+			report.Rejected = true
+		case ActionDrop:
+			report.Dropped++
+			continue
+		case ActionFlag:
+			report.Flagged++
+		}
+		kept = append(kept, rate)
+	}
+
+	if report.Rejected {
+		report.Flagged = 0
+		report.Dropped = len(rates)
+		return nil, report, &ValidationError{Report: report}
+	}
+	report.Kept = len(kept)
+	return kept, report, nil
+}
+
+// checkRate проверяет одну строку, кроме повторов символа
+func (r ValidationRules) checkRate(rate FundingRate, now time.Time) []ValidationIssue {
+	var issues []ValidationIssue
+
+	fields := []struct {
+		name  string
+		value float64
+	}{
+		{"rate", rate.Rate},
+		{"predicted_rate", rate.PredictedRate},
+		{"volume_24h", rate.Volume24h},
+		{"volume_usdt_24h", rate.VolumeUSDT24h},
+		{"mark_price", rate.MarkPrice},
+		{"index_price", rate.IndexPrice},
+		{"open_interest", rate.OpenInterest},
+	}
+	var invalid []string
+	for _, field := range fields {
+		if math.IsNaN(field.value) || math.IsInf(field.value, 0) {
+			invalid = append(invalid, field.name)
+		}
+	}
+	if len(invalid) > 0 {
+		issues = append(issues, ValidationIssue{Check: CheckNaN, Detail: strings.Join(invalid, ", ")})
+	}
+
+	if r.MaxAbsRate > 0 {
+		if math.Abs(rate.Rate) > r.MaxAbsRate {
+			issues = append(issues, ValidationIssue{Check: CheckRateBounds, Detail: fmt.Sprintf("ставка %g", rate.Rate)})
+		} else if math.Abs(rate.PredictedRate) > r.MaxAbsRate {
+			issues = append(issues, ValidationIssue{Check: CheckRateBounds, Detail: fmt.Sprintf("прогнозная ставка %g", rate.PredictedRate)})
+		}
+	}
+
+	// Пустое время означает, что биржа его не сообщает
+	if rate.NextFunding != "" {
+		next, err := time.Parse(time.RFC3339, rate.NextFunding)
+		switch {
+		case err != nil:
+			issues = append(issues, ValidationIssue{Check: CheckNextFunding, Detail: fmt.Sprintf("время %q не разбирается", rate.NextFunding)})
+		case next.Before(now.Add(-r.MaxFundingLag)):
+			issues = append(issues, ValidationIssue{Check: CheckNextFunding, Detail: fmt.Sprintf("время %s в прошлом", rate.NextFunding)})
+		case r.MaxFundingAhead > 0 && next.After(now.Add(r.MaxFundingAhead)):
+			issues = append(issues, ValidationIssue{Check: CheckNextFunding, Detail: fmt.Sprintf("время %s дальше %s", rate.NextFunding, r.MaxFundingAhead)})
+		}
+	}
+
+	if rate.Volume24h < 0 || rate.VolumeUSDT24h < 0 || rate.OpenInterest < 0 {
+		issues = append(issues, ValidationIssue{Check: CheckNegativeVolume,
+			Detail: fmt.Sprintf("объем %g, объем USDT %g, открытый интерес %g", rate.Volume24h, rate.VolumeUSDT24h, rate.OpenInterest)})
+	}
+
+	return issues
+}
+
+// SetValidationRules задает правила проверки снимков, получаемых UpdateRates
+func (c *RatesCache) SetValidationRules(rules ValidationRules) {
+	c.Mu.Lock()
+	c.validation = &rules
+	c.Mu.Unlock()
+}
+
+// validationRules возвращает правила кэша, вызывается под c.Mu
+func (c *RatesCache) validationRules() ValidationRules {
+	if c.validation == nil {
+		return DefaultValidationRules()
+	}
+	return *c.validation
+}
+
+// GetValidationReport возвращает отчет о проверке последнего снимка биржи
+func (c *RatesCache) GetValidationReport(exchange string) (ValidationReport, bool) {
+	c.Mu.RLock()
+	defer c.Mu.RUnlock()
+	report, ok := c.validationReports[exchange]
+	return report, ok
+}
+
+// GetValidationReports возвращает отчеты о проверке последних снимков всех бирж
+func (c *RatesCache) GetValidationReports() map[string]ValidationReport {
+	c.Mu.RLock()
+	defer c.Mu.RUnlock()
+
+	result := make(map[string]ValidationReport, len(c.validationReports))
+	for k, v := range c.validationReports {
+		result[k] = v
+	}
+	return result
+}
+
+// saveValidationReport сохраняет отчет, вызывается под c.Mu
+func (c *RatesCache) saveValidationReport(report ValidationReport) {
+	if c.validationReports == nil {
+		c.validationReports = make(map[string]ValidationReport)
+	}
+	c.validationReports[report.Exchange] = report
+}
+
+// issueSummary кратко описывает проблемы отчета для лога, например "nan: 2, duplicate: 1"
+func (r ValidationReport) issueSummary() string {
+	var parts []string
+	for _, check := range ValidationChecks {
+		if count := r.Counts[check]; count > 0 {
+			parts = append(parts, fmt.Sprintf("%s: %d", check, count))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
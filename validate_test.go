@@ -0,0 +1,178 @@
+package exchanges_test
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	exchanges "github.com/petrixs/cr-exchanges"
+)
+
+// staticExchange биржа, возвращающая заданный снимок ставок
+type staticExchange struct {
+	name  string
+	rates []exchanges.FundingRate
+}
+
+func (e *staticExchange) GetName() string { return e.name }
+
+func (e *staticExchange) GetFundingRates() ([]exchanges.FundingRate, error) {
+	return e.rates, nil
+}
+
+func TestValidateRates(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	next := now.Add(4 * time.Hour).Format(time.RFC3339)
+	good := exchanges.FundingRate{Symbol: "BTCUSDT", Rate: 0.0001, NextFunding: next, Volume24h: 10}
+
+	bad := []exchanges.FundingRate{
+		good,
+		{Symbol: "NANUSDT", Rate: math.NaN(), NextFunding: next},
+		{Symbol: "WILDUSDT", Rate: 0.5, NextFunding: next},
+		{Symbol: "EPOCHUSDT", Rate: 0.0001, NextFunding: time.Unix(0, 0).UTC().Format(time.RFC3339)},
+		{Symbol: "BTCUSDT", Rate: 0.0002, NextFunding: next},
+		{Symbol: "NEGUSDT", Rate: 0.0001, NextFunding: next, Volume24h: -1},
+		{Symbol: "NOTIMEUSDT", Rate: 0.0001},
+	}
+
+	tests := []struct {
+		name        string
+		actions     map[exchanges.ValidationCheck]exchanges.ValidationAction
+		wantSymbols []string
+		wantIssues  map[string][]exchanges.ValidationCheck
+		wantDropped int
+		wantFlagged int
+		wantReject  bool
+	}{
+		{
+			name:        "Правила по умолчанию",
+			wantSymbols: []string{"BTCUSDT", "WILDUSDT", "EPOCHUSDT", "NEGUSDT", "NOTIMEUSDT"},
+			wantIssues: map[string][]exchanges.ValidationCheck{
+				"WILDUSDT":  {exchanges.CheckRateBounds},
+				"EPOCHUSDT": {exchanges.CheckNextFunding},
+				"NEGUSDT":   {exchanges.CheckNegativeVolume},
+			},
+			wantDropped: 2,
+			wantFlagged: 3,
+		},
+		{
+			name: "Удаление всех проблемных строк",
+			actions: map[exchanges.ValidationCheck]exchanges.ValidationAction{
+				exchanges.CheckNaN:            exchanges.ActionDrop,
+				exchanges.CheckRateBounds:     exchanges.ActionDrop,
+				exchanges.CheckNextFunding:    exchanges.ActionDrop,
+				exchanges.CheckDuplicate:      exchanges.ActionDrop,
+				exchanges.CheckNegativeVolume: exchanges.ActionDrop,
+			},
+			wantSymbols: []string{"BTCUSDT", "NOTIMEUSDT"},
+			wantDropped: 5,
+		},
+		{
+			name: "Отключенные проверки",
+			actions: map[exchanges.ValidationCheck]exchanges.ValidationAction{
+				exchanges.CheckNaN:            exchanges.ActionIgnore,
+				exchanges.CheckRateBounds:     exchanges.ActionIgnore,
+				exchanges.CheckNextFunding:    exchanges.ActionIgnore,
+				exchanges.CheckDuplicate:      exchanges.ActionIgnore,
+				exchanges.CheckNegativeVolume: exchanges.ActionIgnore,
+			},
+			wantSymbols: []string{"BTCUSDT", "NANUSDT", "WILDUSDT", "EPOCHUSDT", "BTCUSDT", "NEGUSDT", "NOTIMEUSDT"},
+		},
+		{
+			name: "Отклонение снимка",
+			actions: map[exchanges.ValidationCheck]exchanges.ValidationAction{
+				exchanges.CheckNextFunding: exchanges.ActionReject,
+			},
+			wantDropped: len(bad),
+			wantReject:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := exchanges.DefaultValidationRules()
+			if tt.actions != nil {
+				rules.Actions = tt.actions
+			}
+
+			got, report, err := exchanges.ValidateRates("Test", bad, rules, now)
+
+			var validationErr *exchanges.ValidationError
+			if tt.wantReject != errors.As(err, &validationErr) {
+				t.Fatalf("ошибка %v, ожидалось отклонение: %v", err, tt.wantReject)
+			}
+			if report.Rejected != tt.wantReject || report.Dropped != tt.wantDropped || report.Flagged != tt.wantFlagged {
+				t.Errorf("отчет: отклонен %v, удалено %d, отмечено %d", report.Rejected, report.Dropped, report.Flagged)
+			}
+
+			var symbols []string
+			issues := make(map[string][]exchanges.ValidationCheck)
+			for _, rate := range got {
+				symbols = append(symbols, rate.Symbol)
+				if len(rate.Issues) > 0 {
+					issues[rate.Symbol] = rate.Issues
+				}
+			}
+			if !reflect.DeepEqual(symbols, tt.wantSymbols) {
+				t.Errorf("символы %v, ожидалось %v", symbols, tt.wantSymbols)
+			}
+			if tt.wantIssues == nil {
+				tt.wantIssues = map[string][]exchanges.ValidationCheck{}
+			}
+			if !reflect.DeepEqual(issues, tt.wantIssues) {
+				t.Errorf("отметки %v, ожидалось %v", issues, tt.wantIssues)
+			}
+		})
+	}
+}
+
+func TestUpdateRatesRejectKeepsPrevious(t *testing.T) {
+	cache := newTestCache()
+	next := time.Now().Add(time.Hour).Format(time.RFC3339)
+	exchange := &staticExchange{name: "Test", rates: []exchanges.FundingRate{{Symbol: "BTCUSDT", Rate: 0.0001, NextFunding: next}}}
+	if err := cache.UpdateRates(exchange); err != nil {
+		t.Fatal(err)
+	}
+
+	rules := exchanges.DefaultValidationRules()
+	rules.Actions[exchanges.CheckNextFunding] = exchanges.ActionReject
+	cache.SetValidationRules(rules)
+
+	exchange.rates = []exchanges.FundingRate{{Symbol: "BTCUSDT", Rate: 0.0002, NextFunding: "1970-01-01T00:00:00Z"}}
+	var validationErr *exchanges.ValidationError
+	if err := cache.UpdateRates(exchange); !errors.As(err, &validationErr) {
+		t.Fatalf("ожидалось отклонение снимка, получено %v", err)
+	}
+
+	if rates := cache.GetRates("Test"); len(rates) != 1 || rates[0].Rate != 0.0001 {
+		t.Errorf("в кэше %v, ожидался прежний снимок", rates)
+	}
+	if status := cache.GetStatus()["Test"]; status.Errors != 1 || status.Updates != 1 {
+		t.Errorf("состояние %+v", status)
+	}
+	report, ok := cache.GetValidationReport("Test")
+	if !ok || !report.Rejected || report.Counts[exchanges.CheckNextFunding] != 1 {
+		t.Errorf("отчет %+v", report)
+	}
+}
+
+func TestMissingNextFundingStaysEmpty(t *testing.T) {
+	replayFixtures(t, "bingx/ok", `"nextFundingTime": 1767254400000`, `"nextFundingTime": 0`)
+
+	rates, err := (&exchanges.BingX{}).GetFundingRates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rate := range rates {
+		if rate.NextFunding != "" {
+			t.Errorf("%s: время выплаты %q, биржа его не сообщала", rate.Symbol, rate.NextFunding)
+		}
+	}
+
+	_, report, err := exchanges.ValidateRates("BingX", rates, exchanges.DefaultValidationRules(), time.Now())
+	if err != nil || report.Counts[exchanges.CheckNextFunding] != 0 {
+		t.Errorf("отчет %+v, ошибка %v", report, err)
+	}
+}
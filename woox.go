@@ -0,0 +1,170 @@
+package exchanges
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+type WOOX struct{}
+
+func NewWOOX() *WOOX {
+	log.Println("Инициализация WOO X")
+	return &WOOX{}
+}
+
+func (w *WOOX) GetName() string {
+	return "WOOX"
+}
+
+// wooxDecimal преобразует число WOO X, которое может прийти как числом, так и строкой
+func wooxDecimal(n json.Number) Decimal {
+	return parseDecimal(n.String())
+}
+
+// wooxFloat преобразует число WOO X в float64
+func wooxFloat(n json.Number) float64 {
+	return wooxDecimal(n).Float64()
+}
+
+// wooxTime преобразует метку времени WOO X: часть полей передается в миллисекундах,
+// часть — в секундах с дробной частью (например, "1575014255.089")
+func wooxTime(n json.Number) time.Time {
+	value := wooxFloat(n)
+	if value <= 0 {
+		return time.Time{}
+	}
+	if value < 1e11 {
+		return time.UnixMilli(int64(value * 1000))
+	}
+	return time.UnixMilli(int64(value))
+}
+
+// Структура рыночных данных фьючерсов WOO X
+type wooxFuturesResponse struct {
+	Success bool `json:"success"`
+	Rows    []struct {
+		Symbol     string      `json:"symbol"`
+		IndexPrice json.Number `json:"index_price"`
+		MarkPrice  json.Number `json:"mark_price"`
+		Volume24h  json.Number `json:"24h_volume"`
+		Amount24h  json.Number `json:"24h_amount"`
+	} `json:"rows"`
+}
+
+// Получаем объемы и цены для всех пар
+func (w *WOOX) getFutures() (*wooxFuturesResponse, error) {
+	resp, err := httpGet("https://api.woox.io/v1/public/futures")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var futures wooxFuturesResponse
+	if err := decodeResponse(w.GetName(), resp, &futures); err != nil {
+		return nil, err
+	}
+
+	if !futures.Success {
+		return nil, fmt.Errorf("WOO X API error")
+	}
+
+	return &futures, nil
+}
+
+func (w *WOOX) GetFundingRates() ([]FundingRate, error) {
+	log.Println("Запрос ставок фандинга с WOO X")
+
+	futures, err := w.getFutures()
+	if err != nil {
+		log.Printf("Ошибка получения рыночных данных WOO X: %v", err)
+		// Продолжаем работу без объемов
+		futures = &wooxFuturesResponse{}
+	}
+
+	markets := make(map[string]int, len(futures.Rows))
+	for i, row := range futures.Rows {
+		markets[row.Symbol] = i
+	}
+
+	resp, err := httpGet("https://api.woox.io/v1/public/funding_rates")
+	if err != nil {
+		log.Printf("Ошибка запроса к WOO X: %v", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var response struct {
+		Success bool `json:"success"`
+		Rows    []struct {
+			Symbol                  string      `json:"symbol"`
+			EstFundingRate          json.Number `json:"est_funding_rate"`
+			LastFundingRate         json.Number `json:"last_funding_rate"`
+			NextFundingTime         json.Number `json:"next_funding_time"`
+			LastFundingRateInterval json.Number `json:"last_funding_rate_interval"` // в часах
+			EstFundingRateInterval  json.Number `json:"est_funding_rate_interval"`  // в часах
+		} `json:"rows"`
+	}
+
+	if err := decodeResponse(w.GetName(), resp, &response); err != nil {
+		log.Printf("Ошибка декодирования ответа WOO X: %v", err)
+		return nil, err
+	}
+
+	if !response.Success {
+		return nil, fmt.Errorf("WOO X API ошибка")
+	}
+
+	location := GetLocationFromEnv()
+	result := make([]FundingRate, 0)
+
+	for _, rate := range response.Rows {
+		// Учитываем только USDT-перпетуалы вида PERP_BTC_USDT
+		if !strings.HasPrefix(rate.Symbol, "PERP_") || !strings.HasSuffix(rate.Symbol, "_USDT") {
+			continue
+		}
+
+		interval := time.Duration(wooxFloat(rate.EstFundingRateInterval) * float64(time.Hour))
+		if interval <= 0 {
+			interval = time.Duration(wooxFloat(rate.LastFundingRateInterval) * float64(time.Hour))
+		}
+		if interval <= 0 {
+			interval = 8 * time.Hour
+		}
+
+		nextFundingTime := wooxTime(rate.NextFundingTime)
+		if nextFundingTime.IsZero() {
+			nextFundingTime = time.Now().Truncate(interval).Add(interval)
+		}
+
+		lastRate := wooxDecimal(rate.LastFundingRate)
+		predicted := wooxDecimal(rate.EstFundingRate)
+
+		fundingRate := FundingRate{
+			Symbol:          rate.Symbol,
+			Rate:            lastRate.Float64(),
+			NextFunding:     nextFundingTime.In(location).Format(time.RFC3339),
+			PredictedRate:   predicted.Float64(),
+			FundingInterval: interval,
+		}
+
+		var volume, volumeUSDT Decimal
+		if i, ok := markets[rate.Symbol]; ok {
+			market := futures.Rows[i]
+			volume = wooxDecimal(market.Volume24h)
+			volumeUSDT = wooxDecimal(market.Amount24h)
+			fundingRate.Volume24h = volume.Float64()
+			fundingRate.VolumeUSDT24h = volumeUSDT.Float64()
+			fundingRate.MarkPrice = wooxFloat(market.MarkPrice)
+			fundingRate.IndexPrice = wooxFloat(market.IndexPrice)
+		}
+		fundingRate.Precise = newPrecise(lastRate, predicted, volume, volumeUSDT)
+
+		result = append(result, fundingRate)
+	}
+
+	log.Printf("Получено %d ставок фандинга с WOO X", len(result))
+	return result, nil
+}